@@ -3,7 +3,9 @@ package room
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"github.com/google/go-querystring/query"
+	"io"
 	"mime/multipart"
 	"net/url"
 )
@@ -55,7 +57,7 @@ func (f *FormURLEncodedBody) Parse() *bytes.Buffer {
 	switch f.v.(type) {
 	case map[string]any:
 		for key, value := range f.v.(map[string]any) {
-			values.Add(key, value.(string))
+			values.Add(key, fmt.Sprint(value))
 		}
 	default:
 		values, _ = query.Values(f.v)
@@ -67,6 +69,7 @@ func (f *FormURLEncodedBody) Parse() *bytes.Buffer {
 // MultipartFormDataBody handles multipart/form-data encoding
 type MultipartFormDataBody struct {
 	formData    map[string]string
+	boundary    string
 	contentType string
 }
 
@@ -77,6 +80,7 @@ func (f *MultipartFormDataBody) ContentType() string {
 func (f *MultipartFormDataBody) Parse() *bytes.Buffer {
 	var body bytes.Buffer
 	writer := multipart.NewWriter(&body)
+	_ = writer.SetBoundary(f.boundary)
 
 	for key, value := range f.formData {
 		_ = writer.WriteField(key, value)
@@ -86,8 +90,6 @@ func (f *MultipartFormDataBody) Parse() *bytes.Buffer {
 
 	_ = writer.Close()
 
-	f.contentType = writer.FormDataContentType()
-
 	return &body
 }
 
@@ -98,7 +100,7 @@ func NewMultipartFormDataBodyParser(v any) IBodyParser {
 		newMap := make(map[string]string)
 
 		for key, value := range v.(map[string]any) {
-			newMap[key] = value.(string)
+			newMap[key] = fmt.Sprint(value)
 		}
 
 		formData = newMap
@@ -106,8 +108,13 @@ func NewMultipartFormDataBodyParser(v any) IBodyParser {
 		formData, _ = v.(map[string]string)
 	}
 
+	// the boundary is fixed on creation, so Parse does not mutate the parser and can be called concurrently
+	writer := multipart.NewWriter(io.Discard)
+
 	return &MultipartFormDataBody{
-		formData: formData,
+		formData:    formData,
+		boundary:    writer.Boundary(),
+		contentType: writer.FormDataContentType(),
 	}
 }
 
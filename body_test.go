@@ -50,6 +50,25 @@ func TestFormURLEncodedBodyAsMap_Parse(t *testing.T) {
 	}
 }
 
+func TestFormURLEncodedBodyAsTypedMap_Parse(t *testing.T) {
+	// the coerced values of dynamic contents are not strings
+	mapData := map[string]any{"active": true, "count": int64(3), "price": 9.5}
+	body := FormURLEncodedBody{v: mapData}
+	buffer := body.Parse()
+	expected := "active=true&count=3&price=9.5"
+	if buffer.String() != expected {
+		t.Errorf("FormURLEncodedBody Parse() returned %s, expected %s", buffer.String(), expected)
+	}
+}
+
+func TestMultipartFormDataBodyAsTypedMap_Parse(t *testing.T) {
+	body := NewMultipartFormDataBodyParser(map[string]any{"active": true, "count": int64(3)})
+	buffer := body.Parse().String()
+	if !strings.Contains(buffer, "name=\"active\"\r\n\r\ntrue") || !strings.Contains(buffer, "name=\"count\"\r\n\r\n3") {
+		t.Errorf("MultipartFormDataBody Parse() returned %s", buffer)
+	}
+}
+
 func TestDumpBody_Parse(t *testing.T) {
 	// Test DumpBody Parse() always returns an empty buffer
 	body := dumpBody{}
@@ -64,7 +83,7 @@ func TestNewJsonBodyParser(t *testing.T) {
 	// Test NewJsonBodyParser() creates a JsonBody instance
 	data := map[string]interface{}{"key": "value"}
 	bodyParser := NewJsonBodyParser(data)
-	_, ok := bodyParser.(JsonBody)
+	_, ok := bodyParser.(*JsonBody)
 	if !ok {
 		t.Error("NewJsonBodyParser() did not return a JsonBody instance")
 	}
@@ -74,7 +93,7 @@ func TestNewFormURLEncodedBodyParser(t *testing.T) {
 	// Test NewFormURLEncodedBodyParser() creates a FormURLEncodedBody instance
 	data := map[string]interface{}{"key": "value"}
 	bodyParser := NewFormURLEncodedBodyParser(data)
-	_, ok := bodyParser.(FormURLEncodedBody)
+	_, ok := bodyParser.(*FormURLEncodedBody)
 	if !ok {
 		t.Error("NewFormURLEncodedBodyParser() did not return a FormURLEncodedBody instance")
 	}
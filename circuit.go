@@ -0,0 +1,294 @@
+package room
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit breaker of the connector is open
+var ErrCircuitOpen = errors.New("room: circuit breaker is open")
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// CircuitStateHook is called after every state change of a circuit breaker
+type CircuitStateHook func(from, to CircuitState)
+
+// CircuitBreaker fails the requests fast after consecutive failures or a high failure rate,
+// it lets a limited number of probe requests through once the open timeout elapses
+// and closes again when all of them succeed
+type CircuitBreaker struct {
+	mu sync.Mutex
+
+	consecutiveFailures int
+	failureRate         float64
+	window              time.Duration
+	minRequests         int
+	openTimeout         time.Duration
+	halfOpenRequests    int
+	isFailure           func(response Response, err error) bool
+	hooks               []CircuitStateHook
+	now                 func() time.Time
+
+	state      CircuitState
+	generation int
+	failures   int
+	openedAt   time.Time
+	probes     int
+	successes  int
+	buckets    []circuitBucket
+}
+
+// circuitBucket counts the outcomes of a tenth of the sliding window
+type circuitBucket struct {
+	start    time.Time
+	total    int
+	failures int
+}
+
+type OptionCircuitBreaker func(breaker *CircuitBreaker)
+
+// WithConsecutiveFailures opens the circuit after n failures in a row, it is 5 by default
+func WithConsecutiveFailures(n int) OptionCircuitBreaker {
+	return func(breaker *CircuitBreaker) {
+		breaker.consecutiveFailures = n
+	}
+}
+
+// WithFailureRate opens the circuit when the rate of failures in the sliding window reaches the rate,
+// the rate is ignored until the window has at least minRequests outcomes
+func WithFailureRate(rate float64, window time.Duration, minRequests int) OptionCircuitBreaker {
+	return func(breaker *CircuitBreaker) {
+		breaker.failureRate = rate
+		breaker.window = window
+		breaker.minRequests = minRequests
+	}
+}
+
+// WithOpenTimeout sets how long the circuit stays open before probing, it is 30 seconds by default
+func WithOpenTimeout(timeout time.Duration) OptionCircuitBreaker {
+	return func(breaker *CircuitBreaker) {
+		breaker.openTimeout = timeout
+	}
+}
+
+// WithHalfOpenRequests sets the number of probe requests of the half-open state, it is 1 by default
+func WithHalfOpenRequests(n int) OptionCircuitBreaker {
+	return func(breaker *CircuitBreaker) {
+		breaker.halfOpenRequests = n
+	}
+}
+
+// WithFailurePredicate decides which outcomes are failures, by default transport errors and 5xx responses are
+func WithFailurePredicate(isFailure func(response Response, err error) bool) OptionCircuitBreaker {
+	return func(breaker *CircuitBreaker) {
+		breaker.isFailure = isFailure
+	}
+}
+
+// WithCircuitStateHook registers hooks which are called after the state changes, they must not block
+func WithCircuitStateHook(hooks ...CircuitStateHook) OptionCircuitBreaker {
+	return func(breaker *CircuitBreaker) {
+		breaker.hooks = append(breaker.hooks, hooks...)
+	}
+}
+
+func NewCircuitBreaker(opts ...OptionCircuitBreaker) *CircuitBreaker {
+	b := &CircuitBreaker{
+		consecutiveFailures: 5,
+		openTimeout:         30 * time.Second,
+		halfOpenRequests:    1,
+		isFailure:           isServerFailure,
+		now:                 time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	if b.halfOpenRequests < 1 {
+		b.halfOpenRequests = 1
+	}
+
+	return b
+}
+
+// isServerFailure treats the responses which were not received and the 5xx responses as failures,
+// an unexpected 4xx status is a problem of the request, not of the partner
+func isServerFailure(response Response, err error) bool {
+	if response.StatusCode >= 500 {
+		return true
+	}
+
+	var unexpectedStatus UnexpectedStatusError
+
+	return err != nil && !errors.As(err, &unexpectedStatus)
+}
+
+// State returns the current state, an open circuit whose timeout has elapsed is reported as half-open
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		return CircuitHalfOpen
+	}
+
+	return b.state
+}
+
+// allow reserves a request, it returns ErrCircuitOpen while the circuit is open or all probes are in flight,
+// the returned generation ties the outcome to the state the request was allowed in
+func (b *CircuitBreaker) allow() (int, error) {
+	b.mu.Lock()
+
+	var changed []CircuitState
+
+	if b.state == CircuitOpen {
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			b.mu.Unlock()
+			return 0, ErrCircuitOpen
+		}
+
+		changed = b.transition(CircuitHalfOpen)
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.probes >= b.halfOpenRequests {
+			b.mu.Unlock()
+			b.notify(changed)
+			return 0, ErrCircuitOpen
+		}
+
+		b.probes++
+	}
+
+	generation := b.generation
+
+	b.mu.Unlock()
+	b.notify(changed)
+
+	return generation, nil
+}
+
+// record counts the outcome of a request which was allowed, outcomes of requests allowed before the last state change are ignored
+func (b *CircuitBreaker) record(generation int, response Response, err error) {
+	failed := b.isFailure(response, err)
+
+	b.mu.Lock()
+
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+
+	var changed []CircuitState
+
+	switch b.state {
+	case CircuitHalfOpen:
+		switch {
+		case failed:
+			changed = b.transition(CircuitOpen)
+		case b.successes+1 >= b.halfOpenRequests:
+			changed = b.transition(CircuitClosed)
+		default:
+			b.successes++
+		}
+	case CircuitClosed:
+		if failed {
+			b.failures++
+		} else {
+			b.failures = 0
+		}
+
+		if b.count(failed) {
+			changed = b.transition(CircuitOpen)
+		}
+	}
+
+	b.mu.Unlock()
+	b.notify(changed)
+}
+
+// count adds the outcome to the sliding window and reports whether the circuit must open
+func (b *CircuitBreaker) count(failed bool) bool {
+	if b.consecutiveFailures > 0 && b.failures >= b.consecutiveFailures {
+		return true
+	}
+
+	if b.failureRate <= 0 || b.window <= 0 {
+		return false
+	}
+
+	now := b.now()
+
+	for len(b.buckets) > 0 && now.Sub(b.buckets[0].start) >= b.window {
+		b.buckets = b.buckets[1:]
+	}
+
+	if len(b.buckets) == 0 || now.Sub(b.buckets[len(b.buckets)-1].start) >= b.window/10 {
+		b.buckets = append(b.buckets, circuitBucket{start: now})
+	}
+
+	last := &b.buckets[len(b.buckets)-1]
+	last.total++
+
+	if failed {
+		last.failures++
+	}
+
+	total, failures := 0, 0
+
+	for _, bucket := range b.buckets {
+		total += bucket.total
+		failures += bucket.failures
+	}
+
+	return total >= b.minRequests && float64(failures)/float64(total) >= b.failureRate
+}
+
+// transition changes the state with the lock held and returns the change to notify after unlocking
+func (b *CircuitBreaker) transition(to CircuitState) []CircuitState {
+	from := b.state
+
+	b.state = to
+	b.generation++
+	b.probes = 0
+	b.successes = 0
+
+	switch to {
+	case CircuitOpen:
+		b.openedAt = b.now()
+	case CircuitClosed:
+		b.failures = 0
+		b.buckets = nil
+	}
+
+	return []CircuitState{from, to}
+}
+
+func (b *CircuitBreaker) notify(changed []CircuitState) {
+	if len(changed) == 0 {
+		return
+	}
+
+	for _, hook := range b.hooks {
+		hook(changed[0], changed[1])
+	}
+}
@@ -0,0 +1,195 @@
+package room
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestBreaker(clock *testClock, opts ...OptionCircuitBreaker) *CircuitBreaker {
+	breaker := NewCircuitBreaker(opts...)
+	breaker.now = clock.Now
+
+	return breaker
+}
+
+func sendThrough(breaker *CircuitBreaker, status int, err error) error {
+	generation, allowErr := breaker.allow()
+
+	if allowErr != nil {
+		return allowErr
+	}
+
+	breaker.record(generation, Response{StatusCode: status}, err)
+
+	return nil
+}
+
+func TestCircuitBreaker_ConsecutiveFailures(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+
+	var changes []string
+	breaker := newTestBreaker(clock, WithConsecutiveFailures(3), WithOpenTimeout(time.Minute), WithHalfOpenRequests(2),
+		WithCircuitStateHook(func(from, to CircuitState) {
+			changes = append(changes, from.String()+">"+to.String())
+		}))
+
+	_ = sendThrough(breaker, 500, nil)
+	_ = sendThrough(breaker, 500, nil)
+	_ = sendThrough(breaker, 200, nil)
+	_ = sendThrough(breaker, 500, nil)
+	_ = sendThrough(breaker, 500, nil)
+
+	if breaker.State() != CircuitClosed {
+		t.Fatalf("state = %s, a success resets the consecutive failures", breaker.State())
+	}
+
+	_ = sendThrough(breaker, 0, errors.New("connection refused"))
+
+	if err := sendThrough(breaker, 200, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("send error = %v, want ErrCircuitOpen", err)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+
+	if breaker.State() != CircuitHalfOpen {
+		t.Fatalf("state = %s, want half-open after the open timeout", breaker.State())
+	}
+
+	first, _ := breaker.allow()
+	second, _ := breaker.allow()
+
+	if _, err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("third probe error = %v, want ErrCircuitOpen", err)
+	}
+
+	breaker.record(first, Response{StatusCode: 200}, nil)
+	breaker.record(second, Response{StatusCode: 204}, nil)
+
+	if breaker.State() != CircuitClosed {
+		t.Fatalf("state = %s, want closed after the probes succeed", breaker.State())
+	}
+
+	want := []string{"closed>open", "open>half-open", "half-open>closed"}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("changes = %v, want %v", changes, want)
+		}
+	}
+}
+
+func TestCircuitBreaker_HalfOpenFailure(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	breaker := newTestBreaker(clock, WithConsecutiveFailures(1), WithOpenTimeout(time.Second))
+
+	stale, _ := breaker.allow()
+	_ = sendThrough(breaker, 503, nil)
+
+	clock.now = clock.now.Add(time.Second)
+
+	probe, _ := breaker.allow()
+
+	// the outcome of a request allowed before the circuit opened is not a probe
+	breaker.record(stale, Response{StatusCode: 200}, nil)
+
+	if breaker.State() != CircuitHalfOpen {
+		t.Fatalf("state = %s, want half-open", breaker.State())
+	}
+
+	breaker.record(probe, Response{}, errors.New("timeout"))
+
+	if breaker.State() != CircuitOpen {
+		t.Fatalf("state = %s, want open after a failed probe", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_FailureRate(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	breaker := newTestBreaker(clock, WithConsecutiveFailures(0), WithFailureRate(0.5, 10*time.Second, 4))
+
+	_ = sendThrough(breaker, 500, nil)
+	_ = sendThrough(breaker, 500, nil)
+	_ = sendThrough(breaker, 200, nil)
+
+	if breaker.State() != CircuitClosed {
+		t.Fatalf("state = %s, the rate is ignored below the minimum requests", breaker.State())
+	}
+
+	// the failures slide out of the window
+	clock.now = clock.now.Add(10 * time.Second)
+
+	_ = sendThrough(breaker, 200, nil)
+	_ = sendThrough(breaker, 200, nil)
+	_ = sendThrough(breaker, 500, nil)
+	_ = sendThrough(breaker, 200, nil)
+
+	if breaker.State() != CircuitClosed {
+		t.Fatalf("state = %s, want closed at a rate of 0.25", breaker.State())
+	}
+
+	_ = sendThrough(breaker, 500, nil)
+	_ = sendThrough(breaker, 500, nil)
+
+	if breaker.State() != CircuitOpen {
+		t.Fatalf("state = %s, want open at a rate of 0.5", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_ClientErrorsAreNotFailures(t *testing.T) {
+	breaker := NewCircuitBreaker(WithConsecutiveFailures(1))
+
+	_ = sendThrough(breaker, 404, UnexpectedStatusError{StatusCode: 404, Expected: []int{200}})
+
+	if breaker.State() != CircuitClosed {
+		t.Fatalf("state = %s, a 4xx must not open the circuit", breaker.State())
+	}
+}
+
+func TestConnector_CircuitBreaker(t *testing.T) {
+	var hits atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var hooked []error
+	connector := NewConnector(server.URL,
+		WithCircuitBreaker(NewCircuitBreaker(WithConsecutiveFailures(2))),
+		WithResponseHook(func(response Response, err error) {
+			hooked = append(hooked, err)
+		}),
+	)
+
+	for i := 0; i < 4; i++ {
+		_, err := connector.Send("todos")
+
+		if i >= 2 && !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("send %d error = %v, want ErrCircuitOpen", i, err)
+		}
+	}
+
+	if hits.Load() != 2 {
+		t.Errorf("server got %d requests, want 2", hits.Load())
+	}
+
+	if len(hooked) != 4 || !errors.Is(hooked[3], ErrCircuitOpen) {
+		t.Errorf("response hooks got %v", hooked)
+	}
+}
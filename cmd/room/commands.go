@@ -0,0 +1,542 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/codegen"
+	"github.com/WEG-Technology/room/elevator"
+	"github.com/WEG-Technology/room/openapi"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stdin is read by `--body -`
+var stdin io.Reader = os.Stdin
+
+func (f *flags) load() (elevator.Elevator, error) {
+	if err := elevator.LoadEnvFiles(f.envFiles...); err != nil {
+		return elevator.Elevator{}, err
+	}
+
+	opts := []elevator.OptionLoad{elevator.WithFiles(f.file)}
+
+	if f.profile != "" {
+		opts = append(opts, elevator.WithProfile(f.profile))
+	}
+
+	return elevator.Load(opts...)
+}
+
+func runCommand(args []string, stdout, _ io.Writer) error {
+	f := newFlags("run", "<roomKey>", "<requestKey>")
+
+	var vars stringsFlag
+	var bodyFile string
+
+	f.Var(&vars, "var", "key=value of the dynamic content or a path param, can be repeated")
+	f.StringVar(&bodyFile, "body", "", "json file of the dynamic content or of the body, - reads stdin")
+
+	values, err := f.parse(args, stdout)
+
+	if err != nil {
+		return err
+	}
+
+	el, err := f.load()
+
+	if err != nil {
+		return err
+	}
+
+	response, err := execute(el, values[0], values[1], vars, bodyFile)
+
+	if response.StatusCode != 0 {
+		if printErr := printResponse(stdout, f.output, response); printErr != nil {
+			return printErr
+		}
+	}
+
+	return err
+}
+
+func curlCommand(args []string, stdout, _ io.Writer) error {
+	f := newFlags("curl", "<roomKey>", "<requestKey>")
+
+	var vars stringsFlag
+	var bodyFile string
+	var redact bool
+	var auth bool
+
+	f.Var(&vars, "var", "key=value of the dynamic content or a path param, can be repeated")
+	f.StringVar(&bodyFile, "body", "", "json file of the dynamic content or of the body, - reads stdin")
+	f.BoolVar(&redact, "redact", false, "redact the credentials in the command")
+	f.BoolVar(&auth, "auth", false, "send the auth requests of bearer rooms to put a real token into the command")
+
+	values, err := f.parse(args, stdout)
+
+	if err != nil {
+		return err
+	}
+
+	el, err := f.load()
+
+	if err != nil {
+		return err
+	}
+
+	response, err := execute(el, values[0], values[1], vars, bodyFile, elevator.WithConnectorOptions(room.WithMiddleware(dryRun(el, auth))))
+
+	var unexpectedStatus room.UnexpectedStatusError
+	if err != nil && !errors.As(err, &unexpectedStatus) {
+		return err
+	}
+
+	var opts []room.OptionExport
+	if redact {
+		opts = append(opts, room.WithExportRedactor(room.NewRedactor(nil, nil)))
+	}
+
+	_, err = fmt.Fprintln(stdout, response.Request.ToCurl(opts...))
+
+	return err
+}
+
+// placeholderToken is the bearer token of the printed commands unless the auth requests are sent
+const placeholderToken = "<token>"
+
+// dryRunSOAPEnvelope is the empty reply of soap requests, it carries no fault
+const dryRunSOAPEnvelope = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body/></soap:Envelope>`
+
+// dryRun answers the requests of the engine without sending them, the auth requests of the rooms are not labeled,
+// they are answered with placeholderToken or sent to get a real token into the command when auth is set
+func dryRun(el elevator.Elevator, auth bool) room.Middleware {
+	tokens := map[string]string{}
+
+	for _, configRoom := range el.Config.Flat.Rooms {
+		if configRoom.Connection.Auth.AccessTokenKey != "" {
+			tokens[configRoom.Connection.Auth.AccessTokenKey] = placeholderToken
+		}
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return room.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if info, ok := room.InfoFromContext(req.Context()); ok && info.Labels["request"] != "" {
+				configRequest := el.Config.Flat.Rooms[info.Labels["room"]].Requests[info.Labels["request"]]
+
+				return dryRunReply(req, configRequest.Body.Type)
+			}
+
+			if auth {
+				return next.RoundTrip(req)
+			}
+
+			data, err := json.Marshal(tokens)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return dryRunResponse(req, http.StatusOK, "application/json", data), nil
+		})
+	}
+}
+
+// dryRunReply answers with an empty reply of the protocol of the request, so the jsonrpc and soap envelopes are accepted
+func dryRunReply(req *http.Request, bodyType string) (*http.Response, error) {
+	switch bodyType {
+	case elevator.BodyTypeJSONRPC:
+		var call struct {
+			ID json.RawMessage `json:"id"`
+		}
+
+		if req.Body != nil {
+			data, err := io.ReadAll(req.Body)
+
+			if err != nil {
+				return nil, err
+			}
+
+			_ = json.Unmarshal(data, &call)
+		}
+
+		// notifications are not answered
+		if len(call.ID) == 0 {
+			break
+		}
+
+		data, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": call.ID, "result": nil})
+
+		if err != nil {
+			return nil, err
+		}
+
+		return dryRunResponse(req, http.StatusOK, "application/json", data), nil
+	case elevator.BodyTypeSOAP:
+		return dryRunResponse(req, http.StatusOK, "text/xml; charset=utf-8", []byte(dryRunSOAPEnvelope)), nil
+	}
+
+	return dryRunResponse(req, http.StatusNoContent, "", nil), nil
+}
+
+func dryRunResponse(req *http.Request, statusCode int, contentType string, data []byte) *http.Response {
+	response := &http.Response{
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode: statusCode,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}
+
+	if data != nil {
+		response.Header.Set("Content-Type", contentType)
+		response.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	return response
+}
+
+func concurrentCommand(args []string, stdout, _ io.Writer) error {
+	f := newFlags("concurrent", "<concurrentKey>")
+
+	var rooms stringsFlag
+	f.Var(&rooms, "room", "execute the requests of the room only, can be repeated")
+
+	values, err := f.parse(args, stdout)
+
+	if err != nil {
+		return err
+	}
+
+	el, err := f.load()
+
+	if err != nil {
+		return err
+	}
+
+	engine := elevator.NewElevatorEngine(el)
+	engine.WarmUp()
+
+	responses, _ := engine.ExecuteConcurrentSegment(values[0], rooms...)
+
+	if len(responses) == 0 {
+		return fmt.Errorf("no request has the concurrent key %q", values[0])
+	}
+
+	return printResponses(stdout, f.output, responses)
+}
+
+func validateCommand(args []string, stdout, stderr io.Writer) error {
+	f := newFlags("validate")
+
+	if _, err := f.parse(args, stdout); err != nil {
+		return err
+	}
+
+	el, err := f.load()
+
+	if err == nil {
+		err = el.Validate()
+	}
+
+	if f.output == outputJson {
+		result := struct {
+			Valid  bool     `json:"valid"`
+			Errors []string `json:"errors"`
+		}{Valid: err == nil, Errors: []string{}}
+
+		if err != nil {
+			result.Errors = strings.Split(err.Error(), "\n")
+		}
+
+		if encodeErr := writeJson(stdout, result); encodeErr != nil {
+			return encodeErr
+		}
+	} else if err != nil {
+		fmt.Fprintln(stderr, err)
+	} else {
+		fmt.Fprintf(stdout, "%s is valid\n", f.file)
+	}
+
+	if err != nil {
+		return errFailed
+	}
+
+	return nil
+}
+
+func listCommand(args []string, stdout, _ io.Writer) error {
+	f := newFlags("list")
+
+	if _, err := f.parse(args, stdout); err != nil {
+		return err
+	}
+
+	el, err := f.load()
+
+	if err != nil {
+		return err
+	}
+
+	return printRequests(stdout, f.output, el)
+}
+
+func testCommand(args []string, stdout, stderr io.Writer) error {
+	f := newFlags("test")
+
+	var rooms stringsFlag
+	var junit string
+
+	f.Var(&rooms, "room", "run the requests of the room only, can be repeated")
+	f.StringVar(&junit, "junit", "", "write the report as JUnit XML to the file")
+
+	if _, err := f.parse(args, stdout); err != nil {
+		return err
+	}
+
+	el, err := f.load()
+
+	if err != nil {
+		return err
+	}
+
+	if err = el.Validate(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return errFailed
+	}
+
+	engine := elevator.NewElevatorEngine(el)
+	engine.WarmUp()
+
+	report := engine.RunContracts(rooms...)
+
+	if junit != "" {
+		if err = writeJUnit(junit, report); err != nil {
+			return err
+		}
+	}
+
+	if err = printReport(stdout, f.output, report); err != nil {
+		return err
+	}
+
+	if !report.Passed() {
+		return errFailed
+	}
+
+	return nil
+}
+
+func genCommand(args []string, stdout, _ io.Writer) error {
+	f := newFlags("gen")
+
+	var packageName, out string
+
+	f.StringVar(&packageName, "package", "client", "package name of the generated client")
+	f.StringVar(&out, "out", "", "write the client to the file instead of stdout")
+
+	if _, err := f.parse(args, stdout); err != nil {
+		return err
+	}
+
+	el, err := f.load()
+
+	if err != nil {
+		return err
+	}
+
+	source, err := codegen.Generate(el.Config, codegen.WithPackage(packageName), codegen.WithSource(filepath.Base(f.file)))
+
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(stdout, out, source)
+}
+
+func importCommand(args []string, stdout, stderr io.Writer) error {
+	f := newFlags("import", "<openapi>")
+
+	var out, dto, packageName, baseUrl string
+
+	f.StringVar(&out, "out", "", "write the integration yml to the file instead of stdout")
+	f.StringVar(&dto, "dto", "", "write the DTOs of the schemas to the Go file")
+	f.StringVar(&packageName, "package", "dto", "package name of the DTOs")
+	f.StringVar(&baseUrl, "base-url", "", "baseUrl of all rooms instead of the servers of the document")
+
+	values, err := f.parse(args, stdout)
+
+	if err != nil {
+		return err
+	}
+
+	document, err := openapi.ParseFile(values[0])
+
+	if err != nil {
+		return err
+	}
+
+	var opts []openapi.OptionImport
+	if baseUrl != "" {
+		opts = append(opts, openapi.WithBaseUrl(baseUrl))
+	}
+
+	result := openapi.Import(document, opts...)
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(stderr, "warning: %s\n", warning)
+	}
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err = encoder.Encode(result.Config); err != nil {
+		return err
+	}
+
+	if err = writeOutput(stdout, out, buf.Bytes()); err != nil {
+		return err
+	}
+
+	if dto == "" {
+		return nil
+	}
+
+	source, err := openapi.GenerateDTOs(document, openapi.WithPackage(packageName), openapi.WithSource(filepath.Base(values[0])))
+
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(stdout, dto, source)
+}
+
+// writeOutput writes the generated data to the file, or to stdout when no file is given
+func writeOutput(stdout io.Writer, path string, data []byte) error {
+	if path == "" {
+		_, err := stdout.Write(data)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func writeJUnit(path string, report elevator.ContractReport) error {
+	file, err := os.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	if err = report.WriteJUnit(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// execute sends the request with the vars and the body file, they fill the dynamic contents when the request declares them,
+// otherwise the vars are path params and the body file is sent as the json body
+func execute(el elevator.Elevator, roomKey, requestKey string, vars []string, bodyFile string, opts ...elevator.OptionEngine) (room.Response, error) {
+	configRoom, ok := el.Config.Flat.Rooms[roomKey]
+
+	if !ok {
+		return room.Response{}, fmt.Errorf("unknown room %q", roomKey)
+	}
+
+	req, ok := configRoom.Requests[requestKey]
+
+	if !ok {
+		return room.Response{}, fmt.Errorf("unknown request %q of room %q", requestKey, roomKey)
+	}
+
+	body, err := readBody(bodyFile)
+
+	if err != nil {
+		return room.Response{}, err
+	}
+
+	params := map[string]string{}
+
+	for _, v := range vars {
+		key, value, found := strings.Cut(v, "=")
+
+		if !found || key == "" {
+			return room.Response{}, fmt.Errorf("%w: --var %q is not key=value", errUsage, v)
+		}
+
+		params[key] = value
+	}
+
+	engine := elevator.NewElevatorEngine(el, opts...)
+	engine.WarmUp()
+
+	requestOpts := []room.OptionRequest{room.WithPathParams(params)}
+
+	if len(req.Body.DynamicContent) > 0 || len(req.Query.DynamicContent) > 0 {
+		payload, isObject := body.(map[string]any)
+
+		if body != nil && !isObject {
+			return room.Response{}, fmt.Errorf("the body of a request with dynamic content must be a json object")
+		}
+
+		if payload == nil {
+			payload = map[string]any{}
+		}
+
+		for key, value := range params {
+			payload[key] = value
+		}
+
+		return engine.DynamicExecuteWith(roomKey, requestKey, payload, requestOpts...)
+	}
+
+	if body != nil {
+		requestOpts = append(requestOpts, room.WithBody(room.NewJsonBodyParser(body)))
+	}
+
+	return engine.ExecuteWith(roomKey, requestKey, requestOpts...)
+}
+
+func readBody(path string) (any, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	var data []byte
+	var err error
+
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var body any
+
+	if err = json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("body %s: %w", path, err)
+	}
+
+	return body, nil
+}
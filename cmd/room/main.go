@@ -0,0 +1,174 @@
+// Command room executes the requests of an integration yml without writing Go.
+//
+//	room run todoRoom addTodo --var todo=lorem --var completed=true
+//	room concurrent add
+//	room curl todoRoom addTodo --body todo.json
+//	room validate -f integration.yml
+//	room list -o json
+//	room test --junit report.xml
+//	room gen --package todoclient --out todoclient/todoclient_gen.go
+//	room import openapi.yml --out integration.yml --dto dto/dto_gen.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+const usage = `usage: room <command> [flags] [args]
+
+commands:
+  run <roomKey> <requestKey>    execute a request
+  concurrent <concurrentKey>    execute the requests of a concurrent key
+  curl <roomKey> <requestKey>   print the curl command of a request, --auth sends the login for a real token
+  validate                      validate the integration yml
+  list                          list the rooms and requests
+  test                          run the requests which declare expect and report the assertions
+  gen                           generate a typed Go client of the integration yml
+  import <openapi>              convert an OpenAPI 3 document to an integration yml and DTOs
+
+common flags:
+  -f, --file string       integration yml (default "integration.yml")
+  --profile string        profile of the integration yml
+  --env-file string       dotenv file loaded before the yml, can be repeated
+  -o, --output string     pretty, json or raw (default "pretty")
+
+run "room <command> -h" for the flags of a command
+`
+
+// errUsage is returned for invalid arguments, it exits with 2 like the flag package
+var errUsage = errors.New("invalid usage")
+
+// errFailed exits with 1 without printing anything more, the command has already reported the failure
+var errFailed = errors.New("failed")
+
+type command func(args []string, stdout, stderr io.Writer) error
+
+var commands = map[string]command{
+	"run":        runCommand,
+	"concurrent": concurrentCommand,
+	"curl":       curlCommand,
+	"validate":   validateCommand,
+	"list":       listCommand,
+	"test":       testCommand,
+	"gen":        genCommand,
+	"import":     importCommand,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+
+	if !ok {
+		fmt.Fprintf(stderr, "room: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	err := cmd(args[1:], stdout, stderr)
+
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errFailed):
+		return 1
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "room %s: %v\n", args[0], err)
+		return 2
+	}
+
+	fmt.Fprintf(stderr, "room %s: %v\n", args[0], err)
+
+	return 1
+}
+
+// stringsFlag collects the values of a repeated flag
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// flags are the flag set of a command with the flags which are common to all commands
+type flags struct {
+	*flag.FlagSet
+	arguments []string
+	file      string
+	profile   string
+	envFiles  stringsFlag
+	output    string
+}
+
+func newFlags(name string, arguments ...string) *flags {
+	f := &flags{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError), arguments: arguments}
+
+	// errors are reported by run, the defaults are printed for -h only
+	f.SetOutput(io.Discard)
+
+	f.StringVar(&f.file, "file", "integration.yml", "integration yml")
+	f.StringVar(&f.file, "f", "integration.yml", "integration yml")
+	f.StringVar(&f.profile, "profile", "", "profile of the integration yml")
+	f.Var(&f.envFiles, "env-file", "dotenv file loaded before the yml, can be repeated")
+	f.StringVar(&f.output, "output", outputPretty, "pretty, json or raw")
+	f.StringVar(&f.output, "o", outputPretty, "pretty, json or raw")
+
+	return f
+}
+
+// parse parses the flags wherever they are, `room run todoRoom addTodo -o json` works like `room run -o json todoRoom addTodo`
+func (f *flags) parse(args []string, stdout io.Writer) ([]string, error) {
+	var values []string
+
+	for {
+		if err := f.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				fmt.Fprintf(stdout, "usage: room %s [flags] %s\n\nflags:\n", f.Name(), strings.Join(f.arguments, " "))
+				f.SetOutput(stdout)
+				f.PrintDefaults()
+				return nil, err
+			}
+
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+
+		args = f.Args()
+
+		if len(args) == 0 {
+			break
+		}
+
+		values = append(values, args[0])
+		args = args[1:]
+	}
+
+	if len(values) != len(f.arguments) {
+		if len(f.arguments) == 0 {
+			return nil, fmt.Errorf("%w: expected no arguments, got %v", errUsage, values)
+		}
+
+		return nil, fmt.Errorf("%w: expected arguments %s, got %v", errUsage, strings.Join(f.arguments, " "), values)
+	}
+
+	if !slices.Contains(outputs, f.output) {
+		return nil, fmt.Errorf("%w: unknown output %q, expected one of %v", errUsage, f.output, outputs)
+	}
+
+	return values, nil
+}
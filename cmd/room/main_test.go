@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testYml = `
+flat:
+  rooms:
+    todoRoom:
+      connection:
+        baseUrl: ${ROOM_CLI_BASE_URL}
+        headers:
+          X-Key: ${ROOM_CLI_KEY}
+      requests:
+        addTodo:
+          concurrentKey: "add"
+          method: "POST"
+          path: "todos/add"
+          body:
+            type: "json"
+            dynamicContent:
+              - key: "todo"
+                type: "string"
+              - key: "completed"
+                type: "boolean"
+        getTodo:
+          method: "GET"
+          path: "todos/{id}"
+          expect:
+            status: 200
+            jsonPath:
+              "$.path": "/todos/1"
+`
+
+func newTestFiles(t *testing.T) (string, string) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"path": r.URL.Path, "body": string(body), "xKey": r.Header.Get("X-Key")})
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+
+	yml := filepath.Join(dir, "integration.yml")
+	env := filepath.Join(dir, ".env")
+
+	if err := os.WriteFile(yml, []byte(testYml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(env, []byte("ROOM_CLI_BASE_URL="+server.URL+"\nROOM_CLI_KEY=secret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("ROOM_CLI_BASE_URL", "")
+	t.Setenv("ROOM_CLI_KEY", "")
+	_ = os.Unsetenv("ROOM_CLI_BASE_URL")
+	_ = os.Unsetenv("ROOM_CLI_KEY")
+
+	return yml, env
+}
+
+func runTest(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	code := run(args, &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	yml, env := newTestFiles(t)
+
+	code, stdout, stderr := runTest(t, "run", "todoRoom", "addTodo", "-f", yml, "--env-file", env, "--var", "todo=lorem", "--var", "completed=true", "-o", "json")
+	if code != 0 {
+		t.Fatalf("run exited with %d: %s", code, stderr)
+	}
+
+	var output responseOutput
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		t.Fatalf("run -o json printed invalid json: %v\n%s", err, stdout)
+	}
+
+	body := output.Body.(map[string]any)
+	if output.Status != http.StatusOK || body["xKey"] != "secret" || strings.TrimSpace(body["body"].(string)) != `{"completed":true,"todo":"lorem"}` {
+		t.Errorf("run printed %s", stdout)
+	}
+
+	code, stdout, _ = runTest(t, "run", "todoRoom", "getTodo", "-f", yml, "--env-file", env, "--var", "id=1", "-o", "raw")
+	if code != 0 || !strings.Contains(stdout, `"path":"/todos/1"`) {
+		t.Errorf("run -o raw exited with %d and printed %s", code, stdout)
+	}
+
+	code, _, stderr = runTest(t, "run", "todoRoom", "missing", "-f", yml, "--env-file", env)
+	if code != 1 || !strings.Contains(stderr, `unknown request "missing"`) {
+		t.Errorf("run exited with %d and printed %s for an unknown request", code, stderr)
+	}
+}
+
+func TestCurl(t *testing.T) {
+	yml, env := newTestFiles(t)
+
+	bodyFile := filepath.Join(t.TempDir(), "todo.json")
+	if err := os.WriteFile(bodyFile, []byte(`{"todo":"it's", "completed": false}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runTest(t, "curl", "todoRoom", "addTodo", "-f", yml, "--env-file", env, "--body", bodyFile)
+	if code != 0 {
+		t.Fatalf("curl exited with %d: %s", code, stderr)
+	}
+
+	for _, expected := range []string{"curl -X POST '" + os.Getenv("ROOM_CLI_BASE_URL") + "/todos/add'", "-H 'X-Key: secret'", `--data-raw '{"completed":false,"todo":"it'\''s"}`} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("curl printed %s, expected it to contain %s", stdout, expected)
+		}
+	}
+}
+
+func TestCurl_Auth(t *testing.T) {
+	logins := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logins++
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {"token": "real"}}`))
+	}))
+	defer server.Close()
+
+	yml := filepath.Join(t.TempDir(), "integration.yml")
+	if err := os.WriteFile(yml, []byte(`
+flat:
+  rooms:
+    todoRoom:
+      connection:
+        baseUrl: `+server.URL+`
+        auth:
+          type: "bearer"
+          accessTokenKey: "token"
+          request:
+            method: "POST"
+            path: "login"
+      requests:
+        getTodo:
+          path: "todos/1"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runTest(t, "curl", "todoRoom", "getTodo", "-f", yml)
+	if code != 0 || !strings.Contains(stdout, "-H 'Authorization: Bearer "+placeholderToken+"'") || logins != 0 {
+		t.Errorf("curl exited with %d after %d logins and printed %s%s, expected the placeholder token", code, logins, stdout, stderr)
+	}
+
+	code, stdout, stderr = runTest(t, "curl", "todoRoom", "getTodo", "-f", yml, "--auth")
+	if code != 0 || !strings.Contains(stdout, "-H 'Authorization: Bearer real'") || logins != 1 {
+		t.Errorf("curl --auth exited with %d after %d logins and printed %s%s, expected the real token", code, logins, stdout, stderr)
+	}
+}
+
+func TestCurl_Protocols(t *testing.T) {
+	yml := filepath.Join(t.TempDir(), "integration.yml")
+	if err := os.WriteFile(yml, []byte(`
+flat:
+  rooms:
+    nodeRoom:
+      connection:
+        baseUrl: "http://127.0.0.1:1"
+      requests:
+        getBalance:
+          body:
+            type: jsonrpc
+            method: eth_getBalance
+            positionalParams: true
+            content: ["0xab", "latest"]
+    erpRoom:
+      connection:
+        baseUrl: "http://127.0.0.1:1"
+      requests:
+        getOrder:
+          path: erp
+          body:
+            type: soap
+            operation: GetOrder
+            namespace: urn:erp
+            content:
+              OrderID: 7
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"nodeRoom", "getBalance"}, `"method":"eth_getBalance","params":["0xab","latest"]`},
+		{[]string{"erpRoom", "getOrder"}, "<OrderID>7</OrderID>"},
+	} {
+		code, stdout, stderr := runTest(t, append([]string{"curl", "-f", yml}, c.args...)...)
+		if code != 0 || !strings.Contains(stdout, c.expected) {
+			t.Errorf("curl %v exited with %d and printed %s%s, expected it to contain %s", c.args, code, stdout, stderr, c.expected)
+		}
+	}
+}
+
+func TestListAndValidate(t *testing.T) {
+	yml, env := newTestFiles(t)
+
+	code, stdout, _ := runTest(t, "list", "-f", yml, "--env-file", env, "-o", "raw")
+	if code != 0 || stdout != "todoRoom\taddTodo\tPOST\ttodos/add\tadd\ntodoRoom\tgetTodo\tGET\ttodos/{id}\t\n" {
+		t.Errorf("list exited with %d and printed %q", code, stdout)
+	}
+
+	code, stdout, _ = runTest(t, "validate", "-f", yml, "--env-file", env)
+	if code != 0 || !strings.Contains(stdout, "is valid") {
+		t.Errorf("validate exited with %d and printed %s", code, stdout)
+	}
+
+	invalid := filepath.Join(t.TempDir(), "invalid.yml")
+	if err := os.WriteFile(invalid, []byte("flat:\n  rooms:\n    todoRoom:\n      requests:\n        getTodo:\n          method: FETCH\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, _, stderr := runTest(t, "validate", "-f", invalid)
+	if code != 1 || !strings.Contains(stderr, "baseUrl is required") || !strings.Contains(stderr, `method "FETCH"`) {
+		t.Errorf("validate exited with %d and printed %s for an invalid yml", code, stderr)
+	}
+
+	if code, _, _ = runTest(t, "list", "-o", "xml"); code != 2 {
+		t.Errorf("list exited with %d for an unknown output, expected 2", code)
+	}
+}
+
+func TestContractTest(t *testing.T) {
+	yml, env := newTestFiles(t)
+
+	junit := filepath.Join(t.TempDir(), "report.xml")
+
+	code, stdout, stderr := runTest(t, "test", "-f", yml, "--env-file", env, "--junit", junit)
+	if code != 1 {
+		t.Fatalf("test exited with %d, expected 1 as the path param is not set: %s%s", code, stdout, stderr)
+	}
+
+	if !strings.Contains(stdout, "FAIL  todoRoom.getTodo 200") || !strings.Contains(stdout, `jsonPath $.path: expected /todos/1, got /todos/{id}`) {
+		t.Errorf("test printed %s", stdout)
+	}
+
+	report, err := os.ReadFile(junit)
+	if err != nil || !bytes.Contains(report, []byte(`<testcase name="getTodo" classname="todoRoom"`)) {
+		t.Errorf("test wrote the junit report %s, %v", report, err)
+	}
+}
+
+const testOpenAPI = `
+openapi: 3.0.3
+info:
+  title: Todos
+  version: 1.0.0
+paths:
+  /todos/{id}:
+    get:
+      operationId: getTodo
+      tags: [todos]
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        '200':
+          description: todo
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  todo:
+                    type: string
+`
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+
+	spec := filepath.Join(dir, "openapi.yml")
+	yml := filepath.Join(dir, "integration.yml")
+	dto := filepath.Join(dir, "dto", "dto_gen.go")
+
+	if err := os.WriteFile(spec, []byte(testOpenAPI), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runTest(t, "import", spec, "--out", yml, "--dto", dto, "--base-url", "http://localhost:8080")
+	if code != 0 {
+		t.Fatalf("import exited with %d: %s%s", code, stdout, stderr)
+	}
+
+	source, err := os.ReadFile(dto)
+	if err != nil || !bytes.Contains(source, []byte("type GetTodoResponse struct {")) {
+		t.Errorf("import wrote the DTOs %s, %v", source, err)
+	}
+
+	code, stdout, stderr = runTest(t, "list", "-f", yml)
+	if code != 0 || !strings.Contains(stdout, "getTodo") || !strings.Contains(stdout, "todos/{id}") {
+		t.Errorf("list of the imported yml exited with %d: %s%s", code, stdout, stderr)
+	}
+}
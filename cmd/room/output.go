@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/elevator"
+	"github.com/WEG-Technology/room/internal/maputil"
+	"io"
+	"net/http"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	outputPretty = "pretty"
+	outputJson   = "json"
+	outputRaw    = "raw"
+)
+
+var outputs = []string{outputPretty, outputJson, outputRaw}
+
+type responseOutput struct {
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Status     int               `json:"status"`
+	Headers    map[string]string `json:"headers"`
+	DurationMs float64           `json:"durationMs"`
+	Body       any               `json:"body"`
+}
+
+func newResponseOutput(response room.Response) responseOutput {
+	output := responseOutput{
+		Method:     response.Request.Method,
+		URL:        response.Request.URI.String(),
+		Status:     response.StatusCode,
+		Headers:    headers(response.Header),
+		DurationMs: float64(response.Timing.Total) / float64(time.Millisecond),
+		Body:       string(response.Data),
+	}
+
+	if json.Valid(response.Data) {
+		output.Body = json.RawMessage(response.Data)
+	}
+
+	return output
+}
+
+// printResponse writes the status, the headers and the indented body, the response as json or only the body
+func printResponse(w io.Writer, output string, response room.Response) error {
+	switch output {
+	case outputJson:
+		return writeJson(w, newResponseOutput(response))
+	case outputRaw:
+		_, err := w.Write(response.Data)
+		return err
+	}
+
+	fmt.Fprintf(w, "%s %s\n", response.Request.Method, response.Request.URI.String())
+	fmt.Fprintf(w, "%d %s in %s\n", response.StatusCode, http.StatusText(response.StatusCode), response.Timing.Total.Round(time.Millisecond))
+
+	responseHeaders := headers(response.Header)
+
+	for _, key := range maputil.SortedKeys(responseHeaders) {
+		fmt.Fprintf(w, "%s: %s\n", key, responseHeaders[key])
+	}
+
+	if len(response.Data) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+
+	if json.Indent(&body, response.Data, "", "  ") != nil {
+		body.Reset()
+		body.Write(response.Data)
+	}
+
+	_, err := fmt.Fprintf(w, "\n%s\n", bytes.TrimRight(body.Bytes(), "\n"))
+
+	return err
+}
+
+// printResponses prints the responses sorted by their room key
+func printResponses(w io.Writer, output string, responses map[string]room.Response) error {
+	if output == outputJson {
+		outputs := map[string]responseOutput{}
+
+		for key, response := range responses {
+			outputs[key] = newResponseOutput(response)
+		}
+
+		return writeJson(w, outputs)
+	}
+
+	for i, key := range maputil.SortedKeys(responses) {
+		if output == outputPretty {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+
+			fmt.Fprintf(w, "== %s ==\n", key)
+		}
+
+		if err := printResponse(w, output, responses[key]); err != nil {
+			return err
+		}
+
+		if output == outputRaw {
+			fmt.Fprintln(w)
+		}
+	}
+
+	return nil
+}
+
+type requestOutput struct {
+	Room          string `json:"room"`
+	Request       string `json:"request"`
+	Method        string `json:"method"`
+	Path          string `json:"path"`
+	ConcurrentKey string `json:"concurrentKey,omitempty"`
+}
+
+// printRequests lists the requests as a table, as json or as tab separated lines
+func printRequests(w io.Writer, output string, el elevator.Elevator) error {
+	requests := []requestOutput{}
+
+	for _, roomKey := range maputil.SortedKeys(el.Config.Flat.Rooms) {
+		configRequests := el.Config.Flat.Rooms[roomKey].Requests
+
+		for _, requestKey := range maputil.SortedKeys(configRequests) {
+			req := configRequests[requestKey]
+
+			method := req.Method
+			if method == "" {
+				method = room.GET.String()
+			}
+
+			requests = append(requests, requestOutput{
+				Room:          roomKey,
+				Request:       requestKey,
+				Method:        method,
+				Path:          req.Path,
+				ConcurrentKey: req.ConcurrentKey,
+			})
+		}
+	}
+
+	if output == outputJson {
+		return writeJson(w, requests)
+	}
+
+	if output == outputRaw {
+		for _, r := range requests {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Room, r.Request, r.Method, r.Path, r.ConcurrentKey)
+		}
+
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ROOM\tREQUEST\tMETHOD\tPATH\tCONCURRENT KEY")
+
+	for _, r := range requests {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Room, r.Request, r.Method, r.Path, r.ConcurrentKey)
+	}
+
+	return tw.Flush()
+}
+
+type assertionOutput struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+type contractOutput struct {
+	Room       string            `json:"room"`
+	Request    string            `json:"request"`
+	Passed     bool              `json:"passed"`
+	Status     int               `json:"status"`
+	DurationMs float64           `json:"durationMs"`
+	Error      string            `json:"error,omitempty"`
+	Assertions []assertionOutput `json:"assertions"`
+}
+
+// printReport writes a line for every contract with its failed assertions and a summary
+func printReport(w io.Writer, output string, report elevator.ContractReport) error {
+	if output == outputJson {
+		results := []contractOutput{}
+
+		for _, result := range report.Results {
+			c := contractOutput{
+				Room:       result.Room,
+				Request:    result.Request,
+				Passed:     result.Passed(),
+				Status:     result.StatusCode,
+				DurationMs: float64(result.Duration) / float64(time.Millisecond),
+				Assertions: []assertionOutput{},
+			}
+
+			if result.Err != nil {
+				c.Error = result.Err.Error()
+			}
+
+			for _, a := range result.Assertions {
+				c.Assertions = append(c.Assertions, assertionOutput(a))
+			}
+
+			results = append(results, c)
+		}
+
+		return writeJson(w, results)
+	}
+
+	passed := 0
+
+	for _, result := range report.Results {
+		name := result.Room + "." + result.Request
+		duration := result.Duration.Round(time.Millisecond)
+
+		switch {
+		case result.Err != nil:
+			fmt.Fprintf(w, "ERROR %s (%s)\n    %v\n", name, duration, result.Err)
+		case result.Passed():
+			passed++
+			fmt.Fprintf(w, "PASS  %s %d (%s)\n", name, result.StatusCode, duration)
+		default:
+			fmt.Fprintf(w, "FAIL  %s %d (%s)\n", name, result.StatusCode, duration)
+
+			for _, failure := range result.Failures() {
+				fmt.Fprintf(w, "    %s: %s\n", failure.Name, failure.Message)
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d passed, %d failed in %s\n", passed, len(report.Results)-passed, report.Duration.Round(time.Millisecond))
+
+	return err
+}
+
+func headers(header room.IHeader) map[string]string {
+	values := map[string]string{}
+
+	if header == nil {
+		return values
+	}
+
+	header.Properties().Each(func(key string, value any) {
+		values[key] = fmt.Sprint(value)
+	})
+
+	return values
+}
+
+func writeJson(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
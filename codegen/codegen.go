@@ -0,0 +1,486 @@
+// Package codegen generates typed Go clients from the integration yml of the elevator engine.
+//
+// Every room becomes a struct and every request a method of it, the dynamic contents of a request
+// become its payload struct and the `{param}`s of its path become string arguments:
+//
+//	engine := elevator.NewElevatorEngine(el)
+//	engine.WarmUp()
+//
+//	client := todoclient.New(engine)
+//	response, err := client.TodoRoom.AddTodo(todoclient.TodoRoomAddTodoRequest{Todo: "lorem"})
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/WEG-Technology/room/elevator"
+	"github.com/WEG-Technology/room/internal/maputil"
+	"go/format"
+	"go/token"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const defaultPackage = "client"
+
+var pathParamPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+type generator struct {
+	packageName string
+	source      string
+	types       []typeModel
+	names       map[string]string
+}
+
+type OptionGenerate func(generator *generator)
+
+// WithPackage sets the package name of the generated file, it is `client` by default
+func WithPackage(name string) OptionGenerate {
+	return func(generator *generator) {
+		generator.packageName = name
+	}
+}
+
+// WithSource names the integration yml in the header comment of the generated file
+func WithSource(source string) OptionGenerate {
+	return func(generator *generator) {
+		generator.source = source
+	}
+}
+
+// Generate returns the formatted source of the client package for the config
+func Generate(config elevator.IntegrationConfig, opts ...OptionGenerate) ([]byte, error) {
+	g := &generator{packageName: defaultPackage, source: "integration.yml", names: map[string]string{}}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if !token.IsIdentifier(g.packageName) {
+		return nil, fmt.Errorf("codegen: %q is not a valid package name", g.packageName)
+	}
+
+	model, err := g.model(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	if err = clientTemplate.Execute(&buf, model); err != nil {
+		return nil, err
+	}
+
+	source, err := format.Source(buf.Bytes())
+
+	if err != nil {
+		return nil, fmt.Errorf("codegen: generated invalid code: %w", err)
+	}
+
+	return source, nil
+}
+
+type fileModel struct {
+	Package     string
+	Source      string
+	Rooms       []roomModel
+	Concurrents []concurrentModel
+	Types       []typeModel
+}
+
+type roomModel struct {
+	Name     string
+	Key      string
+	Requests []requestModel
+}
+
+type requestModel struct {
+	Name       string
+	Key        string
+	Method     string
+	Path       string
+	Payload    string
+	PathParams []paramModel
+}
+
+type paramModel struct {
+	Name string
+	Key  string
+}
+
+type concurrentModel struct {
+	Name string
+	Key  string
+}
+
+type typeModel struct {
+	Name   string
+	Doc    string
+	Fields []fieldModel
+}
+
+type fieldModel struct {
+	Name string
+	Type string
+	Tag  string
+	Doc  string
+}
+
+func (g *generator) model(config elevator.IntegrationConfig) (fileModel, error) {
+	model := fileModel{Package: g.packageName, Source: g.source}
+
+	// the identifiers of the generated file, the room structs and the payload types share the package scope
+	if err := g.declare("Client", "the client"); err != nil {
+		return model, err
+	}
+
+	if err := g.declare("New", "the constructor"); err != nil {
+		return model, err
+	}
+
+	concurrentKeys := map[string]bool{}
+
+	for _, roomKey := range maputil.SortedKeys(config.Flat.Rooms) {
+		configRoom := config.Flat.Rooms[roomKey]
+
+		r := roomModel{Name: GoName(roomKey), Key: roomKey}
+
+		if err := g.declare(r.Name, "room "+roomKey); err != nil {
+			return model, err
+		}
+
+		methods := map[string]string{}
+
+		for _, requestKey := range maputil.SortedKeys(configRoom.Requests) {
+			req := configRoom.Requests[requestKey]
+
+			// streams and websockets are opened by the engine, they are not sent like the requests
+			if req.Stream != "" || configRoom.Connection.Protocol == elevator.ProtocolWebSocket {
+				continue
+			}
+
+			request, err := g.request(r.Name, roomKey, requestKey, req)
+
+			if err != nil {
+				return model, err
+			}
+
+			if other, exists := methods[request.Name]; exists {
+				return model, fmt.Errorf("codegen: requests %s and %s of room %s are both named %s", other, requestKey, roomKey, request.Name)
+			}
+
+			methods[request.Name] = requestKey
+
+			if req.ConcurrentKey != "" {
+				concurrentKeys[req.ConcurrentKey] = true
+			}
+
+			r.Requests = append(r.Requests, request)
+		}
+
+		model.Rooms = append(model.Rooms, r)
+	}
+
+	methods := map[string]string{}
+
+	for _, key := range maputil.SortedKeys(concurrentKeys) {
+		c := concurrentModel{Name: "Concurrent" + GoName(key), Key: key}
+
+		if other, exists := methods[c.Name]; exists {
+			return model, fmt.Errorf("codegen: concurrent keys %s and %s are both named %s", other, key, c.Name)
+		}
+
+		methods[c.Name] = key
+		model.Concurrents = append(model.Concurrents, c)
+	}
+
+	model.Types = g.types
+
+	return model, nil
+}
+
+func (g *generator) request(roomName, roomKey, requestKey string, req elevator.Request) (requestModel, error) {
+	method := req.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	request := requestModel{
+		Name:   GoName(requestKey),
+		Key:    requestKey,
+		Method: method,
+		Path:   req.Path,
+	}
+
+	used := map[string]bool{"payload": true, "opts": true}
+
+	for _, match := range pathParamPattern.FindAllStringSubmatch(req.Path, -1) {
+		name := unexportedName(match[1])
+
+		for used[name] || token.IsKeyword(name) {
+			name += "Param"
+		}
+
+		used[name] = true
+		request.PathParams = append(request.PathParams, paramModel{Name: name, Key: match[1]})
+	}
+
+	// the body and the query dynamic contents are filled from the same payload by DynamicExecute
+	dynamicContents := append(append([]elevator.DynamicContent(nil), req.Body.DynamicContent...), req.Query.DynamicContent...)
+
+	if len(dynamicContents) == 0 {
+		return request, nil
+	}
+
+	name := roomName + request.Name + "Request"
+
+	if err := g.structType(name, fmt.Sprintf("is the payload of %s.%s", roomKey, requestKey), dynamicContents); err != nil {
+		return request, err
+	}
+
+	request.Payload = name
+
+	return request, nil
+}
+
+func (g *generator) structType(name, doc string, dynamicContents []elevator.DynamicContent) error {
+	if err := g.declare(name, doc); err != nil {
+		return err
+	}
+
+	t := typeModel{Name: name, Doc: doc}
+	index := len(g.types)
+	g.types = append(g.types, t)
+
+	fields := map[string]string{}
+
+	for _, dynamicContent := range dynamicContents {
+		// fixed values are not part of the payload
+		if dynamicContent.Value != nil {
+			continue
+		}
+
+		field := fieldModel{Name: GoName(dynamicContent.Key), Doc: fieldDoc(dynamicContent)}
+
+		if other, exists := fields[field.Name]; exists {
+			return fmt.Errorf("codegen: keys %s and %s of %s are both named %s", other, dynamicContent.Key, name, field.Name)
+		}
+
+		fields[field.Name] = dynamicContent.Key
+
+		fieldType, err := g.fieldType(name, field.Name, dynamicContent)
+
+		if err != nil {
+			return err
+		}
+
+		field.Tag = fmt.Sprintf("`json:%q`", dynamicContent.Key)
+
+		if !dynamicContent.IsRequired() {
+			field.Tag = fmt.Sprintf("`json:%q`", dynamicContent.Key+",omitempty")
+
+			if !strings.HasPrefix(fieldType, "[]") && !strings.HasPrefix(fieldType, "map[") && fieldType != "any" {
+				fieldType = "*" + fieldType
+			}
+		}
+
+		field.Type = fieldType
+
+		t.Fields = append(t.Fields, field)
+	}
+
+	g.types[index] = t
+
+	return nil
+}
+
+// fieldType returns the Go type of the field, objects with properties are declared as `ParentField` structs
+func (g *generator) fieldType(parent, field string, dynamicContent elevator.DynamicContent) (string, error) {
+	name := parent + field
+
+	switch dynamicContent.Type {
+	case elevator.DynamicTypeString:
+		return "string", nil
+	case elevator.DynamicTypeBoolean:
+		return "bool", nil
+	case elevator.DynamicTypeInteger:
+		return "int", nil
+	case elevator.DynamicTypeNumber:
+		return "float64", nil
+	case elevator.DynamicTypeObject:
+		if len(dynamicContent.Properties) == 0 {
+			return "map[string]any", nil
+		}
+
+		if err := g.structType(name, "is the "+dynamicContent.Key+" object of "+parent, dynamicContent.Properties); err != nil {
+			return "", err
+		}
+
+		return name, nil
+	case elevator.DynamicTypeArray:
+		if dynamicContent.Items == nil {
+			return "[]any", nil
+		}
+
+		item := *dynamicContent.Items
+		if item.Key == "" {
+			item.Key = dynamicContent.Key + " item"
+		}
+
+		itemType, err := g.fieldType(parent, field+"Item", item)
+
+		if err != nil {
+			return "", err
+		}
+
+		return "[]" + itemType, nil
+	}
+
+	return "any", nil
+}
+
+func (g *generator) declare(name, owner string) error {
+	if other, exists := g.names[name]; exists {
+		return fmt.Errorf("codegen: %s and %s are both named %s", other, owner, name)
+	}
+
+	g.names[name] = owner
+
+	return nil
+}
+
+func fieldDoc(dynamicContent elevator.DynamicContent) string {
+	var notes []string
+
+	if dynamicContent.Format != "" {
+		notes = append(notes, "format "+dynamicContent.Format)
+	}
+
+	if len(dynamicContent.Enum) > 0 {
+		values := make([]string, 0, len(dynamicContent.Enum))
+		for _, value := range dynamicContent.Enum {
+			values = append(values, fmt.Sprint(value))
+		}
+
+		notes = append(notes, "one of "+strings.Join(values, ", "))
+	}
+
+	if dynamicContent.Default != nil {
+		notes = append(notes, fmt.Sprintf("default %v", dynamicContent.Default))
+	}
+
+	return strings.Join(notes, ", ")
+}
+
+// GoName converts keys like `addTodo`, `add_todo` or `add-todo` to `AddTodo`
+func GoName(key string) string {
+	var b strings.Builder
+
+	upper := true
+
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteString("X")
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	if b.Len() == 0 {
+		return "X"
+	}
+
+	return b.String()
+}
+
+func unexportedName(key string) string {
+	name := []rune(GoName(key))
+
+	for i := 0; i < len(name) && unicode.IsUpper(name[i]); i++ {
+		// keeps the initialisms readable, `ID` becomes `id` and `URLPath` becomes `urlPath`
+		if i > 0 && i+1 < len(name) && unicode.IsLower(name[i+1]) {
+			break
+		}
+
+		name[i] = unicode.ToLower(name[i])
+	}
+
+	return string(name)
+}
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by room gen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/elevator"
+)
+
+// Client executes the requests of {{.Source}}, room.DecodeDTO decodes the responses into typed DTOs
+type Client struct {
+{{- range .Rooms}}
+	{{.Name}} *{{.Name}}
+{{- end}}
+	engine elevator.IOptionExecutor
+}
+
+// New creates a client on top of a warmed up engine of {{.Source}}
+func New(engine elevator.IOptionExecutor) *Client {
+	return &Client{
+{{- range .Rooms}}
+		{{.Name}}: &{{.Name}}{engine: engine},
+{{- end}}
+		engine: engine,
+	}
+}
+{{range .Concurrents}}
+// {{.Name}} executes the requests with the concurrent key {{.Key}}
+func (c *Client) {{.Name}}(appliedRooms ...string) map[string]room.Response {
+	return c.engine.ExecuteConcurrent({{printf "%q" .Key}}, appliedRooms...)
+}
+{{end}}
+{{- range $room := .Rooms}}
+// {{.Name}} executes the requests of the room {{.Key}}
+type {{.Name}} struct {
+	engine elevator.IOptionExecutor
+}
+{{range .Requests}}
+// {{.Name}} sends {{.Method}} {{.Path}}
+func (r *{{$room.Name}}) {{.Name}}({{range .PathParams}}{{.Name}} string, {{end}}{{if .Payload}}payload {{.Payload}}, {{end}}opts ...room.OptionRequest) (room.Response, error) {
+{{- if .PathParams}}
+	opts = append([]room.OptionRequest{room.WithPathParams(map[string]string{
+	{{- range .PathParams}}
+		{{printf "%q" .Key}}: {{.Name}},
+	{{- end}}
+	})}, opts...)
+{{end}}
+{{- if .Payload}}
+	return r.engine.DynamicExecuteWith({{printf "%q" $room.Key}}, {{printf "%q" .Key}}, payload, opts...)
+{{- else}}
+	return r.engine.ExecuteWith({{printf "%q" $room.Key}}, {{printf "%q" .Key}}, opts...)
+{{- end}}
+}
+{{end}}
+{{- end}}
+{{- range .Types}}
+// {{.Name}} {{.Doc}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}}{{if .Doc}} // {{.Doc}}{{end}}
+{{- end}}
+}
+{{end}}`))
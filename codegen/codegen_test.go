@@ -0,0 +1,169 @@
+package codegen
+
+import (
+	"encoding/json"
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/elevator"
+	"github.com/WEG-Technology/room/examples/gen_example/todoclient"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+const exampleDir = "../examples/gen_example/"
+
+func loadExample(t *testing.T) elevator.Elevator {
+	t.Helper()
+
+	el, err := elevator.Load(elevator.WithFiles(exampleDir + "integration.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return el
+}
+
+// TestGenerate_Example keeps the generated example client in sync with the generator
+func TestGenerate_Example(t *testing.T) {
+	source, err := Generate(loadExample(t).Config, WithPackage("todoclient"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden, err := os.ReadFile(exampleDir + "todoclient/todoclient_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(source) != string(golden) {
+		t.Errorf("Generate() differs from %stodoclient/todoclient_gen.go, run go generate ./examples/gen_example\n%s", exampleDir, source)
+	}
+}
+
+func TestGenerate_Client(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"path": r.URL.Path, "query": r.URL.RawQuery, "body": strings.TrimSpace(string(body))})
+	}))
+	defer server.Close()
+
+	engine := elevator.NewElevatorEngine(loadExample(t), elevator.WithBaseUrl("todoRoom", server.URL), elevator.WithBaseUrl("userRoom", server.URL))
+	engine.WarmUp()
+	client := todoclient.New(engine)
+
+	type echo struct {
+		Path  string `json:"path"`
+		Query string `json:"query"`
+		Body  string `json:"body"`
+	}
+
+	priority := "high"
+
+	for _, c := range []struct {
+		name     string
+		send     func() (room.Response, error)
+		expected echo
+	}{
+		{
+			name: "path param",
+			send: func() (room.Response, error) {
+				return client.UserRoom.UpdateUser("7", todoclient.UserRoomUpdateUserRequest{Email: "user@example.com"})
+			},
+			expected: echo{Path: "/users/7", Body: `{"email":"user@example.com"}`},
+		},
+		{
+			name: "nested payload",
+			send: func() (room.Response, error) {
+				return client.TodoRoom.AddTodo(todoclient.TodoRoomAddTodoRequest{
+					Todo:     "lorem",
+					UserId:   1,
+					Priority: &priority,
+					Tags:     []todoclient.TodoRoomAddTodoRequestTagsItem{{Name: "home"}},
+				})
+			},
+			expected: echo{Path: "/todos/add", Body: `{"completed":false,"priority":"high","tags":[{"name":"home"}],"todo":"lorem","userId":1}`},
+		},
+		{
+			name: "required nested object",
+			send: func() (room.Response, error) {
+				return client.UserRoom.AddUser(todoclient.UserRoomAddUserRequest{
+					Name:    "john",
+					Address: todoclient.UserRoomAddUserRequestAddress{City: "istanbul"},
+				})
+			},
+			expected: echo{Path: "/users/add", Body: `{"address":{"city":"istanbul"},"name":"john"}`},
+		},
+		{
+			name: "query default",
+			send: func() (room.Response, error) {
+				return client.TodoRoom.ListTodos(todoclient.TodoRoomListTodosRequest{})
+			},
+			expected: echo{Path: "/todos", Query: "limit=10"},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := room.DecodeDTO[echo](c.send())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if actual != c.expected {
+				t.Errorf("the client sent %+v, expected %+v", actual, c.expected)
+			}
+		})
+	}
+}
+
+func TestGenerate_NameCollision(t *testing.T) {
+	_, err := Generate(elevator.IntegrationConfig{Flat: elevator.Flat{Rooms: map[string]elevator.Room{
+		"todoRoom": {Requests: map[string]elevator.Request{"add-todo": {}, "addTodo": {}}},
+	}}})
+
+	if err == nil || !strings.Contains(err.Error(), "are both named AddTodo") {
+		t.Errorf("Generate() returned %v, expected a name collision", err)
+	}
+
+	if _, err = Generate(elevator.IntegrationConfig{}, WithPackage("todo-client")); err == nil {
+		t.Error("Generate() returned nil for an invalid package name")
+	}
+}
+
+func TestGenerate_QuotedKeys(t *testing.T) {
+	source, err := Generate(elevator.IntegrationConfig{Flat: elevator.Flat{Rooms: map[string]elevator.Room{
+		`todo"Room`: {Requests: map[string]elevator.Request{`get\todo`: {Method: "GET", Path: "todos"}}},
+	}}})
+
+	if err != nil {
+		t.Fatalf("Generate() returned error: %v", err)
+	}
+
+	if !strings.Contains(string(source), `r.engine.ExecuteWith("todo\"Room", "get\\todo", opts...)`) {
+		t.Errorf("Generate() returned %s, expected the keys as quoted string literals", source)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for key, expected := range map[string]string{
+		"addTodo":      "AddTodo",
+		"add_todo":     "AddTodo",
+		"add-todo":     "AddTodo",
+		"2fa":          "X2fa",
+		"postal_code":  "PostalCode",
+		"X-Request-Id": "XRequestId",
+	} {
+		if actual := GoName(key); actual != expected {
+			t.Errorf("GoName(%q) = %q, expected %q", key, actual, expected)
+		}
+	}
+
+	for key, expected := range map[string]string{"id": "id", "ID": "id", "URLPath": "urlPath", "type": "type"} {
+		if actual := unexportedName(key); actual != expected {
+			t.Errorf("unexportedName(%q) = %q, expected %q", key, actual, expected)
+		}
+	}
+}
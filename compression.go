@@ -0,0 +1,177 @@
+package room
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+	EncodingBrotli  = "br"
+	EncodingZstd    = "zstd"
+
+	headerKeyContentEncoding = "Content-Encoding"
+	headerKeyAcceptEncoding  = "Accept-Encoding"
+)
+
+// Encodings are the content encodings room compresses and decompresses
+var Encodings = []string{EncodingGzip, EncodingDeflate, EncodingBrotli, EncodingZstd}
+
+// maxDecodedSize limits the decoded bodies, so a small compressed body can not exhaust the memory
+var maxDecodedSize int64 = 64 << 20
+
+// compression compresses the request bodies of a connector and advertises the encodings it decodes
+type compression struct {
+	encoding       string
+	threshold      int
+	acceptEncoding string
+}
+
+// WithRequestCompression compresses the request bodies of at least threshold bytes with one of the Encodings,
+// smaller bodies are sent as they are since compressing them saves nothing
+func WithRequestCompression(encoding string, threshold int) OptionConnector {
+	return func(connector *Connector) {
+		connector.compression.encoding = encoding
+		connector.compression.threshold = threshold
+	}
+}
+
+// WithAcceptEncoding sends the Accept-Encoding header with the given encodings, all Encodings when none is given,
+// unless the request sets its own. The responses are decoded by their Content-Encoding either way.
+func WithAcceptEncoding(encodings ...string) OptionConnector {
+	return func(connector *Connector) {
+		if len(encodings) == 0 {
+			encodings = Encodings
+		}
+
+		connector.compression.acceptEncoding = strings.Join(encodings, ", ")
+	}
+}
+
+// compress compresses the body when it reaches the threshold, it reports the encoding of the returned body
+func (c compression) compress(body *bytes.Buffer) (*bytes.Buffer, string) {
+	if c.encoding == "" || body.Len() < c.threshold {
+		return body, ""
+	}
+
+	var compressed bytes.Buffer
+
+	writer, err := newEncoder(c.encoding, &compressed)
+
+	if err != nil {
+		return body, ""
+	}
+
+	_, err = writer.Write(body.Bytes())
+
+	if closeErr := writer.Close(); err != nil || closeErr != nil {
+		return body, ""
+	}
+
+	return &compressed, c.encoding
+}
+
+func newEncoder(encoding string, w io.Writer) (io.WriteCloser, error) {
+	switch strings.ToLower(encoding) {
+	case EncodingGzip:
+		return gzip.NewWriter(w), nil
+	case EncodingDeflate:
+		return zlib.NewWriter(w), nil
+	case EncodingBrotli:
+		return brotli.NewWriter(w), nil
+	case EncodingZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("room: content encoding %q is not supported", encoding)
+	}
+}
+
+func newDecoder(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch strings.ToLower(encoding) {
+	case EncodingGzip, "x-gzip":
+		return gzip.NewReader(r)
+	case EncodingDeflate:
+		return newDeflateReader(r)
+	case EncodingBrotli:
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case EncodingZstd:
+		decoder, err := zstd.NewReader(r)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return decoder.IOReadCloser(), nil
+	case "identity":
+		return io.NopCloser(r), nil
+	default:
+		return nil, fmt.Errorf("room: content encoding %q is not supported", encoding)
+	}
+}
+
+// newDeflateReader reads the zlib stream of the deflate encoding, some servers send the raw deflate stream instead
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reader, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		return reader, nil
+	}
+
+	return flate.NewReader(bytes.NewReader(data)), nil
+}
+
+// DecodeBody decodes the body by the Content-Encoding header, for the middlewares which keep the bodies like recorders.
+// The body is returned as it is with the error when an encoding is not supported or the decoded body is too large.
+func DecodeBody(header http.Header, body []byte) ([]byte, error) {
+	return decode(header, body)
+}
+
+// decode decodes the data by the Content-Encoding header, the encodings are undone in the reverse order of their appliance.
+// Data of an unsupported encoding or larger than maxDecodedSize once decoded is returned as it is with the error.
+func decode(header http.Header, data []byte) ([]byte, error) {
+	encoded := data
+
+	encodings := strings.Split(header.Get(headerKeyContentEncoding), ",")
+
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.TrimSpace(encodings[i])
+
+		if encoding == "" || len(data) == 0 {
+			continue
+		}
+
+		reader, err := newDecoder(encoding, bytes.NewReader(data))
+
+		if err != nil {
+			return encoded, err
+		}
+
+		decoded, err := io.ReadAll(io.LimitReader(reader, maxDecodedSize+1))
+		_ = reader.Close()
+
+		if err != nil {
+			return encoded, err
+		}
+
+		if int64(len(decoded)) > maxDecodedSize {
+			return encoded, fmt.Errorf("room: decoded body exceeds %d bytes", maxDecodedSize)
+		}
+
+		data = decoded
+	}
+
+	return data, nil
+}
@@ -0,0 +1,124 @@
+package room
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func compressed(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer, err := newEncoder(encoding, &buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = writer.Write(data)
+	_ = writer.Close()
+
+	return buf.Bytes()
+}
+
+func TestConnector_Compression(t *testing.T) {
+	payload := map[string]any{"note": strings.Repeat("room ", 100)}
+
+	for _, encoding := range Encodings {
+		var received []byte
+		var acceptEncoding string
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			received, _ = decode(r.Header, data)
+			acceptEncoding = r.Header.Get("Accept-Encoding")
+
+			if r.Header.Get("Content-Encoding") != encoding {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Encoding", encoding)
+			_, _ = w.Write(compressed(t, encoding, received))
+		}))
+
+		connector := NewConnector(server.URL, WithRequestCompression(encoding, 64), WithAcceptEncoding())
+
+		response, err := connector.Do(NewRequest("notes", WithMethod(POST), WithBody(NewJsonBodyParser(payload))))
+
+		if err != nil || response.StatusCode != http.StatusOK {
+			t.Fatalf("%s: Do() = %d, %v", encoding, response.StatusCode, err)
+		}
+
+		if response.ResponseBody()["note"] != payload["note"] || response.RequestBody()["note"] != payload["note"] {
+			t.Errorf("%s: response = %s", encoding, response.Data)
+		}
+
+		if acceptEncoding != "gzip, deflate, br, zstd" || !strings.Contains(string(received), "room room") {
+			t.Errorf("%s: Accept-Encoding = %q, received = %s", encoding, acceptEncoding, received)
+		}
+
+		server.Close()
+	}
+}
+
+func TestConnector_CompressionThreshold(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "" || r.Header.Get("Accept-Encoding") != "identity" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	connector := NewConnector(server.URL, WithRequestCompression(EncodingGzip, 1024), WithAcceptEncoding(EncodingGzip))
+
+	// the small body is not compressed and the Accept-Encoding of the request is kept
+	response, err := connector.Do(NewRequest("notes", WithMethod(POST), WithBody(NewJsonBodyParser(map[string]any{"note": "short"})),
+		WithHeader(NewHeader().Add("Accept-Encoding", "identity"))))
+
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Errorf("Do() = %d, %v", response.StatusCode, err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	data := []byte(`{"ok":true}`)
+
+	header := http.Header{"Content-Encoding": {"gzip, br"}}
+
+	if decoded, err := decode(header, compressed(t, EncodingBrotli, compressed(t, EncodingGzip, data))); err != nil || !bytes.Equal(decoded, data) {
+		t.Errorf("decode() = %s, %v", decoded, err)
+	}
+
+	// the raw deflate stream some servers send instead of zlib
+	var raw bytes.Buffer
+	writer, _ := flate.NewWriter(&raw, flate.DefaultCompression)
+	_, _ = writer.Write(data)
+	_ = writer.Close()
+
+	if decoded, err := decode(http.Header{"Content-Encoding": {"deflate"}}, raw.Bytes()); err != nil || !bytes.Equal(decoded, data) {
+		t.Errorf("decode() raw deflate = %s, %v", decoded, err)
+	}
+
+	if decoded, err := decode(http.Header{"Content-Encoding": {"compress"}}, data); err == nil || !bytes.Equal(decoded, data) {
+		t.Errorf("decode() unsupported = %s, %v", decoded, err)
+	}
+}
+
+func TestDecode_MaxSize(t *testing.T) {
+	defer func(size int64) { maxDecodedSize = size }(maxDecodedSize)
+	maxDecodedSize = 1024
+
+	bomb := compressed(t, EncodingGzip, bytes.Repeat([]byte("0"), 4096))
+
+	if decoded, err := decode(http.Header{"Content-Encoding": {"gzip"}}, bomb); err == nil || !bytes.Equal(decoded, bomb) {
+		t.Errorf("decode() = %d bytes, %v", len(decoded), err)
+	}
+}
@@ -1,12 +1,26 @@
 package room
 
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
 type Connector struct {
 	baseUrl        string
 	Header         IHeader
 	contextBuilder IContextBuilder
 	authRequest    *Request
+	transport      http.RoundTripper
+	middlewares    []Middleware
+	responseHooks  []ResponseHook
+	circuitBreaker *CircuitBreaker
+	compression    compression
 }
 
+// ResponseHook is called with the outcome of every request sent by the connector
+type ResponseHook func(response Response, err error)
+
 type OptionConnector func(info *Connector)
 
 func WithHeaderConnector(header IHeader) OptionConnector {
@@ -21,6 +35,35 @@ func WithHeaderContextBuilder(ctxBuilder IContextBuilder) OptionConnector {
 	}
 }
 
+// WithTransport sets the base transport of the connector, http.DefaultTransport is used by default
+func WithTransport(transport http.RoundTripper) OptionConnector {
+	return func(connector *Connector) {
+		connector.transport = transport
+	}
+}
+
+// WithMiddleware wraps the transport of the connector, middlewares are applied in the given order
+func WithMiddleware(middlewares ...Middleware) OptionConnector {
+	return func(connector *Connector) {
+		connector.middlewares = append(connector.middlewares, middlewares...)
+	}
+}
+
+// WithResponseHook registers hooks which are called after every request of the connector
+func WithResponseHook(hooks ...ResponseHook) OptionConnector {
+	return func(connector *Connector) {
+		connector.responseHooks = append(connector.responseHooks, hooks...)
+	}
+}
+
+// WithCircuitBreaker fails the requests of the connector fast with ErrCircuitOpen while the breaker is open,
+// a breaker should not be shared by connectors of different partners
+func WithCircuitBreaker(breaker *CircuitBreaker) OptionConnector {
+	return func(connector *Connector) {
+		connector.circuitBreaker = breaker
+	}
+}
+
 func NewConnector(baseUrl string, opts ...OptionConnector) *Connector {
 	c := &Connector{
 		baseUrl: NewURI(baseUrl).String(),
@@ -30,6 +73,10 @@ func NewConnector(baseUrl string, opts ...OptionConnector) *Connector {
 		opt(c)
 	}
 
+	if c.transport != nil || len(c.middlewares) > 0 {
+		c.transport = chainMiddlewares(c.transport, c.middlewares)
+	}
+
 	return c
 }
 
@@ -37,10 +84,96 @@ func (c *Connector) Send(path string) (Response, error) {
 	return c.Do(NewRequest(path))
 }
 
+// Do sends a copy of the request with the connector's base url, header and context builder applied,
+// the given request is never modified so it can be reused concurrently
 func (c *Connector) Do(request *Request) (Response, error) {
-	return request.
+	response, err := c.send(c.prepare(request))
+
+	for _, hook := range c.responseHooks {
+		hook(response, err)
+	}
+
+	return response, err
+}
+
+// prepare returns a copy of the request with the connector's base url, header, context builder, transport and compression,
+// the connector's header is merged under the request header so the values of the request and of the call win
+func (c *Connector) prepare(request *Request) *Request {
+	r := request.
+		Clone().
 		SetBaseUrl(c.baseUrl).
-		MergeHeader(c.Header).
-		SetContextBuilder(c.contextBuilder).
-		Send()
+		MergeHeader(c.Header)
+
+	// a context builder of the request, like a request specific timeout, takes precedence over the connector's
+	if r.contextBuilder == nil {
+		r.SetContextBuilder(c.contextBuilder)
+	}
+
+	if c.transport != nil {
+		r.transport = c.transport
+	}
+
+	r.compression = c.compression
+
+	// a url given by the partner may point anywhere, the credentials are only sent to the host of the connector
+	if r.followed && !sameOrigin(c.baseUrl, r.path) {
+		r.withoutCredentials()
+	}
+
+	return r
+}
+
+func (c *Connector) send(r *Request) (Response, error) {
+	if c.circuitBreaker == nil {
+		return r.Send()
+	}
+
+	generation, err := c.circuitBreaker.allow()
+
+	if err != nil {
+		return Response{}, err
+	}
+
+	response, err := r.Send()
+
+	c.circuitBreaker.record(generation, response, err)
+
+	return response, err
+}
+
+// sameOrigin reports whether the url has the scheme, host and port of the base url, default ports are implied
+func sameOrigin(baseUrl, u string) bool {
+	base, err := url.Parse(baseUrl)
+
+	if err != nil {
+		return false
+	}
+
+	target, err := url.Parse(u)
+
+	if err != nil {
+		return false
+	}
+
+	// a relative url is sent to the base url
+	if target.Host == "" {
+		return true
+	}
+
+	return strings.EqualFold(base.Scheme, target.Scheme) &&
+		strings.EqualFold(base.Hostname(), target.Hostname()) &&
+		originPort(base) == originPort(target)
+}
+
+func originPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "https", "wss":
+		return "443"
+	}
+
+	return "80"
 }
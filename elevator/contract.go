@@ -0,0 +1,387 @@
+package elevator
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/internal/maputil"
+	"github.com/WEG-Technology/room/jsonpath"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// ExpectExists passes when the header or the json path is present
+	ExpectExists = "exists"
+	// ExpectAbsent passes when the header or the json path is missing
+	ExpectAbsent = "absent"
+)
+
+// Expect declares the assertions on the response of a request, RunContracts checks them
+type Expect struct {
+	Status     ExpectStatus      `yaml:"status,omitempty"`
+	Headers    map[string]string `yaml:"headers,omitempty"`
+	JSONSchema any               `yaml:"jsonSchema,omitempty"`
+	JSONPath   map[string]any    `yaml:"jsonPath,omitempty"`
+}
+
+// ExpectStatus is a single status code or a list of accepted status codes
+type ExpectStatus []int
+
+func (s *ExpectStatus) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var status int
+
+		if err := value.Decode(&status); err != nil {
+			return err
+		}
+
+		*s = ExpectStatus{status}
+
+		return nil
+	}
+
+	var statuses []int
+
+	if err := value.Decode(&statuses); err != nil {
+		return err
+	}
+
+	*s = statuses
+
+	return nil
+}
+
+// AssertionResult is the outcome of a single expectation
+type AssertionResult struct {
+	Name    string
+	Passed  bool
+	Message string
+}
+
+// Check asserts the response against the expectations
+func (e Expect) Check(response room.Response) []AssertionResult {
+	var results []AssertionResult
+
+	if len(e.Status) > 0 {
+		results = append(results, assertion("status", slices.Contains(e.Status, response.StatusCode),
+			fmt.Sprintf("expected %v, got %d", []int(e.Status), response.StatusCode)))
+	}
+
+	for _, key := range maputil.SortedKeys(e.Headers) {
+		actual := ""
+		if response.Header != nil {
+			actual = response.Header.Get(http.CanonicalHeaderKey(key))
+		}
+
+		results = append(results, assertion("header "+key, matchExpectedHeader(e.Headers[key], actual),
+			fmt.Sprintf("expected %s, got %q", e.Headers[key], actual)))
+	}
+
+	if e.JSONSchema == nil && len(e.JSONPath) == 0 {
+		return results
+	}
+
+	var document any
+
+	if err := json.Unmarshal(response.Data, &document); err != nil {
+		return append(results, assertion("json", false, fmt.Sprintf("response body is not json: %v", err)))
+	}
+
+	if e.JSONSchema != nil {
+		if schema, err := normalizeJson(e.JSONSchema); err != nil {
+			results = append(results, assertion("jsonSchema", false, fmt.Sprintf("schema is not json: %v", err)))
+		} else if unsupported := checkSchema(schema, "jsonSchema"); len(unsupported) > 0 {
+			results = append(results, assertion("jsonSchema", false, strings.ReplaceAll(errors.Join(unsupported...).Error(), "\n", "; ")))
+		} else {
+			errs := validateSchema(schema, document, "$")
+			results = append(results, assertion("jsonSchema", len(errs) == 0, strings.Join(errs, "; ")))
+		}
+	}
+
+	for _, expression := range maputil.SortedKeys(e.JSONPath) {
+		results = append(results, checkJsonPath(document, expression, e.JSONPath[expression]))
+	}
+
+	return results
+}
+
+func checkJsonPath(document any, expression string, expected any) AssertionResult {
+	name := "jsonPath " + expression
+
+	path, err := jsonpath.Parse(expression)
+
+	if err != nil {
+		return assertion(name, false, err.Error())
+	}
+
+	values := path.Get(document)
+
+	switch expected {
+	case ExpectExists:
+		return assertion(name, len(values) > 0, "expected to exist")
+	case ExpectAbsent:
+		return assertion(name, len(values) == 0, fmt.Sprintf("expected to be absent, got %v", values))
+	}
+
+	var actual any = values
+	if len(values) == 1 {
+		actual = values[0]
+	}
+
+	expected, err = normalizeJson(expected)
+
+	if err != nil {
+		return assertion(name, false, fmt.Sprintf("expected value is not json: %v", err))
+	}
+
+	return assertion(name, len(values) > 0 && reflect.DeepEqual(actual, expected), fmt.Sprintf("expected %v, got %v", expected, actual))
+}
+
+func matchExpectedHeader(expected, actual string) bool {
+	switch expected {
+	case ExpectExists:
+		return actual != ""
+	case ExpectAbsent:
+		return actual == ""
+	}
+
+	return expected == actual
+}
+
+func assertion(name string, passed bool, message string) AssertionResult {
+	if passed {
+		message = ""
+	}
+
+	return AssertionResult{Name: name, Passed: passed, Message: message}
+}
+
+// normalizeJson converts yaml decoded values to their encoding/json representation, like ints to float64,
+// values without one, like .inf, are returned as error
+func normalizeJson(v any) (any, error) {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized any
+	if err = json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+
+	return normalized, nil
+}
+
+// ContractResult is the outcome of a request with expectations
+type ContractResult struct {
+	Room       string
+	Request    string
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+	Assertions []AssertionResult
+}
+
+// Passed reports whether the request was sent and all the assertions passed
+func (r ContractResult) Passed() bool {
+	if r.Err != nil {
+		return false
+	}
+
+	for _, a := range r.Assertions {
+		if !a.Passed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Failures returns the failed assertions
+func (r ContractResult) Failures() []AssertionResult {
+	var failures []AssertionResult
+
+	for _, a := range r.Assertions {
+		if !a.Passed {
+			failures = append(failures, a)
+		}
+	}
+
+	return failures
+}
+
+// ContractReport keeps the results of RunContracts sorted by room and request key
+type ContractReport struct {
+	Results  []ContractResult
+	Duration time.Duration
+}
+
+// Passed reports whether all the contracts passed
+func (r ContractReport) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RunContracts executes every request which declares `expect` and checks its response,
+// the requests are sent one by one with their static content only
+func (e *ElevatorEngine) RunContracts(appliedRooms ...string) ContractReport {
+	startedAt := time.Now()
+
+	var report ContractReport
+
+	config := e.config()
+
+	for _, roomKey := range maputil.SortedKeys(config.Config.Flat.Rooms) {
+		if len(appliedRooms) > 0 && !slices.Contains(appliedRooms, roomKey) {
+			continue
+		}
+
+		requests := config.Config.Flat.Rooms[roomKey].Requests
+
+		for _, requestKey := range maputil.SortedKeys(requests) {
+			expect := requests[requestKey].Expect
+
+			if expect == nil {
+				continue
+			}
+
+			report.Results = append(report.Results, e.runContract(roomKey, requestKey, *expect))
+		}
+	}
+
+	report.Duration = time.Since(startedAt)
+
+	return report
+}
+
+func (e *ElevatorEngine) runContract(roomKey, requestKey string, expect Expect) ContractResult {
+	startedAt := time.Now()
+
+	response, err := e.Execute(roomKey, requestKey)
+
+	result := ContractResult{
+		Room:       roomKey,
+		Request:    requestKey,
+		StatusCode: response.StatusCode,
+		Duration:   time.Since(startedAt),
+	}
+
+	// an unexpected status is reported by the status assertion, other errors mean there is no response to check
+	var unexpectedStatus room.UnexpectedStatusError
+	if err != nil && !errors.As(err, &unexpectedStatus) {
+		result.Err = err
+		return result
+	}
+
+	result.Assertions = expect.Check(response)
+
+	return result
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+	duration  time.Duration
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML, every room is a test suite and every request a test case
+func (r ContractReport) WriteJUnit(w io.Writer) error {
+	suites := junitTestSuites{Time: junitTime(r.Duration)}
+	suiteIndex := map[string]int{}
+
+	for _, result := range r.Results {
+		index, ok := suiteIndex[result.Room]
+		if !ok {
+			index = len(suites.Suites)
+			suiteIndex[result.Room] = index
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: result.Room})
+		}
+
+		suite := &suites.Suites[index]
+
+		testCase := junitTestCase{Name: result.Request, ClassName: result.Room, Time: junitTime(result.Duration)}
+
+		if result.Err != nil {
+			testCase.Error = &junitMessage{Message: result.Err.Error(), Text: result.Err.Error()}
+			suite.Errors++
+		} else if failures := result.Failures(); len(failures) > 0 {
+			lines := make([]string, 0, len(failures))
+			for _, failure := range failures {
+				lines = append(lines, failure.Name+": "+failure.Message)
+			}
+
+			testCase.Failure = &junitMessage{Message: fmt.Sprintf("%d assertion(s) failed", len(failures)), Text: strings.Join(lines, "\n")}
+			suite.Failures++
+		}
+
+		suite.Tests++
+		suite.duration += result.Duration
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	for i := range suites.Suites {
+		suites.Suites[i].Time = junitTime(suites.Suites[i].duration)
+		suites.Tests += suites.Suites[i].Tests
+		suites.Failures += suites.Suites[i].Failures
+		suites.Errors += suites.Suites[i].Errors
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,174 @@
+package elevator
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/WEG-Technology/room"
+	"math"
+	"strings"
+	"testing"
+)
+
+const contractTestYml = `
+flat:
+  rooms:
+    todoRoom:
+      connection:
+        baseUrl: %s
+      requests:
+        getTodo:
+          method: "GET"
+          path: "todos/1"
+          expect:
+            status: 200
+            headers:
+              content-type: "application/json"
+              X-Missing: "absent"
+            jsonSchema:
+              type: object
+              required: [path, query]
+              properties:
+                path:
+                  type: string
+                  pattern: "^/todos/"
+                query:
+                  type: string
+            jsonPath:
+              "$.path": "/todos/1"
+              "$.body": exists
+        listTodos:
+          method: "GET"
+          path: "todos"
+          expect:
+            status: [201, 202]
+            jsonSchema:
+              type: object
+              properties:
+                path:
+                  type: integer
+            jsonPath:
+              "$.id": exists
+        withoutExpect:
+          method: "GET"
+          path: "todos"
+`
+
+func TestElevatorEngine_RunContracts(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	el, err := Load(WithBytes([]byte(strings.Replace(contractTestYml, "%s", server.URL, 1))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = el.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewElevatorEngine(el)
+	engine.WarmUp()
+
+	report := engine.RunContracts()
+
+	if len(report.Results) != 2 || report.Results[0].Request != "getTodo" || report.Results[1].Request != "listTodos" {
+		t.Fatalf("RunContracts() returned %+v, expected getTodo and listTodos", report.Results)
+	}
+
+	if !report.Results[0].Passed() {
+		t.Errorf("getTodo failed: %+v", report.Results[0].Failures())
+	}
+
+	failures := report.Results[1].Failures()
+	if report.Passed() || len(failures) != 3 {
+		t.Fatalf("listTodos returned failures %+v, expected status, jsonSchema and jsonPath", failures)
+	}
+
+	for i, expected := range []string{"expected [201 202], got 200", "$.path: expected integer, got string", "expected to exist"} {
+		if failures[i].Message != expected {
+			t.Errorf("failure %s returned %q, expected %q", failures[i].Name, failures[i].Message, expected)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = report.WriteJUnit(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitTestSuites
+	if err = xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("WriteJUnit() wrote invalid xml: %v\n%s", err, buf.String())
+	}
+
+	if suites.Tests != 2 || suites.Failures != 1 || len(suites.Suites) != 1 || suites.Suites[0].TestCases[1].Failure == nil {
+		t.Errorf("WriteJUnit() wrote %s", buf.String())
+	}
+}
+
+func TestElevator_ValidateExpect(t *testing.T) {
+	el, err := Load(WithBytes([]byte(`
+flat:
+  rooms:
+    todoRoom:
+      connection:
+        baseUrl: "https://dummyjson.com"
+      requests:
+        getTodo:
+          path: "todos/1"
+          expect:
+            status: 1000
+            jsonSchema:
+              type: text
+              properties:
+                id:
+                  oneOf: [{type: integer}, {type: string}]
+                  format: uuid
+            jsonPath:
+              "$.[": exists
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = el.Validate()
+
+	for _, expected := range []string{"expect.status 1000", `expect.jsonSchema.type "text"`, "expect.jsonPath",
+		"expect.jsonSchema.properties.id.format is not supported", "expect.jsonSchema.properties.id.oneOf is not supported"} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Validate() returned %v, expected it to contain %q", err, expected)
+		}
+	}
+}
+
+func TestExpect_CheckInvalidJson(t *testing.T) {
+	// yaml decodes .inf to an infinite float, which has no json representation
+	expect := Expect{
+		JSONSchema: map[string]any{"type": "object", "maximum": math.Inf(1)},
+		JSONPath:   map[string]any{"$.id": math.Inf(1)},
+	}
+
+	results := expect.Check(room.Response{Data: []byte(`{"id": 1}`)})
+
+	if len(results) != 2 {
+		t.Fatalf("Check() returned %+v, expected the schema and the json path assertion", results)
+	}
+
+	for _, result := range results {
+		if result.Passed || !strings.Contains(result.Message, "is not json") {
+			t.Errorf("Check() returned %+v, expected it to fail as not json", result)
+		}
+	}
+}
+
+func TestExpect_CheckUnsupportedSchema(t *testing.T) {
+	expect := Expect{JSONSchema: map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"id": map[string]any{"$ref": "#/definitions/id"}},
+	}}
+
+	results := expect.Check(room.Response{Data: []byte(`{"id": "anything"}`)})
+
+	if len(results) != 1 || results[0].Passed || !strings.Contains(results[0].Message, "jsonSchema.properties.id.$ref is not supported") {
+		t.Errorf("Check() returned %+v, expected the unsupported keyword to fail the schema", results)
+	}
+}
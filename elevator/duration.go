@@ -0,0 +1,38 @@
+package elevator
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"time"
+)
+
+// Duration is a duration like `250ms` or `1.5s` in the yml, a plain number is read as seconds like the timeouts
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var seconds float64
+
+	if value.Tag == "!!int" || value.Tag == "!!float" {
+		if err := value.Decode(&seconds); err != nil {
+			return err
+		}
+
+		*d = Duration(seconds * float64(time.Second))
+
+		return nil
+	}
+
+	duration, err := time.ParseDuration(value.Value)
+
+	if err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+
+	*d = Duration(duration)
+
+	return nil
+}
+
+func (d Duration) MarshalYAML() (any, error) {
+	return time.Duration(d).String(), nil
+}
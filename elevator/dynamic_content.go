@@ -0,0 +1,299 @@
+package elevator
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DynamicTypeString  = "string"
+	DynamicTypeBoolean = "boolean"
+	DynamicTypeInteger = "integer"
+	DynamicTypeNumber  = "number"
+	DynamicTypeObject  = "object"
+	DynamicTypeArray   = "array"
+
+	DynamicFormatEmail    = "email"
+	DynamicFormatUUID     = "uuid"
+	DynamicFormatDateTime = "date-time"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// FieldError describes why a single dynamic content field is invalid
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError collects all field errors of a dynamic execution payload
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+
+	for i, fieldError := range e.Errors {
+		messages[i] = fieldError.Error()
+	}
+
+	return "dynamic content validation failed: " + strings.Join(messages, "; ")
+}
+
+func validationFieldErrors(err error) []FieldError {
+	if validationError, ok := err.(ValidationError); ok {
+		return validationError.Errors
+	}
+
+	return []FieldError{{Field: "", Message: err.Error()}}
+}
+
+// IsRequired reports whether the field must be present in the payload,
+// fields are required unless they are marked as not required or have a default value
+func (d DynamicContent) IsRequired() bool {
+	if d.Required != nil {
+		return *d.Required
+	}
+
+	return d.Default == nil
+}
+
+// resolveDynamicContents builds the request payload from the declared dynamic contents,
+// values are coerced to their declared types and every invalid field is reported
+func resolveDynamicContents(dynamicContents []DynamicContent, v map[string]any) (map[string]any, error) {
+	var errs []FieldError
+
+	requestPayload := resolveObject("", dynamicContents, v, &errs)
+
+	if len(errs) > 0 {
+		return nil, ValidationError{Errors: errs}
+	}
+
+	return requestPayload, nil
+}
+
+func resolveObject(path string, dynamicContents []DynamicContent, v map[string]any, errs *[]FieldError) map[string]any {
+	requestPayload := map[string]any{}
+
+	for _, dynamicContent := range dynamicContents {
+		fieldPath := joinFieldPath(path, dynamicContent.Key)
+
+		if dynamicContent.Value != nil {
+			requestPayload[dynamicContent.Key] = dynamicContent.Value
+			continue
+		}
+
+		value, ok := v[dynamicContent.Key]
+
+		if !ok || value == nil {
+			if dynamicContent.Default != nil {
+				value = dynamicContent.Default
+			} else if dynamicContent.IsRequired() {
+				*errs = append(*errs, FieldError{fieldPath, "is required"})
+				continue
+			} else {
+				continue
+			}
+		}
+
+		if resolved, ok := resolveValue(fieldPath, dynamicContent, value, errs); ok {
+			requestPayload[dynamicContent.Key] = resolved
+		}
+	}
+
+	return requestPayload
+}
+
+func resolveValue(path string, dynamicContent DynamicContent, value any, errs *[]FieldError) (any, bool) {
+	resolved, err := coerce(dynamicContent.Type, value)
+
+	if err != nil {
+		*errs = append(*errs, FieldError{path, err.Error()})
+		return nil, false
+	}
+
+	switch dynamicContent.Type {
+	case DynamicTypeObject:
+		if len(dynamicContent.Properties) > 0 {
+			resolved = resolveObject(path, dynamicContent.Properties, resolved.(map[string]any), errs)
+		}
+	case DynamicTypeArray:
+		if dynamicContent.Items != nil {
+			items := resolved.([]any)
+			resolvedItems := make([]any, 0, len(items))
+
+			for i, item := range items {
+				if resolvedItem, ok := resolveValue(fmt.Sprintf("%s[%d]", path, i), *dynamicContent.Items, item, errs); ok {
+					resolvedItems = append(resolvedItems, resolvedItem)
+				}
+			}
+
+			resolved = resolvedItems
+		}
+	}
+
+	if len(dynamicContent.Enum) > 0 && !inEnum(dynamicContent.Enum, resolved) {
+		*errs = append(*errs, FieldError{path, fmt.Sprintf("must be one of %v", dynamicContent.Enum)})
+		return nil, false
+	}
+
+	if dynamicContent.Format != "" {
+		if err = checkFormat(dynamicContent.Format, resolved); err != nil {
+			*errs = append(*errs, FieldError{path, err.Error()})
+			return nil, false
+		}
+	}
+
+	return resolved, true
+}
+
+// coerce converts the value to the declared type, an empty type keeps the value as it is
+func coerce(dynamicType string, value any) (any, error) {
+	val := reflect.ValueOf(value)
+
+	switch dynamicType {
+	case "":
+		return value, nil
+	case DynamicTypeString:
+		if marshaler, ok := value.(encoding.TextMarshaler); ok {
+			if text, err := marshaler.MarshalText(); err == nil {
+				return string(text), nil
+			}
+		}
+
+		switch val.Kind() {
+		case reflect.String:
+			return val.String(), nil
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+			return fmt.Sprint(value), nil
+		}
+	case DynamicTypeBoolean:
+		switch val.Kind() {
+		case reflect.Bool:
+			return val.Bool(), nil
+		case reflect.String:
+			if b, err := strconv.ParseBool(val.String()); err == nil {
+				return b, nil
+			}
+		}
+	case DynamicTypeInteger:
+		switch val.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return val.Int(), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if val.Uint() <= math.MaxInt64 {
+				return int64(val.Uint()), nil
+			}
+		case reflect.Float32, reflect.Float64:
+			if f := val.Float(); f == math.Trunc(f) {
+				return int64(f), nil
+			}
+		case reflect.String:
+			if i, err := strconv.ParseInt(val.String(), 10, 64); err == nil {
+				return i, nil
+			}
+		}
+	case DynamicTypeNumber:
+		switch val.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(val.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(val.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			return val.Float(), nil
+		case reflect.String:
+			if f, err := strconv.ParseFloat(val.String(), 64); err == nil {
+				return f, nil
+			}
+		}
+	case DynamicTypeObject:
+		switch val.Kind() {
+		case reflect.Map:
+			if val.Type().Key().Kind() == reflect.String {
+				m := make(map[string]any, val.Len())
+
+				iter := val.MapRange()
+				for iter.Next() {
+					m[iter.Key().String()] = iter.Value().Interface()
+				}
+
+				return m, nil
+			}
+		case reflect.Struct, reflect.Pointer:
+			return NewDynamicExecutionPayload(value).Fields, nil
+		}
+	case DynamicTypeArray:
+		switch val.Kind() {
+		case reflect.Slice, reflect.Array:
+			items := make([]any, val.Len())
+
+			for i := 0; i < val.Len(); i++ {
+				items[i] = fieldValue(val.Index(i))
+			}
+
+			return items, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %s", dynamicType)
+	}
+
+	return nil, fmt.Errorf("can not convert %T to %s", value, dynamicType)
+}
+
+func inEnum(enum []any, value any) bool {
+	for _, option := range enum {
+		if fmt.Sprint(option) == fmt.Sprint(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkFormat(format string, value any) error {
+	s, ok := value.(string)
+
+	if !ok {
+		return fmt.Errorf("format %s requires a string value", format)
+	}
+
+	switch format {
+	case DynamicFormatEmail:
+		if address, err := mail.ParseAddress(s); err != nil || address.Address != s {
+			return fmt.Errorf("%q is not a valid email", s)
+		}
+	case DynamicFormatUUID:
+		if !uuidPattern.MatchString(s) {
+			return fmt.Errorf("%q is not a valid uuid", s)
+		}
+	case DynamicFormatDateTime:
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return fmt.Errorf("%q is not a valid date-time", s)
+		}
+	default:
+		return fmt.Errorf("unknown format %s", format)
+	}
+
+	return nil
+}
+
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,162 @@
+package elevator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResolveDynamicContents_Coercion(t *testing.T) {
+	notRequired := false
+
+	dynamicContents := []DynamicContent{
+		{Key: "todo", Type: DynamicTypeString},
+		{Key: "completed", Type: DynamicTypeBoolean},
+		{Key: "userId", Type: DynamicTypeString},
+		{Key: "priority", Type: DynamicTypeInteger, Default: 3},
+		{Key: "note", Type: DynamicTypeString, Required: &notRequired},
+		{Key: "tags", Type: DynamicTypeArray, Items: &DynamicContent{Type: DynamicTypeString}},
+		{Key: "owner", Type: DynamicTypeObject, Properties: []DynamicContent{
+			{Key: "email", Type: DynamicTypeString, Format: DynamicFormatEmail},
+		}},
+	}
+
+	payload, err := resolveDynamicContents(dynamicContents, map[string]any{
+		"todo":      "lorem",
+		"completed": "true",
+		"userId":    1,
+		"tags":      []int{1, 2},
+		"owner":     map[string]any{"email": "john@example.com", "ignored": true},
+	})
+
+	if err != nil {
+		t.Fatalf("resolveDynamicContents() returned error: %v", err)
+	}
+
+	expected := map[string]any{
+		"todo":      "lorem",
+		"completed": true,
+		"userId":    "1",
+		"priority":  int64(3),
+		"tags":      []any{"1", "2"},
+		"owner":     map[string]any{"email": "john@example.com"},
+	}
+
+	if !reflect.DeepEqual(payload, expected) {
+		t.Errorf("resolveDynamicContents() returned %+v, expected %+v", payload, expected)
+	}
+}
+
+func TestResolveDynamicContents_StructPayload(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+
+	type payload struct {
+		Name    string  `json:"name"`
+		Address Address `json:"address"`
+	}
+
+	dynamicContents := []DynamicContent{
+		{Key: "name", Type: DynamicTypeString},
+		{Key: "address", Type: DynamicTypeObject, Properties: []DynamicContent{
+			{Key: "city", Type: DynamicTypeString},
+		}},
+	}
+
+	resolved, err := resolveDynamicContents(dynamicContents, NewDynamicExecutionPayload(payload{
+		Name:    "john",
+		Address: Address{City: "istanbul"},
+	}).Fields)
+
+	if err != nil {
+		t.Fatalf("resolveDynamicContents() returned error: %v", err)
+	}
+
+	expected := map[string]any{"name": "john", "address": map[string]any{"city": "istanbul"}}
+
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("resolveDynamicContents() returned %+v, expected %+v", resolved, expected)
+	}
+}
+
+func TestResolveDynamicContents_ValidationErrors(t *testing.T) {
+	dynamicContents := []DynamicContent{
+		{Key: "todo", Type: DynamicTypeString},
+		{Key: "completed", Type: DynamicTypeBoolean},
+		{Key: "status", Type: DynamicTypeString, Enum: []any{"open", "done"}},
+		{Key: "id", Type: DynamicTypeString, Format: DynamicFormatUUID},
+		{Key: "dueAt", Type: DynamicTypeString, Format: DynamicFormatDateTime},
+		{Key: "items", Type: DynamicTypeArray, Items: &DynamicContent{Type: DynamicTypeInteger}},
+	}
+
+	_, err := resolveDynamicContents(dynamicContents, map[string]any{
+		"completed": "maybe",
+		"status":    "closed",
+		"id":        "not-a-uuid",
+		"dueAt":     "yesterday",
+		"items":     []any{1, "two"},
+	})
+
+	var validationError ValidationError
+	if !errors.As(err, &validationError) {
+		t.Fatalf("resolveDynamicContents() returned %v, expected a ValidationError", err)
+	}
+
+	fields := map[string]bool{}
+	for _, fieldError := range validationError.Errors {
+		fields[fieldError.Field] = true
+	}
+
+	for _, field := range []string{"todo", "completed", "status", "id", "dueAt", "items[1]"} {
+		if !fields[field] {
+			t.Errorf("resolveDynamicContents() did not report an error for %s, got %v", field, validationError.Errors)
+		}
+	}
+}
+
+func TestNewDynamicExecutionPayload_Struct(t *testing.T) {
+	type Audit struct {
+		CreatedBy string `json:"createdBy"`
+	}
+
+	type Owner struct {
+		Name string `json:"name"`
+	}
+
+	type payload struct {
+		Audit
+		Todo      string    `json:"todo"`
+		Secret    string    `json:"-"`
+		Note      string    `json:"note,omitempty"`
+		Owner     Owner     `json:"owner"`
+		DueAt     time.Time `json:"dueAt"`
+		Untagged  int
+		unexposed string
+	}
+
+	dueAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	fields := NewDynamicExecutionPayload(&payload{
+		Audit:     Audit{CreatedBy: "admin"},
+		Todo:      "lorem",
+		Secret:    "secret",
+		Owner:     Owner{Name: "john"},
+		DueAt:     dueAt,
+		Untagged:  1,
+		unexposed: "hidden",
+	}).Fields
+
+	expected := map[string]any{
+		"createdBy": "admin",
+		"todo":      "lorem",
+		"owner":     Owner{Name: "john"},
+		"dueAt":     dueAt,
+		"Untagged":  1,
+	}
+
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("NewDynamicExecutionPayload() returned %+v, expected %+v", fields, expected)
+	}
+}
@@ -1,6 +1,8 @@
 package elevator
 
 import (
+	"context"
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,9 +10,13 @@ import (
 	"github.com/WEG-Technology/room/segment"
 	"github.com/WEG-Technology/room/store"
 	"gopkg.in/yaml.v3"
+	"log/slog"
+	"maps"
+	"net/http"
 	"os"
 	"reflect"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 )
@@ -44,49 +50,355 @@ type IElevatorEngine interface {
 	Request(roomKey, requestKey string) (*room.Request, error)
 }
 
+// IOptionExecutor executes the requests with per call request options, generated clients depend on it
+type IOptionExecutor interface {
+	ExecuteWith(roomKey, requestKey string, opts ...room.OptionRequest) (room.Response, error)
+	DynamicExecuteWith(roomKey, requestKey string, v any, opts ...room.OptionRequest) (room.Response, error)
+	ExecuteConcurrent(concurrentKey string, appliedRooms ...string) map[string]room.Response
+}
+
 type ElevatorEngine struct {
-	elevator       Elevator
-	Segment        segment.ISegment
-	RoomContainers map[string]RoomContainer
+	elevator         Elevator
+	Segment          segment.ISegment
+	RoomContainers   map[string]RoomContainer
+	connectorOptions []room.OptionConnector
+	baseUrls         map[string]string
+	circuitHooks     []CircuitStateHook
+	eventHandlers    map[string][]eventHandler
+	overrides        map[[2]string][]room.OptionRequest
+	mu               sync.RWMutex
+}
+
+type OptionEngine func(engine *ElevatorEngine)
+
+// WithConnectorOptions applies the options to the connectors of all rooms, like middlewares or recorders
+func WithConnectorOptions(opts ...room.OptionConnector) OptionEngine {
+	return func(engine *ElevatorEngine) {
+		engine.connectorOptions = append(engine.connectorOptions, opts...)
+	}
 }
 
+// WithBaseUrl overrides the baseUrl of the room, like pointing it to a mock server in tests
+func WithBaseUrl(roomKey, baseUrl string) OptionEngine {
+	return func(engine *ElevatorEngine) {
+		if engine.baseUrls == nil {
+			engine.baseUrls = map[string]string{}
+		}
+
+		engine.baseUrls[roomKey] = baseUrl
+	}
+}
+
+// CircuitStateHook is called when the circuit breaker of a room changes its state
+type CircuitStateHook func(roomKey string, from, to room.CircuitState)
+
+// WithCircuitStateHook registers hooks for the circuit breakers configured in the connections of the rooms
+func WithCircuitStateHook(hooks ...CircuitStateHook) OptionEngine {
+	return func(engine *ElevatorEngine) {
+		engine.circuitHooks = append(engine.circuitHooks, hooks...)
+	}
+}
+
+// GetElapsedTime returns the elapsed time of the last ExecuteConcurrent call
 func (e *ElevatorEngine) GetElapsedTime() float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.Segment == nil {
+		return 0
+	}
+
 	return e.Segment.GetElapsedTime()
 }
 
+// RoomContainer keeps the room and its requests, requests are templates which are cloned on every execution
 type RoomContainer struct {
 	Room     room.IRoom
 	Requests map[string]*room.Request
 }
 
-func NewElevatorEngine(elevator Elevator) IElevatorEngine {
-	return &ElevatorEngine{
+// NewElevatorEngine creates the engine, WarmUp must be called before executing requests
+func NewElevatorEngine(elevator Elevator, opts ...OptionEngine) *ElevatorEngine {
+	e := &ElevatorEngine{
 		elevator: elevator,
 	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// entry is the room, the request template and their config taken at once,
+// so a reload in between can not pair a template with the config of another version
+type entry struct {
+	room       room.IRoom
+	template   *room.Request
+	request    Request
+	connection Connection
+}
+
+// lookup returns the entry of the keys, keys which are not configured, like the ones removed by a reload, are an error
+func (e *ElevatorEngine) lookup(roomKey, requestKey string) (entry, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	roomContainerEntry, ok := e.RoomContainers[roomKey]
+	configRoom, configured := e.elevator.Config.Flat.Rooms[roomKey]
+
+	if !ok || !configured {
+		return entry{}, fmt.Errorf("engine for %s not configured", roomKey)
+	}
+
+	requestEntry, ok := roomContainerEntry.Requests[requestKey]
+	configRequest, configured := configRoom.Requests[requestKey]
+
+	if !ok || !configured {
+		return entry{}, fmt.Errorf("engine for %s on %s not configured", roomKey, requestKey)
+	}
+
+	return entry{room: roomContainerEntry.Room, template: requestEntry, request: configRequest, connection: configRoom.Connection}, nil
 }
 
 func (e *ElevatorEngine) Execute(roomKey, requestKey string) (room.Response, error) {
-	if roomContainerEntry, ok := e.RoomContainers[roomKey]; ok {
-		if requestEntry, ok := roomContainerEntry.Requests[requestKey]; ok {
-			return roomContainerEntry.Room.Send(requestEntry)
-		}
-		panic(fmt.Sprintf("engine for %s on %s not configured", roomKey, requestKey))
+	return e.ExecuteWith(roomKey, requestKey)
+}
+
+// ExecuteWith sends a copy of the configured request with the given options applied,
+// options only affect this call so it is safe to use concurrently with different payloads.
+// A header given by room.WithHeader is merged over the headers of the yml.
+func (e *ElevatorEngine) ExecuteWith(roomKey, requestKey string, opts ...room.OptionRequest) (room.Response, error) {
+	return e.ExecuteContext(context.Background(), roomKey, requestKey, opts...)
+}
+
+// ExecuteContext is ExecuteWith cancelled by the context, including the polling of a job
+func (e *ElevatorEngine) ExecuteContext(ctx context.Context, roomKey, requestKey string, opts ...room.OptionRequest) (room.Response, error) {
+	found, err := e.lookup(roomKey, requestKey)
+
+	if err != nil {
+		return room.Response{}, err
 	}
 
-	panic(fmt.Sprintf("engine for %s not configured", roomKey))
+	roomEntry, elevatorRequest := found.room, found.request
+
+	request := found.template.Clone()
+
+	room.WithLabels(map[string]string{"room": roomKey, "request": requestKey})(request)
+	room.WithParentContext(ctx)(request)
+
+	header := request.Header
+
+	for _, opt := range opts {
+		opt(request)
+	}
+
+	if header != nil && request.Header != header {
+		request.Header = room.NewHeader().Merge(header).Merge(request.Header)
+	}
+
+	if elevatorRequest.Stream != "" {
+		return room.Response{}, fmt.Errorf("%s.%s is a stream, it is opened by Stream", roomKey, requestKey)
+	}
+
+	if found.connection.Protocol == ProtocolWebSocket {
+		return room.Response{}, fmt.Errorf("%s is a websocket room, its requests are opened by WebSocket", roomKey)
+	}
+
+	// the errors of graphql and jsonrpc responses and soap faults are returned even with status 200
+	switch elevatorRequest.Body.Type {
+	case BodyTypeGraphQL:
+		return room.SendGraphQL(room.DoerFunc(roomEntry.Send), request)
+	case BodyTypeJSONRPC:
+		return room.SendJSONRPC(room.DoerFunc(roomEntry.Send), request)
+	case BodyTypeSOAP:
+		return room.SendSOAP(room.DoerFunc(roomEntry.Send), request)
+	}
+
+	response, err := roomEntry.Send(request)
+
+	if elevatorRequest.Poll != nil && err == nil {
+		return elevatorRequest.Poll.await(ctx, roomEntry, response, roomKey, requestKey)
+	}
+
+	return response, err
 }
 
+// DynamicExecute validates the payload against the declared dynamic contents and sends it as the request body,
+// a ValidationError is returned without sending the request if any field is invalid
 func (e *ElevatorEngine) DynamicExecute(roomKey, requestKey string, v any) (room.Response, error) {
-	if roomContainerEntry, ok := e.RoomContainers[roomKey]; ok {
-		if requestEntry, ok := roomContainerEntry.Requests[requestKey]; ok {
-			elevatorRequest := e.elevator.GetRequest(roomKey, requestKey)
-			requestEntry.BodyParser = e.generateDynamicParser(elevatorRequest.Body.Type, elevatorRequest.Body.DynamicContent, NewDynamicExecutionPayload(v).Fields)
-			return roomContainerEntry.Room.Send(requestEntry)
+	return e.DynamicExecuteWith(roomKey, requestKey, v)
+}
+
+// DynamicExecuteWith is DynamicExecute with the given options applied after the dynamic body and query
+func (e *ElevatorEngine) DynamicExecuteWith(roomKey, requestKey string, v any, opts ...room.OptionRequest) (room.Response, error) {
+	found, err := e.lookup(roomKey, requestKey)
+
+	if err != nil {
+		return room.Response{}, err
+	}
+
+	elevatorRequest := found.request
+
+	fields := NewDynamicExecutionPayload(v).Fields
+
+	var dynamicOpts []room.OptionRequest
+	var errs []FieldError
+
+	if len(elevatorRequest.Body.DynamicContent) > 0 {
+		parser, err := e.generateDynamicParser(elevatorRequest.Body, fields)
+
+		if err != nil {
+			errs = append(errs, validationFieldErrors(err)...)
+		} else {
+			dynamicOpts = append(dynamicOpts, room.WithBody(parser))
 		}
-		panic(fmt.Sprintf("engine for %s on %s not configured", roomKey, requestKey))
 	}
 
-	panic(fmt.Sprintf("engine for %s not configured", roomKey))
+	if len(elevatorRequest.Query.DynamicContent) > 0 {
+		query, err := generateDynamicQuery(elevatorRequest.Query, fields)
+
+		if err != nil {
+			errs = append(errs, validationFieldErrors(err)...)
+		} else {
+			dynamicOpts = append(dynamicOpts, room.WithQuery(query))
+		}
+	}
+
+	if len(errs) > 0 {
+		return room.Response{}, ValidationError{Errors: errs}
+	}
+
+	return e.ExecuteWith(roomKey, requestKey, append(dynamicOpts, opts...)...)
+}
+
+// Pager walks the pages of a request which declares pagination, room.Items decodes the items of the pages
+//
+//	pager, err := engine.Pager("todoRoom", "listTodos")
+//	for todo, err := range room.Items[Todo](pager) {
+func (e *ElevatorEngine) Pager(roomKey, requestKey string, opts ...room.OptionRequest) (*room.Pager, error) {
+	found, err := e.lookup(roomKey, requestKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := found.request.Pagination
+
+	if pagination == nil {
+		return nil, fmt.Errorf("%s.%s does not declare pagination", roomKey, requestKey)
+	}
+
+	request := found.template.Clone()
+
+	room.WithLabels(map[string]string{"room": roomKey, "request": requestKey})(request)
+
+	for _, opt := range opts {
+		opt(request)
+	}
+
+	return room.NewPager(room.DoerFunc(found.room.Send), request, pagination.strategy(), pagination.options()...), nil
+}
+
+// EventHandler is called with the events of a stream request
+type EventHandler func(event room.Event)
+
+type eventHandler struct {
+	event   string
+	handler EventHandler
+}
+
+// OnEvent registers the handler for the events of the stream request, an empty event name matches all events
+func (e *ElevatorEngine) OnEvent(roomKey, requestKey, event string, handler EventHandler) *ElevatorEngine {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.eventHandlers == nil {
+		e.eventHandlers = map[string][]eventHandler{}
+	}
+
+	key := roomKey + "." + requestKey
+	e.eventHandlers[key] = append(e.eventHandlers[key], eventHandler{event: event, handler: handler})
+
+	return e
+}
+
+// Stream opens the stream of a request which declares `stream: sse` and calls the handlers registered by OnEvent
+// with its events. It blocks until the context ends, which returns nil, or the stream fails.
+func (e *ElevatorEngine) Stream(ctx context.Context, roomKey, requestKey string, opts ...room.OptionRequest) error {
+	found, err := e.lookup(roomKey, requestKey)
+
+	if err != nil {
+		return err
+	}
+
+	if found.request.Stream != StreamSSE {
+		return fmt.Errorf("%s.%s does not declare stream: %s", roomKey, requestKey, StreamSSE)
+	}
+
+	streamRoom, ok := found.room.(room.IStreamRoom)
+
+	if !ok {
+		return fmt.Errorf("room %s does not support streams", roomKey)
+	}
+
+	request := found.template.Clone()
+
+	room.WithLabels(map[string]string{"room": roomKey, "request": requestKey})(request)
+
+	for _, opt := range opts {
+		opt(request)
+	}
+
+	stream, err := streamRoom.SSE(ctx, request)
+
+	if err != nil {
+		return err
+	}
+
+	defer stream.Close()
+
+	for event := range stream.Events() {
+		e.mu.RLock()
+		handlers := e.eventHandlers[roomKey+"."+requestKey]
+		e.mu.RUnlock()
+
+		for _, h := range handlers {
+			if h.event == "" || h.event == event.Event {
+				h.handler(event)
+			}
+		}
+	}
+
+	return stream.Err()
+}
+
+// WebSocket opens the websocket of a request in a room which declares `protocol: websocket`,
+// the options are applied after the webSocket options of the connection
+func (e *ElevatorEngine) WebSocket(ctx context.Context, roomKey, requestKey string, opts ...room.OptionWebSocket) (*room.WebSocket, error) {
+	found, err := e.lookup(roomKey, requestKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	connection := found.connection
+
+	if connection.Protocol != ProtocolWebSocket {
+		return nil, fmt.Errorf("%s does not declare protocol: %s", roomKey, ProtocolWebSocket)
+	}
+
+	webSocketRoom, ok := found.room.(room.IWebSocketRoom)
+
+	if !ok {
+		return nil, fmt.Errorf("room %s does not support websockets", roomKey)
+	}
+
+	request := found.template.Clone()
+
+	room.WithLabels(map[string]string{"room": roomKey, "request": requestKey})(request)
+
+	return webSocketRoom.WebSocket(ctx, request, append(connection.WebSocket.options(), opts...)...)
 }
 
 type RoomResponseContainer struct {
@@ -100,9 +412,27 @@ func (c *RoomResponseContainer) PutResponse(key string, response room.Response)
 	c.Responses[key] = response
 }
 
+// ExecuteConcurrent executes the requests of the concurrent key, the segment of the call is kept as the engine's Segment
 func (e *ElevatorEngine) ExecuteConcurrent(concurrentKey string, appliedRooms ...string) map[string]room.Response {
-	e.Segment = segment.StartSegmentNow()
-	defer e.Segment.End()
+	responses, s := e.ExecuteConcurrentSegment(concurrentKey, appliedRooms...)
+
+	e.mu.Lock()
+	e.Segment = s
+	e.mu.Unlock()
+
+	return responses
+}
+
+// ExecuteConcurrentSegment executes the requests of the concurrent key and returns the segment of the whole batch,
+// every request has a child segment named `roomKey.requestKey` with the http timing of its response
+func (e *ElevatorEngine) ExecuteConcurrentSegment(concurrentKey string, appliedRooms ...string) (map[string]room.Response, segment.ISegment) {
+	return e.ExecuteConcurrentContext(context.Background(), concurrentKey, appliedRooms...)
+}
+
+// ExecuteConcurrentContext is ExecuteConcurrentSegment cancelled by the context
+func (e *ElevatorEngine) ExecuteConcurrentContext(ctx context.Context, concurrentKey string, appliedRooms ...string) (map[string]room.Response, segment.ISegment) {
+	batchSegment := segment.StartNamedSegmentNow(concurrentKey)
+	defer batchSegment.End()
 
 	responseContainer := RoomResponseContainer{
 		Responses: map[string]room.Response{},
@@ -110,13 +440,17 @@ func (e *ElevatorEngine) ExecuteConcurrent(concurrentKey string, appliedRooms ..
 
 	var wg sync.WaitGroup
 
-	for roomKey, configRoom := range e.elevator.Config.Flat.Rooms {
+	for roomKey, configRoom := range e.config().Config.Flat.Rooms {
 		for requestKey, req := range configRoom.Requests {
 			if ((len(appliedRooms) > 0 && slices.Contains(appliedRooms, roomKey)) || len(appliedRooms) == 0) && concurrentKey == req.ConcurrentKey {
 				wg.Add(1)
 				go func(a, b string) {
+					requestSegment := batchSegment.StartChild(a + "." + b)
+
 					//TODO handle errors
-					res, _ := e.Execute(a, b)
+					res, _ := e.ExecuteContext(ctx, a, b)
+
+					requestSegment.SetTiming(res.Timing).End()
 
 					responseContainer.PutResponse(a, res)
 					wg.Done()
@@ -127,19 +461,75 @@ func (e *ElevatorEngine) ExecuteConcurrent(concurrentKey string, appliedRooms ..
 
 	wg.Wait()
 
-	return responseContainer.Responses
+	return responseContainer.Responses, batchSegment
 }
 
 // TODO remove panics by a parameter
 func (e *ElevatorEngine) WarmUp() IElevatorEngine {
+	roomContainers := e.buildRoomContainers(e.config())
+
+	e.mu.Lock()
+	e.RoomContainers = roomContainers
+	e.mu.Unlock()
+
+	return e
+}
+
+// reload swaps the config and the room containers at once,
+// executions which are already in flight finish with the rooms they started with
+func (e *ElevatorEngine) reload(elevator Elevator) {
+	roomContainers := e.buildRoomContainers(elevator)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// the templates replaced by PutBodyParser and PutQuery keep their overrides, unless the request is removed
+	for key, opts := range e.overrides {
+		if request, ok := roomContainers[key[0]].Requests[key[1]]; ok {
+			for _, opt := range opts {
+				opt(request)
+			}
+		}
+	}
+
+	e.elevator = elevator
+	e.RoomContainers = roomContainers
+}
+
+func (e *ElevatorEngine) config() Elevator {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.elevator
+}
+
+func (e *ElevatorEngine) buildRoomContainers(elevator Elevator) map[string]RoomContainer {
 	roomContainers := map[string]RoomContainer{}
 
-	for roomKey, r := range e.elevator.Config.Flat.Rooms {
-		c := room.NewConnector(
-			r.Connection.BaseURL,
+	for roomKey, r := range elevator.Config.Flat.Rooms {
+		connectorOptions := []room.OptionConnector{
 			room.WithHeaderConnector(room.NewHeader(store.NewMapStore(r.Connection.Headers))),
-			room.WithHeaderContextBuilder(room.NewContextBuilder(time.Duration(r.Connection.Timeout)*time.Second)),
-		)
+			room.WithHeaderContextBuilder(room.NewContextBuilder(time.Duration(r.Connection.Timeout) * time.Second)),
+		}
+
+		if r.Connection.Logging.Enabled {
+			connectorOptions = append(connectorOptions, r.Connection.Logging.connectorOption())
+		}
+
+		// the breakers are rebuilt on reload, so a changed config starts with a closed circuit
+		if r.Connection.CircuitBreaker.enabled() {
+			connectorOptions = append(connectorOptions, r.Connection.CircuitBreaker.connectorOption(roomKey, e.circuitHooks))
+		}
+
+		connectorOptions = append(connectorOptions, r.Connection.Compression.connectorOptions()...)
+		connectorOptions = append(connectorOptions, e.connectorOptions...)
+
+		baseUrl := r.Connection.BaseURL
+		if override, ok := e.baseUrls[roomKey]; ok {
+			baseUrl = override
+		}
+
+		c := room.NewConnector(baseUrl, connectorOptions...)
 
 		var roomObj room.IRoom
 
@@ -159,19 +549,68 @@ func (e *ElevatorEngine) WarmUp() IElevatorEngine {
 		}
 	}
 
-	e.RoomContainers = roomContainers
-
-	return e
+	return roomContainers
 }
 
 func (e *ElevatorEngine) CreateRequest(req Request) *room.Request {
-	parser := e.initParser(req.Body.Type, req.Body.Content)
+	parser := e.initParser(req.Body, req.Body.Content)
+
+	method := room.HTTPMethod(req.Method)
+	if method == "" && req.Body.isEnvelope() {
+		method = room.POST
+	}
 
 	optionRequests := []room.OptionRequest{
-		room.WithMethod(room.HTTPMethod(req.Method)),
+		room.WithMethod(method),
 		room.WithBody(parser),
 	}
 
+	if len(req.Headers) > 0 || req.Accept != "" {
+		header := room.NewHeader()
+
+		// yml values like `X-Api-Version: 2` are not strings
+		for key, value := range req.Headers {
+			header.Add(key, fmt.Sprint(value))
+		}
+
+		if req.Accept != "" {
+			header.Add("Accept", req.Accept)
+		}
+
+		optionRequests = append(optionRequests, room.WithHeader(header))
+	}
+
+	if len(req.Query.Content) > 0 {
+		optionRequests = append(optionRequests, room.WithQuery(newQuery(req.Query.Content)))
+	}
+
+	if req.Timeout > 0 {
+		optionRequests = append(optionRequests, room.WithContextBuilder(room.NewContextBuilder(time.Duration(req.Timeout)*time.Second)))
+	}
+
+	if len(req.Cookies) > 0 {
+		cookies := make([]*http.Cookie, 0, len(req.Cookies))
+
+		for name, value := range req.Cookies {
+			cookies = append(cookies, &http.Cookie{Name: name, Value: value})
+		}
+
+		optionRequests = append(optionRequests, room.WithCookies(cookies...))
+	}
+
+	if len(req.ExpectedStatus) > 0 {
+		optionRequests = append(optionRequests, room.WithExpectedStatus(req.ExpectedStatus...))
+	}
+
+	// the hedging is created with the request template, so its latencies are shared by the executions
+	if req.Hedge != nil {
+		optionRequests = append(optionRequests, req.Hedge.requestOption())
+	}
+
+	if req.AttemptTimeout > 0 {
+		optionRequests = append(optionRequests, room.WithAttemptTimeout(time.Duration(req.AttemptTimeout)))
+	}
+
 	r := room.NewRequest(
 		req.Path,
 		optionRequests...,
@@ -180,16 +619,22 @@ func (e *ElevatorEngine) CreateRequest(req Request) *room.Request {
 	return r
 }
 
-func (e *ElevatorEngine) initParser(bodyType string, content any) room.IBodyParser {
+func (e *ElevatorEngine) initParser(body Body, content any) room.IBodyParser {
 	var parser room.IBodyParser
 	//TODO expand here
-	switch bodyType {
+	switch body.Type {
 	case "json":
 		parser = room.NewJsonBodyParser(content)
 	case "form":
 		parser = room.NewFormURLEncodedBodyParser(content)
 	case "multipart-form":
 		parser = room.NewMultipartFormDataBodyParser(content)
+	case BodyTypeGraphQL:
+		parser = body.graphQLParser(content)
+	case BodyTypeJSONRPC:
+		parser = room.NewJSONRPCBodyParser(body.Method, content)
+	case BodyTypeSOAP:
+		parser = body.soapParser(content)
 	default:
 		parser = nil
 	}
@@ -198,42 +643,103 @@ func (e *ElevatorEngine) initParser(bodyType string, content any) room.IBodyPars
 }
 
 // TODO should be refactored in v2 for use dynamics as `content` instead of `dynamicContent`
-func (e *ElevatorEngine) generateDynamicParser(bodyType string, dynamicContents []DynamicContent, v map[string]any) room.IBodyParser {
-	requestPayload := map[string]any{}
+func (e *ElevatorEngine) generateDynamicParser(body Body, v map[string]any) (room.IBodyParser, error) {
+	requestPayload, err := resolveDynamicContents(body.DynamicContent, v)
 
-	for _, dynamicContent := range dynamicContents {
+	if err != nil {
+		return nil, err
+	}
 
-		if dynamicContent.Value != nil {
-			requestPayload[dynamicContent.Key] = dynamicContent.Value
-			continue
-		}
+	if body.Type == BodyTypeJSONRPC && body.PositionalParams {
+		return e.initParser(body, body.dynamicParams(requestPayload)), nil
+	}
 
-		if _, ok := v[dynamicContent.Key]; !ok {
-			panic(fmt.Sprintf("dynamic content key %s not found in payload", dynamicContent.Key))
-		}
+	// the dynamic variables and params are merged over the static ones
+	if content, ok := body.Content.(map[string]any); ok && body.isEnvelope() {
+		variables := maps.Clone(content)
+		maps.Copy(variables, requestPayload)
+		requestPayload = variables
+	}
+
+	return e.initParser(body, requestPayload), nil
+}
+
+// generateDynamicQuery merges the resolved dynamic contents over the static query content
+func generateDynamicQuery(query Query, v map[string]any) (room.IQuery, error) {
+	var errs []FieldError
+
+	dynamicValues := resolveObject("query", query.DynamicContent, v, &errs)
 
-		requestPayload[dynamicContent.Key] = v[dynamicContent.Key]
+	if len(errs) > 0 {
+		return nil, ValidationError{Errors: errs}
 	}
 
-	return e.initParser(bodyType, requestPayload)
+	values := map[string]any{}
+
+	for key, value := range query.Content {
+		values[key] = value
+	}
+
+	for key, value := range dynamicValues {
+		values[key] = value
+	}
+
+	return newQuery(values), nil
 }
 
-func (e *ElevatorEngine) PutBodyParser(roomKey, requestKey string, bodyParser room.IBodyParser) IElevatorEngine {
-	if roomContainerEntry, ok := e.RoomContainers[roomKey]; ok {
-		if requestEntry, ok := roomContainerEntry.Requests[requestKey]; ok {
-			requestEntry.BodyParser = bodyParser
-			return e
+// newQuery creates a query of string values, lists are sent as comma separated values
+func newQuery(values map[string]any) room.IQuery {
+	m := store.NewMapStore()
+
+	for key, value := range values {
+		if list, ok := value.([]any); ok {
+			items := make([]string, len(list))
+
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+
+			m.Add(key, strings.Join(items, ","))
+		} else {
+			m.Add(key, fmt.Sprint(value))
 		}
-		panic(fmt.Sprintf("engine for %s on %s not configured", roomKey, requestKey))
 	}
 
-	panic(fmt.Sprintf("engine for %s not configured", roomKey))
+	return room.NewQuery(m)
+}
+
+// PutBodyParser replaces the default body parser of the request template,
+// use ExecuteWith with room.WithBody for a body that only applies to a single call
+func (e *ElevatorEngine) PutBodyParser(roomKey, requestKey string, bodyParser room.IBodyParser) IElevatorEngine {
+	return e.putOption(roomKey, requestKey, room.WithBody(bodyParser))
 }
 
+// PutQuery replaces the default query of the request template,
+// use ExecuteWith with room.WithQuery for a query that only applies to a single call
 func (e *ElevatorEngine) PutQuery(roomKey, requestKey string, query room.IQuery) IElevatorEngine {
+	return e.putOption(roomKey, requestKey, room.WithQuery(query))
+}
+
+// putOption swaps the request template with a copy that has the option applied,
+// requests which are already in flight keep using the previous template.
+// The option is kept and applied again to the templates rebuilt by a reload.
+func (e *ElevatorEngine) putOption(roomKey, requestKey string, opt room.OptionRequest) IElevatorEngine {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	if roomContainerEntry, ok := e.RoomContainers[roomKey]; ok {
 		if requestEntry, ok := roomContainerEntry.Requests[requestKey]; ok {
-			requestEntry.Query = query
+			request := requestEntry.Clone()
+			opt(request)
+			roomContainerEntry.Requests[requestKey] = request
+
+			if e.overrides == nil {
+				e.overrides = map[[2]string][]room.OptionRequest{}
+			}
+
+			key := [2]string{roomKey, requestKey}
+			e.overrides[key] = append(e.overrides[key], opt)
+
 			return e
 		}
 		panic(fmt.Sprintf("engine for %s on %s not configured", roomKey, requestKey))
@@ -242,10 +748,14 @@ func (e *ElevatorEngine) PutQuery(roomKey, requestKey string, query room.IQuery)
 	panic(fmt.Sprintf("engine for %s not configured", roomKey))
 }
 
+// Request returns a copy of the request template, changes on it do not affect the engine
 func (e *ElevatorEngine) Request(roomKey, requestKey string) (*room.Request, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	if roomContainerEntry, ok := e.RoomContainers[roomKey]; ok {
 		if requestEntry, ok := roomContainerEntry.Requests[requestKey]; ok {
-			return requestEntry, nil
+			return requestEntry.Clone(), nil
 		}
 		return nil, errors.New("request not found")
 	}
@@ -254,23 +764,17 @@ func (e *ElevatorEngine) Request(roomKey, requestKey string) (*room.Request, err
 }
 
 func NewElevator(integrationYmlPath string) Elevator {
-	ymlFile, err := readYml(integrationYmlPath)
+	elevator, err := loadElevator(integrationYmlPath)
 
 	if err != nil {
 		panic(err)
 	}
 
-	ymlContent := injectEnv(ymlFile)
-
-	config, err := unmarshalYmlContent(ymlContent)
-
-	if err != nil {
-		panic(err)
-	}
+	return elevator
+}
 
-	return Elevator{
-		config,
-	}
+func loadElevator(integrationYmlPath string) (Elevator, error) {
+	return Load(WithFiles(integrationYmlPath))
 }
 
 type IElevator interface {
@@ -281,6 +785,12 @@ type IElevator interface {
 
 type Elevator struct {
 	Config IntegrationConfig
+	paths  []string
+}
+
+// Paths returns the files the config is loaded from
+func (e Elevator) Paths() []string {
+	return e.paths
 }
 
 func (e Elevator) GetRequest(roomKey, requestKey string) Request {
@@ -320,48 +830,441 @@ func NewBody(contentType string, content any) Body {
 	}
 }
 
+// Body declares the body of a request. The content and the dynamic contents of a graphql body are its variables,
+// its query is given inline or by a queryFile which Load reads relative to the file declaring it.
+// The content and the dynamic contents of a jsonrpc body are the params of its method,
+// the dynamic contents are sent as an array in their declared order with positionalParams.
+// The content and the dynamic contents of a soap body are the params of its operation, wrapped in a SOAP envelope.
 type Body struct {
-	Type           string           `yaml:"type"`
-	Content        any              `yaml:"content"`
-	DynamicContent []DynamicContent `yaml:"dynamicContent"`
+	Type           string           `yaml:"type,omitempty"`
+	Content        any              `yaml:"content,omitempty"`
+	DynamicContent []DynamicContent `yaml:"dynamicContent,omitempty"`
+
+	Query              string `yaml:"query,omitempty"`
+	QueryFile          string `yaml:"queryFile,omitempty"`
+	OperationName      string `yaml:"operationName,omitempty"`
+	PersistedQuery     bool   `yaml:"persistedQuery,omitempty"`
+	PersistedQueryHash string `yaml:"persistedQueryHash,omitempty"`
+
+	Method           string `yaml:"method,omitempty"`
+	PositionalParams bool   `yaml:"positionalParams,omitempty"`
+
+	Operation   string         `yaml:"operation,omitempty"`
+	Namespace   string         `yaml:"namespace,omitempty"`
+	SOAPAction  string         `yaml:"soapAction,omitempty"`
+	SOAPVersion string         `yaml:"soapVersion,omitempty"`
+	Security    *UsernameToken `yaml:"security,omitempty"`
+}
+
+// UsernameToken is the WS-Security header of a soap body, the password is usually given by an environment variable
+type UsernameToken struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
 }
 
+const (
+	BodyTypeGraphQL = "graphql"
+	BodyTypeJSONRPC = "jsonrpc"
+	BodyTypeSOAP    = "soap"
+)
+
+// isEnvelope reports whether the content is wrapped in an envelope, these bodies are sent with POST
+// and their dynamic contents are merged over their static content
+func (b Body) isEnvelope() bool {
+	return b.Type == BodyTypeGraphQL || b.Type == BodyTypeJSONRPC || b.Type == BodyTypeSOAP
+}
+
+func (b Body) soapParser(params any) room.IBodyParser {
+	opts := []room.OptionSOAP{room.WithSOAPAction(b.SOAPAction)}
+
+	if b.SOAPVersion != "" {
+		opts = append(opts, room.WithSOAPVersion(room.SOAPVersion(b.SOAPVersion)))
+	}
+
+	if b.Security != nil {
+		opts = append(opts, room.WithUsernameToken(b.Security.Username, b.Security.Password))
+	}
+
+	operationParams, _ := params.(map[string]any)
+
+	return room.NewSOAPBodyParser(room.NewSOAPOperation(b.Operation, b.Namespace, operationParams), opts...)
+}
+
+func (b Body) graphQLParser(variables any) room.IBodyParser {
+	var opts []room.OptionGraphQL
+
+	if b.PersistedQuery || b.PersistedQueryHash != "" {
+		opts = append(opts, room.WithPersistedQuery(b.PersistedQueryHash))
+	}
+
+	return room.NewGraphQLBodyParser(b.Query, variables, b.OperationName, opts...)
+}
+
+// dynamicParams orders the resolved dynamic contents of a jsonrpc body with positional params,
+// they follow the static params of the content
+func (b Body) dynamicParams(resolved map[string]any) any {
+	if !b.PositionalParams {
+		return resolved
+	}
+
+	static, _ := b.Content.([]any)
+
+	params := make([]any, 0, len(static)+len(b.DynamicContent))
+	params = append(params, static...)
+
+	for _, dynamicContent := range b.DynamicContent {
+		params = append(params, resolved[dynamicContent.Key])
+	}
+
+	return params
+}
+
+// DynamicContent declares a body field which is filled from the execution payload,
+// a static Value skips the payload and is sent as it is
 type DynamicContent struct {
-	Key   string `yaml:"key"`
-	Value any    `yaml:"value"`
+	Key        string           `yaml:"key,omitempty"`
+	Value      any              `yaml:"value,omitempty"`
+	Type       string           `yaml:"type,omitempty"`
+	Required   *bool            `yaml:"required,omitempty"`
+	Default    any              `yaml:"default,omitempty"`
+	Enum       []any            `yaml:"enum,omitempty"`
+	Format     string           `yaml:"format,omitempty"`
+	Properties []DynamicContent `yaml:"properties,omitempty"`
+	Items      *DynamicContent  `yaml:"items,omitempty"`
 }
 
+const (
+	ProtocolHTTP      = "http"
+	ProtocolWebSocket = "websocket"
+)
+
 type Connection struct {
-	BaseURL string         `yaml:"baseUrl"`
-	Timeout int            `yaml:"timeout"`
-	Headers map[string]any `yaml:"headers"`
-	Auth    ConnectionAuth `yaml:"auth"`
+	BaseURL  string         `yaml:"baseUrl,omitempty"`
+	Protocol string         `yaml:"protocol,omitempty"`
+	Timeout  int            `yaml:"timeout,omitempty"`
+	Headers  map[string]any `yaml:"headers,omitempty"`
+	Auth     ConnectionAuth `yaml:"auth,omitempty"`
+	Logging  Logging        `yaml:"logging,omitempty"`
+
+	CircuitBreaker CircuitBreaker `yaml:"circuitBreaker,omitempty"`
+	WebSocket      WebSocket      `yaml:"webSocket,omitempty"`
+	Compression    Compression    `yaml:"compression,omitempty"`
+}
+
+// WebSocket configures the websockets of a room with `protocol: websocket`, see room.WebSocket for the defaults
+type WebSocket struct {
+	PingInterval      Duration `yaml:"pingInterval,omitempty"`
+	ReconnectDelay    Duration `yaml:"reconnectDelay,omitempty"`
+	MaxReconnectDelay Duration `yaml:"maxReconnectDelay,omitempty"`
+	ReconnectAttempts *int     `yaml:"reconnectAttempts,omitempty"`
+	MessageTypePath   string   `yaml:"messageTypePath,omitempty"`
+}
+
+func (w WebSocket) options() []room.OptionWebSocket {
+	var opts []room.OptionWebSocket
+
+	if w.PingInterval > 0 {
+		opts = append(opts, room.WithPingInterval(time.Duration(w.PingInterval)))
+	}
+
+	if w.ReconnectDelay > 0 {
+		maxDelay := w.MaxReconnectDelay
+		if maxDelay < w.ReconnectDelay {
+			maxDelay = w.ReconnectDelay
+		}
+
+		opts = append(opts, room.WithReconnectBackoff(time.Duration(w.ReconnectDelay), time.Duration(maxDelay)))
+	}
+
+	if w.ReconnectAttempts != nil {
+		opts = append(opts, room.WithReconnectAttempts(*w.ReconnectAttempts))
+	}
+
+	if w.MessageTypePath != "" {
+		opts = append(opts, room.WithMessageTypePath(w.MessageTypePath))
+	}
+
+	return opts
+}
+
+// Logging enables the request logs of a room, they are written by slog.Default
+type Logging struct {
+	Enabled         bool     `yaml:"enabled,omitempty"`
+	Headers         bool     `yaml:"headers,omitempty"`
+	Body            bool     `yaml:"body,omitempty"`
+	MaxBodySize     int      `yaml:"maxBodySize,omitempty"`
+	RedactedHeaders []string `yaml:"redactHeaders,omitempty"`
+	RedactedFields  []string `yaml:"redactFields,omitempty"`
+}
+
+func (l Logging) connectorOption() room.OptionConnector {
+	opts := []room.OptionLogging{
+		room.WithRedactedHeaders(l.RedactedHeaders...),
+		room.WithRedactedFields(l.RedactedFields...),
+	}
+
+	if l.Headers {
+		opts = append(opts, room.WithLogHeaders())
+	}
+
+	if l.Body {
+		opts = append(opts, room.WithLogBodies())
+	}
+
+	if l.MaxBodySize > 0 {
+		opts = append(opts, room.WithLogBodyLimit(l.MaxBodySize))
+	}
+
+	return room.WithLogging(slog.Default(), opts...)
+}
+
+// Compression compresses the request bodies of a room from threshold bytes with the encoding,
+// the responses are decoded by their Content-Encoding whether acceptEncoding advertises the encodings or not
+type Compression struct {
+	Encoding       string   `yaml:"encoding,omitempty"`
+	Threshold      int      `yaml:"threshold,omitempty"`
+	AcceptEncoding []string `yaml:"acceptEncoding,omitempty"`
+}
+
+func (c Compression) connectorOptions() []room.OptionConnector {
+	var opts []room.OptionConnector
+
+	if c.Encoding != "" {
+		opts = append(opts, room.WithRequestCompression(c.Encoding, c.Threshold))
+	}
+
+	if len(c.AcceptEncoding) > 0 {
+		opts = append(opts, room.WithAcceptEncoding(c.AcceptEncoding...))
+	}
+
+	return opts
+}
+
+// CircuitBreaker fails the requests of a room fast while its partner is down,
+// durations are in seconds like the timeout of the connection
+type CircuitBreaker struct {
+	ConsecutiveFailures int     `yaml:"consecutiveFailures,omitempty"`
+	FailureRate         float64 `yaml:"failureRate,omitempty"`
+	Window              int     `yaml:"window,omitempty"`
+	MinRequests         int     `yaml:"minRequests,omitempty"`
+	OpenTimeout         int     `yaml:"openTimeout,omitempty"`
+	HalfOpenRequests    int     `yaml:"halfOpenRequests,omitempty"`
+}
+
+func (c CircuitBreaker) enabled() bool {
+	return c.ConsecutiveFailures > 0 || c.FailureRate > 0
+}
+
+func (c CircuitBreaker) connectorOption(roomKey string, hooks []CircuitStateHook) room.OptionConnector {
+	opts := []room.OptionCircuitBreaker{room.WithConsecutiveFailures(c.ConsecutiveFailures)}
+
+	if c.FailureRate > 0 {
+		opts = append(opts, room.WithFailureRate(c.FailureRate, time.Duration(c.Window)*time.Second, c.MinRequests))
+	}
+
+	if c.OpenTimeout > 0 {
+		opts = append(opts, room.WithOpenTimeout(time.Duration(c.OpenTimeout)*time.Second))
+	}
+
+	if c.HalfOpenRequests > 0 {
+		opts = append(opts, room.WithHalfOpenRequests(c.HalfOpenRequests))
+	}
+
+	for _, hook := range hooks {
+		opts = append(opts, room.WithCircuitStateHook(func(from, to room.CircuitState) {
+			hook(roomKey, from, to)
+		}))
+	}
+
+	return room.WithCircuitBreaker(room.NewCircuitBreaker(opts...))
 }
 
 type ConnectionAuth struct {
-	Type           string  `yaml:"type"`
-	AccessTokenKey string  `yaml:"accessTokenKey"`
-	Request        Request `yaml:"request"`
+	Type           string  `yaml:"type,omitempty"`
+	AccessTokenKey string  `yaml:"accessTokenKey,omitempty"`
+	Request        Request `yaml:"request,omitempty"`
 }
 
 type Request struct {
-	ConcurrentKey string `yaml:"concurrentKey"`
-	Method        string `yaml:"method"`
-	Path          string `yaml:"path"`
-	Body          Body   `yaml:"body"`
+	ConcurrentKey  string            `yaml:"concurrentKey,omitempty"`
+	Method         string            `yaml:"method,omitempty"`
+	Path           string            `yaml:"path,omitempty"`
+	Body           Body              `yaml:"body,omitempty"`
+	Headers        map[string]any    `yaml:"headers,omitempty"`
+	Query          Query             `yaml:"query,omitempty"`
+	Timeout        int               `yaml:"timeout,omitempty"`
+	Cookies        map[string]string `yaml:"cookies,omitempty"`
+	Accept         string            `yaml:"accept,omitempty"`
+	ExpectedStatus []int             `yaml:"expectedStatus,omitempty"`
+	Expect         *Expect           `yaml:"expect,omitempty"`
+	Hedge          *Hedge            `yaml:"hedge,omitempty"`
+	AttemptTimeout Duration          `yaml:"attemptTimeout,omitempty"`
+	Pagination     *Pagination       `yaml:"pagination,omitempty"`
+	Poll           *Poll             `yaml:"poll,omitempty"`
+	Stream         string            `yaml:"stream,omitempty"`
+}
+
+// StreamSSE declares a server-sent events request, it is opened by ElevatorEngine.Stream
+const StreamSSE = "sse"
+
+// Poll follows the status url of a 202 Accepted response until the job completes and returns its result,
+// see room.AwaitCompletion. The polling is limited by the timeout, 5 minutes by default, and by the context of ExecuteContext.
+type Poll struct {
+	StatusUrlPath string   `yaml:"statusUrlPath,omitempty"`
+	DoneWhen      string   `yaml:"doneWhen,omitempty"`
+	FailedWhen    string   `yaml:"failedWhen,omitempty"`
+	ResultUrlPath string   `yaml:"resultUrlPath,omitempty"`
+	Interval      Duration `yaml:"interval,omitempty"`
+	MaxInterval   Duration `yaml:"maxInterval,omitempty"`
+	Timeout       Duration `yaml:"timeout,omitempty"`
+}
+
+func (p Poll) await(ctx context.Context, roomEntry room.IRoom, accepted room.Response, roomKey, requestKey string) (room.Response, error) {
+	opts := []room.OptionPoll{
+		room.WithPollRequestOptions(room.WithLabels(map[string]string{"room": roomKey, "request": requestKey, "poll": "true"})),
+	}
+
+	if p.StatusUrlPath != "" {
+		opts = append(opts, room.WithStatusUrlPath(p.StatusUrlPath))
+	}
+
+	if p.DoneWhen != "" {
+		opts = append(opts, room.WithDoneWhen(p.DoneWhen))
+	}
+
+	if p.FailedWhen != "" {
+		opts = append(opts, room.WithFailedWhen(p.FailedWhen))
+	}
+
+	if p.ResultUrlPath != "" {
+		opts = append(opts, room.WithResultUrlPath(p.ResultUrlPath))
+	}
+
+	if p.Interval > 0 {
+		maxInterval := p.MaxInterval
+		if maxInterval < p.Interval {
+			maxInterval = p.Interval
+		}
+
+		opts = append(opts, room.WithPollInterval(time.Duration(p.Interval), time.Duration(maxInterval)))
+	}
+
+	timeout := 5 * time.Minute
+	if p.Timeout > 0 {
+		timeout = time.Duration(p.Timeout)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return room.AwaitCompletion(ctx, room.DoerFunc(roomEntry.Send), accepted, opts...)
+}
+
+const (
+	PaginationPage   = "page"
+	PaginationOffset = "offset"
+	PaginationCursor = "cursor"
+	PaginationLink   = "link"
+)
+
+// Pagination declares how the pages of a list request are walked by ElevatorEngine.Pager,
+// param is the page, offset or cursor query param and sizeParam is the page size or limit param
+type Pagination struct {
+	Type        string `yaml:"type,omitempty"`
+	Param       string `yaml:"param,omitempty"`
+	SizeParam   string `yaml:"sizeParam,omitempty"`
+	Size        int    `yaml:"size,omitempty"`
+	Start       *int   `yaml:"start,omitempty"`
+	CursorPath  string `yaml:"cursorPath,omitempty"`
+	ItemsPath   string `yaml:"itemsPath,omitempty"`
+	MaxPages    int    `yaml:"maxPages,omitempty"`
+	MaxItems    int    `yaml:"maxItems,omitempty"`
+	Concurrency int    `yaml:"concurrency,omitempty"`
+}
+
+func (p Pagination) param(fallback string) string {
+	if p.Param != "" {
+		return p.Param
+	}
+
+	return fallback
+}
+
+func (p Pagination) strategy() room.PageStrategy {
+	switch p.Type {
+	case PaginationOffset:
+		limitParam := p.SizeParam
+		if limitParam == "" {
+			limitParam = "limit"
+		}
+
+		return room.Offset(p.param("offset"), limitParam, p.Size)
+	case PaginationCursor:
+		return room.Cursor(p.param("cursor"), p.CursorPath)
+	case PaginationLink:
+		return room.LinkHeader()
+	}
+
+	start := 1
+	if p.Start != nil {
+		start = *p.Start
+	}
+
+	return room.PageNumber(p.param("page"), start, p.SizeParam, p.Size)
+}
+
+func (p Pagination) options() []room.OptionPaginate {
+	opts := []room.OptionPaginate{room.WithMaxPages(p.MaxPages), room.WithMaxItems(p.MaxItems)}
+
+	if p.ItemsPath != "" {
+		opts = append(opts, room.WithItemsPath(p.ItemsPath))
+	}
+
+	if p.Concurrency > 1 {
+		opts = append(opts, room.WithPageConcurrency(p.Concurrency))
+	}
+
+	return opts
+}
+
+// Hedge sends a duplicate of a slow GET or HEAD request after the delay, or after the percentile of its recent latencies
+type Hedge struct {
+	Delay       Duration `yaml:"delay,omitempty"`
+	Percentile  float64  `yaml:"percentile,omitempty"`
+	MaxAttempts int      `yaml:"maxAttempts,omitempty"`
+}
+
+func (h Hedge) requestOption() room.OptionRequest {
+	var opts []room.OptionHedging
+
+	if h.Percentile > 0 {
+		opts = append(opts, room.WithHedgePercentile(h.Percentile))
+	}
+
+	if h.MaxAttempts > 0 {
+		opts = append(opts, room.WithHedgeAttempts(h.MaxAttempts))
+	}
+
+	return room.WithHedging(room.NewHedging(time.Duration(h.Delay), opts...))
+}
+
+// Query declares the query of a request, dynamic contents are filled from the DynamicExecute payload like the body
+type Query struct {
+	Content        map[string]any   `yaml:"content,omitempty"`
+	DynamicContent []DynamicContent `yaml:"dynamicContent,omitempty"`
 }
 
 type Room struct {
-	Connection Connection         `yaml:"connection"`
-	Requests   map[string]Request `yaml:"requests"`
+	Connection Connection         `yaml:"connection,omitempty"`
+	Requests   map[string]Request `yaml:"requests,omitempty"`
 }
 
 type Flat struct {
-	Rooms map[string]Room `yaml:"rooms"`
+	Rooms map[string]Room `yaml:"rooms,omitempty"`
 }
 
 type IntegrationConfig struct {
-	Flat Flat `yaml:"flat"`
+	Flat Flat `yaml:"flat,omitempty"`
 }
 
 func (r *IntegrationConfig) UnmarshalJSON(data []byte) error {
@@ -399,6 +1302,11 @@ func NewDynamicExecutionPayload(v any) DynamicExecutionPayload {
 		}
 	default:
 		val := reflect.ValueOf(v)
+
+		for val.Kind() == reflect.Pointer && !val.IsNil() {
+			val = val.Elem()
+		}
+
 		if val.Kind() == reflect.Struct {
 			structToMap(val, &payload)
 		}
@@ -407,15 +1315,78 @@ func NewDynamicExecutionPayload(v any) DynamicExecutionPayload {
 	return payload
 }
 
+// structToMap fills the payload by the json names of the exported fields,
+// like encoding/json the fields of embedded structs are flattened into the payload and named structs stay under their key,
+// types with their own marshalling like time.Time are kept as they are
 func structToMap(val reflect.Value, payload *DynamicExecutionPayload) {
 	for i := 0; i < val.NumField(); i++ {
 		field := val.Field(i)
-		fieldName := val.Type().Field(i).Tag.Get("json")
+		structField := val.Type().Field(i)
 
-		if field.Kind() == reflect.Struct {
-			structToMap(field, payload)
-		} else {
-			payload.Fields[fieldName] = field.Interface()
+		if !structField.IsExported() {
+			continue
+		}
+
+		fieldName, omitEmpty, skip := jsonFieldName(structField)
+
+		if skip {
+			continue
 		}
+
+		if embedded := fieldValue(field); structField.Anonymous && structField.Tag.Get("json") == "" && embedded != nil {
+			if embeddedValue := reflect.ValueOf(embedded); embeddedValue.Kind() == reflect.Struct && !marshals(embeddedValue) {
+				structToMap(embeddedValue, payload)
+				continue
+			}
+		}
+
+		if omitEmpty && field.IsZero() {
+			continue
+		}
+
+		payload.Fields[fieldName] = fieldValue(field)
+	}
+}
+
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag := field.Tag.Get("json")
+
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+
+	if name == "" {
+		name = field.Name
 	}
+
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty, false
+}
+
+// fieldValue dereferences the pointers of the field
+func fieldValue(field reflect.Value) any {
+	for field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	return field.Interface()
+}
+
+func marshals(field reflect.Value) bool {
+	switch field.Interface().(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return true
+	}
+
+	return false
 }
@@ -0,0 +1,1148 @@
+package elevator
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/WEG-Technology/room"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newEchoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"path":     r.URL.Path,
+			"query":    r.URL.RawQuery,
+			"body":     string(body),
+			"xKey":     r.Header.Get("X-Key"),
+			"accept":   r.Header.Get("Accept"),
+			"cookie":   r.Header.Get("Cookie"),
+			"xVersion": r.Header.Get("X-Version"),
+		})
+	}))
+}
+
+func newTestEngine(baseUrl string, requests ...map[string]Request) *ElevatorEngine {
+	el := Elevator{Config: IntegrationConfig{Flat: Flat{Rooms: map[string]Room{
+		"todoRoom": {
+			Connection: Connection{
+				BaseURL: baseUrl,
+				Timeout: 5,
+				Headers: map[string]any{"X-Key": "connector", "Accept": "application/json"},
+			},
+			Requests: map[string]Request{
+				"addTodo": {
+					Method: "POST",
+					Path:   "todos/{id}",
+					Body: Body{
+						Type:           "json",
+						DynamicContent: []DynamicContent{{Key: "todo"}},
+					},
+				},
+			},
+		},
+	}}}}
+
+	for _, r := range requests {
+		for requestKey, req := range r {
+			el.Config.Flat.Rooms["todoRoom"].Requests[requestKey] = req
+		}
+	}
+
+	engine := NewElevatorEngine(el)
+	engine.WarmUp()
+
+	return engine
+}
+
+func TestElevatorEngine_ExecuteWith(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	engine := newTestEngine(server.URL)
+
+	response, err := engine.ExecuteWith("todoRoom", "addTodo",
+		room.WithPathParams(map[string]string{"id": "1"}),
+		room.WithHeader(room.NewHeader().Add("X-Key", "call")),
+	)
+
+	if err != nil {
+		t.Fatalf("ExecuteWith() returned error: %v", err)
+	}
+
+	body := response.ResponseBody()
+
+	if body["path"] != "/todos/1" {
+		t.Errorf("ExecuteWith() requested %v, expected /todos/1", body["path"])
+	}
+
+	if body["xKey"] != "call" {
+		t.Errorf("ExecuteWith() sent header %v, expected call", body["xKey"])
+	}
+
+	// the template must not keep the overrides of the previous call
+	response, _ = engine.ExecuteWith("todoRoom", "addTodo", room.WithPathParams(map[string]string{"id": "2"}))
+
+	body = response.ResponseBody()
+
+	if body["path"] != "/todos/2" {
+		t.Errorf("ExecuteWith() requested %v, expected /todos/2", body["path"])
+	}
+
+	if body["xKey"] != "connector" {
+		t.Errorf("ExecuteWith() sent header %v, expected connector", body["xKey"])
+	}
+}
+
+func TestElevatorEngine_ConcurrentDynamicExecute(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	engine := newTestEngine(server.URL)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			todo := fmt.Sprintf("todo-%d", i)
+
+			if i%2 == 0 {
+				engine.PutQuery("todoRoom", "addTodo", room.NewQuery(map[string]any{}))
+			}
+
+			response, err := engine.DynamicExecute("todoRoom", "addTodo", map[string]any{"todo": todo})
+
+			if err != nil {
+				t.Errorf("DynamicExecute() returned error: %v", err)
+				return
+			}
+
+			var sent map[string]any
+			_ = json.Unmarshal([]byte(response.ResponseBody()["body"].(string)), &sent)
+
+			if sent["todo"] != todo {
+				t.Errorf("DynamicExecute() sent %v, expected %s", sent["todo"], todo)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func TestElevatorEngine_RequestOverrides(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	engine := newTestEngine(server.URL, map[string]Request{
+		"listTodos": {
+			Method:  "GET",
+			Path:    "todos",
+			Headers: map[string]any{"X-Key": "request"},
+			Query: Query{
+				Content:        map[string]any{"limit": 10},
+				DynamicContent: []DynamicContent{{Key: "skip", Type: DynamicTypeInteger}},
+			},
+			Timeout:        1,
+			Cookies:        map[string]string{"session": "abc"},
+			Accept:         "application/json",
+			ExpectedStatus: []int{201},
+		},
+	})
+
+	response, err := engine.DynamicExecute("todoRoom", "listTodos", map[string]any{"skip": "20"})
+
+	var statusError room.UnexpectedStatusError
+	if !errors.As(err, &statusError) || statusError.StatusCode != 200 {
+		t.Errorf("DynamicExecute() returned %v, expected an UnexpectedStatusError for 200", err)
+	}
+
+	body := response.ResponseBody()
+
+	expected := map[string]any{
+		"query":  "limit=10&skip=20",
+		"xKey":   "request",
+		"accept": "application/json",
+		"cookie": "session=abc",
+	}
+
+	for key, value := range expected {
+		if body[key] != value {
+			t.Errorf("DynamicExecute() sent %s %v, expected %v", key, body[key], value)
+		}
+	}
+
+	_, err = engine.DynamicExecute("todoRoom", "listTodos", map[string]any{})
+
+	var validationError ValidationError
+	if !errors.As(err, &validationError) || validationError.Errors[0].Field != "query.skip" {
+		t.Errorf("DynamicExecute() returned %v, expected a validation error for query.skip", err)
+	}
+}
+
+func TestElevatorEngine_RequestHeaders(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	engine := newTestEngine(server.URL, map[string]Request{
+		"listTodos": {Method: "GET", Path: "todos", Headers: map[string]any{"X-Version": 2}, Accept: "application/json"},
+	})
+
+	response, err := engine.Execute("todoRoom", "listTodos")
+
+	if err != nil || response.ResponseBody()["xVersion"] != "2" {
+		t.Errorf("Execute() = %v, %v", response.ResponseBody(), err)
+	}
+
+	// the header of the call is merged over the headers of the yml
+	response, err = engine.ExecuteWith("todoRoom", "listTodos", room.WithHeader(room.NewHeader().Add("X-Version", "3")))
+
+	if body := response.ResponseBody(); err != nil || body["xVersion"] != "3" || body["accept"] != "application/json" {
+		t.Errorf("ExecuteWith() = %v, %v", body, err)
+	}
+}
+
+func TestElevatorEngine_ConnectionHeaderOverrides(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	engine := newTestEngine(server.URL, map[string]Request{
+		"listTodos": {Method: "GET", Path: "todos", Headers: map[string]any{"X-Key": "request"}, Accept: "application/xml"},
+	})
+
+	// the headers and the accept of the yml request are merged over the headers of the connection
+	response, err := engine.Execute("todoRoom", "listTodos")
+
+	if body := response.ResponseBody(); err != nil || body["xKey"] != "request" || body["accept"] != "application/xml" {
+		t.Errorf("Execute() = %v, %v", body, err)
+	}
+
+	// the header of the call is merged over both
+	response, err = engine.ExecuteWith("todoRoom", "listTodos", room.WithHeader(room.NewHeader().Add("X-Key", "call")))
+
+	if body := response.ResponseBody(); err != nil || body["xKey"] != "call" || body["accept"] != "application/xml" {
+		t.Errorf("ExecuteWith() = %v, %v", body, err)
+	}
+
+	response, err = engine.ExecuteWith("todoRoom", "addTodo", room.WithPathParams(map[string]string{"id": "1"}),
+		room.WithHeader(room.NewHeader().Add("X-Key", "call")))
+
+	if body := response.ResponseBody(); err != nil || body["xKey"] != "call" || body["accept"] != "application/json" {
+		t.Errorf("ExecuteWith() = %v, %v", body, err)
+	}
+}
+
+func TestElevatorEngine_ExecuteConcurrentSegment(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	engine := newTestEngine(server.URL, map[string]Request{
+		"listTodos": {ConcurrentKey: "list", Method: "GET", Path: "todos"},
+	})
+
+	responses, s := engine.ExecuteConcurrentSegment("list")
+
+	if len(responses) != 1 {
+		t.Fatalf("ExecuteConcurrentSegment() returned %d responses, expected 1", len(responses))
+	}
+
+	children := s.Children()
+
+	if len(children) != 1 || children[0].Name() != "todoRoom.listTodos" {
+		t.Fatalf("ExecuteConcurrentSegment() returned children %v, expected todoRoom.listTodos", children)
+	}
+
+	if children[0].Timing().Total <= 0 || responses["todoRoom"].Timing.Total <= 0 {
+		t.Error("ExecuteConcurrentSegment() did not keep the timing of the response")
+	}
+
+	if s.GetElapsedTime() <= 0 {
+		t.Error("ExecuteConcurrentSegment() did not end the batch segment")
+	}
+}
+
+func TestElevatorEngine_CircuitBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	el := Elevator{Config: IntegrationConfig{Flat: Flat{Rooms: map[string]Room{
+		"todoRoom": {
+			Connection: Connection{
+				BaseURL:        server.URL,
+				CircuitBreaker: CircuitBreaker{ConsecutiveFailures: 1, OpenTimeout: 60},
+			},
+			Requests: map[string]Request{"listTodos": {Method: "GET", Path: "todos"}},
+		},
+	}}}}
+
+	if err := el.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []string
+	engine := NewElevatorEngine(el, WithCircuitStateHook(func(roomKey string, from, to room.CircuitState) {
+		changes = append(changes, fmt.Sprintf("%s %s>%s", roomKey, from, to))
+	})).WarmUp()
+
+	if response, _ := engine.Execute("todoRoom", "listTodos"); response.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("first Execute() status = %d", response.StatusCode)
+	}
+
+	if _, err := engine.Execute("todoRoom", "listTodos"); !errors.Is(err, room.ErrCircuitOpen) {
+		t.Errorf("second Execute() error = %v, want ErrCircuitOpen", err)
+	}
+
+	if len(changes) != 1 || changes[0] != "todoRoom closed>open" {
+		t.Errorf("circuit state changes = %v", changes)
+	}
+}
+
+func TestValidate_CircuitBreaker(t *testing.T) {
+	el := Elevator{Config: IntegrationConfig{Flat: Flat{Rooms: map[string]Room{
+		"todoRoom": {Connection: Connection{BaseURL: "http://localhost", CircuitBreaker: CircuitBreaker{FailureRate: 1.5, OpenTimeout: -1}}},
+	}}}}
+
+	err := el.Validate()
+
+	for _, want := range []string{"openTimeout can not be negative", "failureRate must be between 0 and 1", "window is required with failureRate"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestValidate_Logging(t *testing.T) {
+	el := Elevator{Config: IntegrationConfig{Flat: Flat{Rooms: map[string]Room{
+		"todoRoom": {Connection: Connection{BaseURL: "http://localhost", Logging: Logging{Enabled: true, RedactedFields: []string{"$.user[secret"}}}},
+	}}}}
+
+	if err := el.Validate(); err == nil || !strings.Contains(err.Error(), "redactFields") {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestElevatorEngine_Hedge(t *testing.T) {
+	var hits atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	el, err := Load(WithBytes([]byte(`
+flat:
+  rooms:
+    todoRoom:
+      connection:
+        baseUrl: ` + server.URL + `
+      requests:
+        getTodo:
+          method: GET
+          path: todos/1
+          attemptTimeout: 2
+          hedge:
+            delay: 20ms
+            percentile: 0.95
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := el.GetRequest("todoRoom", "getTodo")
+
+	if time.Duration(req.Hedge.Delay) != 20*time.Millisecond || time.Duration(req.AttemptTimeout) != 2*time.Second {
+		t.Fatalf("hedge = %+v, attemptTimeout = %s", req.Hedge, time.Duration(req.AttemptTimeout))
+	}
+
+	if err = el.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := NewElevatorEngine(el).WarmUp().Execute("todoRoom", "getTodo")
+	if err != nil || response.ResponseBody()["id"] != float64(1) {
+		t.Errorf("Execute() = %s, %v", response.Data, err)
+	}
+
+	if hits.Load() != 2 {
+		t.Errorf("server got %d requests, want the hedged attempt", hits.Load())
+	}
+}
+
+func TestValidate_Hedge(t *testing.T) {
+	el := Elevator{Config: IntegrationConfig{Flat: Flat{Rooms: map[string]Room{
+		"todoRoom": {
+			Connection: Connection{BaseURL: "http://localhost"},
+			Requests: map[string]Request{
+				"addTodo": {Method: "POST", Path: "todos", Hedge: &Hedge{Percentile: 95}, AttemptTimeout: -1},
+			},
+		},
+	}}}}
+
+	err := el.Validate()
+
+	for _, want := range []string{"supported for GET and HEAD requests only", "hedge.delay is required", "percentile must be between 0 and 1", "attemptTimeout can not be negative"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestElevatorEngine_Pager(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("after")
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch cursor {
+		case "":
+			_, _ = w.Write([]byte(`{"data":[{"id":1},{"id":2}],"meta":{"next":"b"}}`))
+		case "b":
+			_, _ = w.Write([]byte(`{"data":[{"id":3}],"meta":{"next":null}}`))
+		}
+	}))
+	defer server.Close()
+
+	el, err := Load(WithBytes([]byte(`
+flat:
+  rooms:
+    todoRoom:
+      connection:
+        baseUrl: ` + server.URL + `
+      requests:
+        listTodos:
+          method: GET
+          path: todos
+          pagination:
+            type: cursor
+            param: after
+            cursorPath: $.meta.next
+            itemsPath: $.data
+        getTodo:
+          method: GET
+          path: todos/1
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = el.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewElevatorEngine(el)
+	engine.WarmUp()
+
+	pager, err := engine.Pager("todoRoom", "listTodos")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var items []string
+
+	pager.Walk(func(page room.Page, err error) bool {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, item := range page.Items {
+			items = append(items, string(item))
+		}
+
+		return true
+	})
+
+	if strings.Join(items, ",") != `{"id":1},{"id":2},{"id":3}` {
+		t.Errorf("items = %v", items)
+	}
+
+	if _, err = engine.Pager("todoRoom", "getTodo"); err == nil {
+		t.Error("Pager() of a request without pagination returned no error")
+	}
+}
+
+func TestValidate_Pagination(t *testing.T) {
+	el := Elevator{Config: IntegrationConfig{Flat: Flat{Rooms: map[string]Room{
+		"todoRoom": {
+			Connection: Connection{BaseURL: "http://localhost"},
+			Requests: map[string]Request{
+				"listTodos":  {Path: "todos", Pagination: &Pagination{Type: PaginationOffset, Concurrency: -1}},
+				"listUsers":  {Path: "users", Pagination: &Pagination{Type: PaginationCursor, ItemsPath: "$["}},
+				"listEvents": {Path: "events", Pagination: &Pagination{Type: "scroll"}},
+			},
+		},
+	}}}}
+
+	err := el.Validate()
+
+	for _, want := range []string{"size is required with offset", "concurrency can not be negative", "cursorPath is required", "invalid jsonpath", `type "scroll" is not supported`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestElevatorEngine_Poll(t *testing.T) {
+	var polls atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/exports", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"statusUrl":"/exports/7/status"}`))
+	})
+	mux.HandleFunc("/exports/7/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if polls.Add(1) < 2 {
+			_, _ = w.Write([]byte(`{"state":"pending"}`))
+			return
+		}
+
+		w.Header().Set("Location", "/exports/7")
+		_, _ = w.Write([]byte(`{"state":"complete"}`))
+	})
+	mux.HandleFunc("/exports/7", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"rows":42}`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	el, err := Load(WithBytes([]byte(`
+flat:
+  rooms:
+    reportRoom:
+      connection:
+        baseUrl: ` + server.URL + `
+      requests:
+        export:
+          method: POST
+          path: exports
+          poll:
+            statusUrlPath: $.statusUrl
+            doneWhen: $.state == "complete"
+            failedWhen: $.state == 'error'
+            interval: 1ms
+            timeout: 5s
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = el.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var labels []string
+
+	engine := NewElevatorEngine(el, WithConnectorOptions(room.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return room.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			info, _ := room.InfoFromContext(req.Context())
+			labels = append(labels, info.Labels["request"]+":"+info.Labels["poll"])
+
+			return next.RoundTrip(req)
+		})
+	}))).WarmUp()
+
+	response, err := engine.Execute("reportRoom", "export")
+	if err != nil || response.ResponseBody()["rows"] != float64(42) {
+		t.Fatalf("Execute() = %s, %v", response.Data, err)
+	}
+
+	if strings.Join(labels, ",") != "export:,export:true,export:true,export:true" {
+		t.Errorf("labels = %v", labels)
+	}
+}
+
+func TestElevatorEngine_ExecuteContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/exports/7/status")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	el, err := Load(WithBytes([]byte(`
+flat:
+  rooms:
+    reportRoom:
+      connection:
+        baseUrl: ` + server.URL + `
+      requests:
+        export:
+          method: POST
+          path: exports
+          poll:
+            interval: 1ms
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewElevatorEngine(el)
+	engine.WarmUp()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// the job never completes, the polling ends with the context instead of the 5 minutes timeout
+	start := time.Now()
+
+	if _, err = engine.ExecuteContext(ctx, "reportRoom", "export"); !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > 5*time.Second {
+		t.Errorf("ExecuteContext() = %v after %s", err, time.Since(start))
+	}
+}
+
+func TestValidate_Poll(t *testing.T) {
+	el := Elevator{Config: IntegrationConfig{Flat: Flat{Rooms: map[string]Room{
+		"reportRoom": {
+			Connection: Connection{BaseURL: "http://localhost"},
+			Requests: map[string]Request{
+				"export": {Method: "POST", Path: "exports", Poll: &Poll{DoneWhen: "$.state == complete", Interval: -1}},
+			},
+		},
+	}}}}
+
+	err := el.Validate()
+
+	for _, want := range []string{"not a json literal", "durations can not be negative"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestElevatorEngine_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Channel") != "prices" {
+			t.Errorf("header = %v", r.Header)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: price\ndata: 42\n\nevent: news\ndata: closed\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	el, err := Load(WithBytes([]byte(`
+flat:
+  rooms:
+    marketRoom:
+      connection:
+        baseUrl: ` + server.URL + `
+        headers:
+          X-Channel: prices
+      requests:
+        prices:
+          path: prices
+          stream: sse
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = el.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var events []string
+
+	engine := NewElevatorEngine(el).
+		OnEvent("marketRoom", "prices", "price", func(event room.Event) {
+			events = append(events, "price:"+event.Data)
+		}).
+		OnEvent("marketRoom", "prices", "", func(event room.Event) {
+			events = append(events, "all:"+event.Event)
+
+			if event.Event == "news" {
+				cancel()
+			}
+		})
+
+	engine.WarmUp()
+
+	if _, err = engine.Execute("marketRoom", "prices"); err == nil {
+		t.Error("Execute() of a stream request should fail")
+	}
+
+	if err = engine.Stream(ctx, "marketRoom", "prices"); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(events, ",") != "price:42,all:price,all:news" {
+		t.Errorf("events = %v", events)
+	}
+
+	el.Config.Flat.Rooms["marketRoom"].Requests["prices"] = Request{Path: "prices", Stream: "websocket", Poll: &Poll{}}
+
+	if err = el.Validate(); err == nil || !strings.Contains(err.Error(), `stream "websocket" is not supported`) {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestElevatorEngine_WebSocket(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"token":"secret"}}`))
+	})
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		conn, rw, _ := w.(http.Hijacker).Hijack()
+		defer conn.Close()
+
+		sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		message := `{"kind":"price","value":42}`
+
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+		_, _ = rw.Write(append([]byte{0x81, byte(len(message))}, message...))
+		_ = rw.Flush()
+
+		// the connection stays open until the client closes it
+		_, _ = io.Copy(io.Discard, rw)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	el, err := Load(WithBytes([]byte(`
+flat:
+  rooms:
+    marketRoom:
+      connection:
+        baseUrl: ` + strings.Replace(server.URL, "http://", "ws://", 1) + `
+        protocol: websocket
+        timeout: 5
+        webSocket:
+          pingInterval: 1s
+          reconnectAttempts: 0
+          messageTypePath: $.kind
+        auth:
+          type: bearer
+          accessTokenKey: token
+          request:
+            method: POST
+            path: login
+      requests:
+        feed:
+          path: feed
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = el.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewElevatorEngine(el)
+	engine.WarmUp()
+
+	if _, err = engine.Execute("marketRoom", "feed"); err == nil {
+		t.Error("Execute() of a websocket room should fail")
+	}
+
+	ws, err := engine.WebSocket(context.Background(), "marketRoom", "feed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if message, err := ws.Next(ctx); err != nil || message.Type != "price" {
+		t.Errorf("Next() = %+v, %v", message, err)
+	}
+
+	el.Config.Flat.Rooms["marketRoom"].Requests["feed"] = Request{Method: "POST", Path: "feed", Stream: StreamSSE}
+	marketRoom := el.Config.Flat.Rooms["marketRoom"]
+	marketRoom.Connection.Protocol = "grpc"
+	el.Config.Flat.Rooms["marketRoom"] = marketRoom
+
+	err = el.Validate()
+
+	if err == nil || !strings.Contains(err.Error(), `protocol "grpc" is not supported`) {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	marketRoom.Connection.Protocol = ProtocolWebSocket
+	el.Config.Flat.Rooms["marketRoom"] = marketRoom
+
+	for _, want := range []string{"can not declare a method or body", "can not declare stream"} {
+		if err = el.Validate(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestElevatorEngine_GraphQL(t *testing.T) {
+	var body map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s", r.Method)
+		}
+
+		body = nil
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if body["variables"].(map[string]any)["id"] == "404" {
+			_, _ = w.Write([]byte(`{"data":{"user":null},"errors":[{"message":"user not found","path":["user"]}]}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"user":{"name":"Ada"}}}`))
+	}))
+	defer server.Close()
+
+	el, err := Load(WithBytes([]byte(`
+flat:
+  rooms:
+    userRoom:
+      connection:
+        baseUrl: ` + server.URL + `
+      requests:
+        getUser:
+          path: graphql
+          body:
+            type: graphql
+            query: "query User($id: ID!, $locale: String) { user(id: $id) { name } }"
+            operationName: User
+            content:
+              locale: en
+            dynamicContent:
+              - key: id
+                type: string
+                required: true
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = el.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewElevatorEngine(el).WarmUp()
+
+	user, err := room.DecodeGraphQL[struct {
+		User struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	}](engine.DynamicExecute("userRoom", "getUser", map[string]any{"id": "1"}))
+
+	if err != nil || user.User.Name != "Ada" {
+		t.Errorf("DecodeGraphQL() = %+v, %v", user, err)
+	}
+
+	if body["operationName"] != "User" || fmt.Sprint(body["variables"]) != "map[id:1 locale:en]" {
+		t.Errorf("body = %v", body)
+	}
+
+	// the errors are returned although the status is 200
+	_, err = engine.DynamicExecute("userRoom", "getUser", map[string]any{"id": "404"})
+
+	var errs room.GraphQLErrors
+	if !errors.As(err, &errs) || errs[0].Message != "user not found" {
+		t.Errorf("DynamicExecute() = %v", err)
+	}
+
+	el.Config.Flat.Rooms["userRoom"].Requests["getUser"] = Request{Method: "GET", Path: "graphql", Body: Body{Type: BodyTypeGraphQL, Content: []any{1}}}
+
+	for _, want := range []string{"query, queryFile or persistedQueryHash is required", "must be a map of variables", "sent with POST"} {
+		if err = el.Validate(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestElevatorEngine_JSONRPC(t *testing.T) {
+	var body map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if body["method"] == "eth_getBalance" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": body["id"], "result": "0x10"})
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": body["id"], "error": map[string]any{"code": -32602, "message": "Invalid params"}})
+	}))
+	defer server.Close()
+
+	el, err := Load(WithBytes([]byte(`
+flat:
+  rooms:
+    nodeRoom:
+      connection:
+        baseUrl: ` + server.URL + `
+      requests:
+        getBalance:
+          body:
+            type: jsonrpc
+            method: eth_getBalance
+            positionalParams: true
+            dynamicContent:
+              - key: address
+                type: string
+                required: true
+              - key: block
+                type: string
+                default: latest
+        getStorageAt:
+          body:
+            type: jsonrpc
+            method: eth_getBalance
+            positionalParams: true
+            content: ["0xcd"]
+            dynamicContent:
+              - key: slot
+                type: string
+        getLogs:
+          body:
+            type: jsonrpc
+            method: eth_getLogs
+            content:
+              fromBlock: latest
+            dynamicContent:
+              - key: address
+                type: string
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = el.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewElevatorEngine(el).WarmUp()
+
+	balance, err := room.DecodeJSONRPC[string](engine.DynamicExecute("nodeRoom", "getBalance", map[string]any{"address": "0xab"}))
+
+	if err != nil || balance != "0x10" {
+		t.Errorf("DecodeJSONRPC() = %s, %v", balance, err)
+	}
+
+	if body["jsonrpc"] != "2.0" || fmt.Sprint(body["params"]) != "[0xab latest]" {
+		t.Errorf("body = %v", body)
+	}
+
+	// the dynamic params follow the static ones
+	if _, err = engine.DynamicExecute("nodeRoom", "getStorageAt", map[string]any{"slot": "0x0"}); err != nil || fmt.Sprint(body["params"]) != "[0xcd 0x0]" || body["id"] != float64(1) {
+		t.Errorf("DynamicExecute() = %v with body %v", err, body)
+	}
+
+	// the error object is returned although the status is 200
+	_, err = engine.DynamicExecute("nodeRoom", "getLogs", map[string]any{"address": "0xab"})
+
+	var rpcErr *room.JSONRPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32602 {
+		t.Errorf("DynamicExecute() = %v", err)
+	}
+
+	if fmt.Sprint(body["params"]) != "map[address:0xab fromBlock:latest]" {
+		t.Errorf("params = %v", body["params"])
+	}
+
+	el.Config.Flat.Rooms["nodeRoom"].Requests["getBalance"] = Request{Method: "GET", Body: Body{Type: BodyTypeJSONRPC, Content: "0xab"}}
+	el.Config.Flat.Rooms["nodeRoom"].Requests["getLogs"] = Request{Body: Body{Type: "json", Method: "eth_getLogs"}}
+
+	for _, want := range []string{"method is required with jsonrpc body", "must be a map or a list of params", "sent with POST", "need the jsonrpc body type"} {
+		if err = el.Validate(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestElevatorEngine_SOAP(t *testing.T) {
+	var envelope string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		envelope = string(data)
+
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+
+		if strings.Contains(envelope, "<OrderID>0</OrderID>") {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>` +
+				`<env:Code><env:Value>env:Sender</env:Value></env:Code><env:Reason><env:Text>order not found</env:Text></env:Reason></env:Fault></env:Body></env:Envelope>`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body>` +
+			`<GetOrderResponse xmlns="urn:erp"><Status>shipped</Status></GetOrderResponse></env:Body></env:Envelope>`))
+	}))
+	defer server.Close()
+
+	el, err := Load(WithBytes([]byte(`
+flat:
+  rooms:
+    erpRoom:
+      connection:
+        baseUrl: ` + server.URL + `
+      requests:
+        getOrder:
+          path: erp
+          body:
+            type: soap
+            operation: GetOrder
+            namespace: urn:erp
+            soapAction: urn:erp/GetOrder
+            soapVersion: 1.2
+            security:
+              username: erp
+              password: secret
+            content:
+              Company: "01"
+            dynamicContent:
+              - key: OrderID
+                type: integer
+                required: true
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = el.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewElevatorEngine(el).WarmUp()
+
+	order, err := room.DecodeSOAP[struct {
+		Status string `xml:"Status"`
+	}](engine.DynamicExecute("erpRoom", "getOrder", map[string]any{"OrderID": 7}))
+
+	if err != nil || order.Status != "shipped" {
+		t.Errorf("DecodeSOAP() = %+v, %v", order, err)
+	}
+
+	for _, want := range []string{"<wsse:Username>erp</wsse:Username>", `<GetOrder xmlns="urn:erp"><Company>01</Company><OrderID>7</OrderID></GetOrder>`} {
+		if !strings.Contains(envelope, want) {
+			t.Errorf("envelope = %s, want %s", envelope, want)
+		}
+	}
+
+	// the fault is returned as a typed error
+	_, err = engine.DynamicExecute("erpRoom", "getOrder", map[string]any{"OrderID": 0})
+
+	var fault *room.SOAPFault
+	if !errors.As(err, &fault) || fault.String != "order not found" {
+		t.Errorf("DynamicExecute() = %v", err)
+	}
+
+	el.Config.Flat.Rooms["erpRoom"].Requests["getOrder"] = Request{Method: "GET", Body: Body{Type: BodyTypeSOAP, Content: []any{1}, SOAPVersion: "2.0"}}
+	el.Config.Flat.Rooms["erpRoom"].Requests["listOrders"] = Request{Body: Body{Type: "json", Operation: "ListOrders"}}
+
+	for _, want := range []string{"operation is required with soap body", "must be a map of params", `soapVersion "2.0" is not supported`, "sent with POST", "need the soap body type"} {
+		if err = el.Validate(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestElevatorEngine_Compression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := gzip.NewReader(r.Body)
+
+		if err != nil || r.Header.Get("Accept-Encoding") != "br, gzip" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// the body is sent back gzipped since the client asked for it
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+
+		writer := gzip.NewWriter(w)
+		_, _ = io.Copy(writer, reader)
+		_ = writer.Close()
+	}))
+	defer server.Close()
+
+	el, err := Load(WithBytes([]byte(`
+flat:
+  rooms:
+    erpRoom:
+      connection:
+        baseUrl: ` + server.URL + `
+        compression:
+          encoding: gzip
+          threshold: 16
+          acceptEncoding: [br, gzip]
+      requests:
+        putOrder:
+          method: POST
+          path: orders
+          body:
+            type: json
+            content:
+              note: a note which is long enough to be compressed
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = el.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := NewElevatorEngine(el).WarmUp().Execute("erpRoom", "putOrder")
+
+	if err != nil || response.ResponseBody()["note"] != "a note which is long enough to be compressed" {
+		t.Errorf("Execute() = %s, %v", response.Data, err)
+	}
+
+	erpRoom := el.Config.Flat.Rooms["erpRoom"]
+	erpRoom.Connection.Compression = Compression{Encoding: "lz4", Threshold: -1, AcceptEncoding: []string{"compress"}}
+	el.Config.Flat.Rooms["erpRoom"] = erpRoom
+
+	for _, want := range []string{`encoding "lz4" is not supported`, "threshold can not be negative", `acceptEncoding "compress" is not supported`} {
+		if err = el.Validate(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want %q", err, want)
+		}
+	}
+}
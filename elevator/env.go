@@ -0,0 +1,100 @@
+package elevator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadEnvFiles reads KEY=VALUE lines of the dotenv files into the environment, so they are injected into the integration yml.
+// Variables which are already set are kept, so the environment and then the first file win.
+func LoadEnvFiles(paths ...string) error {
+	for _, path := range paths {
+		variables, err := readEnvFile(path)
+
+		if err != nil {
+			return err
+		}
+
+		for _, variable := range variables {
+			if _, exists := os.LookupEnv(variable[0]); exists {
+				continue
+			}
+
+			if err = os.Setenv(variable[0], variable[1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func readEnvFile(path string) ([][2]string, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var variables [][2]string
+
+	scanner := bufio.NewScanner(file)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		key = strings.TrimSpace(key)
+
+		if !found || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, line)
+		}
+
+		value, err = envValue(strings.TrimSpace(value))
+
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+
+		variables = append(variables, [2]string{key, value})
+	}
+
+	return variables, scanner.Err()
+}
+
+// envValue unquotes double quoted values with escapes, single quoted values literally and strips comments of unquoted ones
+func envValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end := strings.LastIndex(value, `"`)
+
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quote in %s", value)
+		}
+
+		return strconv.Unquote(value[:end+1])
+	case strings.HasPrefix(value, "'"):
+		end := strings.LastIndex(value, "'")
+
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quote in %s", value)
+		}
+
+		return value[1:end], nil
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+
+	return value, nil
+}
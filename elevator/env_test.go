@@ -0,0 +1,61 @@
+package elevator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	first := filepath.Join(dir, ".env")
+	second := filepath.Join(dir, ".env.local")
+
+	if err := os.WriteFile(first, []byte(`
+# credentials
+export ROOM_ENV_USERNAME=emilys
+ROOM_ENV_PASSWORD="pass # word\n"
+ROOM_ENV_TOKEN='$literal'
+ROOM_ENV_URL=https://dummyjson.com # comment
+ROOM_ENV_SET=file
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(second, []byte("ROOM_ENV_USERNAME=overridden\nROOM_ENV_EXTRA=extra\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("ROOM_ENV_SET", "environment")
+
+	for _, key := range []string{"ROOM_ENV_USERNAME", "ROOM_ENV_PASSWORD", "ROOM_ENV_TOKEN", "ROOM_ENV_URL", "ROOM_ENV_EXTRA"} {
+		t.Setenv(key, "")
+		_ = os.Unsetenv(key)
+	}
+
+	if err := LoadEnvFiles(first, second); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, expected := range map[string]string{
+		"ROOM_ENV_USERNAME": "emilys",
+		"ROOM_ENV_PASSWORD": "pass # word\n",
+		"ROOM_ENV_TOKEN":    "$literal",
+		"ROOM_ENV_URL":      "https://dummyjson.com",
+		"ROOM_ENV_SET":      "environment",
+		"ROOM_ENV_EXTRA":    "extra",
+	} {
+		if actual := os.Getenv(key); actual != expected {
+			t.Errorf("%s = %q, expected %q", key, actual, expected)
+		}
+	}
+
+	if err := os.WriteFile(first, []byte("INVALID\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadEnvFiles(first); err == nil {
+		t.Error("LoadEnvFiles() returned nil for a line without =")
+	}
+}
@@ -0,0 +1,392 @@
+package elevator
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	includeKey  = "include"
+	profilesKey = "profiles"
+)
+
+// configSource is a single yml document, fsys is nil for the files on the local file system
+type configSource struct {
+	name string
+	fsys fs.FS
+	data []byte
+}
+
+type loader struct {
+	sources []configSource
+	profile string
+	errs    []error
+}
+
+type OptionLoad func(loader *loader)
+
+// WithFiles loads the given yml files in order, later files are merged over the earlier ones
+func WithFiles(paths ...string) OptionLoad {
+	return func(loader *loader) {
+		for _, p := range paths {
+			loader.sources = append(loader.sources, configSource{name: p})
+		}
+	}
+}
+
+// WithDir loads every .yml and .yaml file of the directory in lexical order
+func WithDir(dir string) OptionLoad {
+	return func(loader *loader) {
+		entries, err := os.ReadDir(dir)
+
+		if err != nil {
+			loader.errs = append(loader.errs, err)
+			return
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() && isYml(entry.Name()) {
+				loader.sources = append(loader.sources, configSource{name: filepath.Join(dir, entry.Name())})
+			}
+		}
+	}
+}
+
+// WithFS loads the files of fsys matching the glob patterns, it makes configs embedded by go:embed loadable.
+// Includes of these files are resolved in fsys too.
+func WithFS(fsys fs.FS, patterns ...string) OptionLoad {
+	return func(loader *loader) {
+		for _, pattern := range patterns {
+			matches, err := fs.Glob(fsys, pattern)
+
+			if err != nil {
+				loader.errs = append(loader.errs, err)
+				continue
+			}
+
+			if len(matches) == 0 {
+				loader.errs = append(loader.errs, fmt.Errorf("%s: no config file found", pattern))
+			}
+
+			for _, match := range matches {
+				loader.sources = append(loader.sources, configSource{name: match, fsys: fsys})
+			}
+		}
+	}
+}
+
+// WithBytes loads the yml content as it is, includes are resolved relative to the working directory
+func WithBytes(content []byte) OptionLoad {
+	return func(loader *loader) {
+		loader.sources = append(loader.sources, configSource{name: "", data: content})
+	}
+}
+
+// WithProfile merges the profile of the given name over the base config
+func WithProfile(profile string) OptionLoad {
+	return func(loader *loader) {
+		loader.profile = profile
+	}
+}
+
+// Load composes the config from all sources, includes are merged before the including file,
+// so every file can override what it includes. YAML anchors are supported within a single file.
+//
+//	include:
+//	  - rooms/*.yml
+//	profiles:
+//	  staging:
+//	    flat:
+//	      rooms:
+//	        todoRoom:
+//	          connection:
+//	            baseUrl: https://staging.example.com
+func Load(opts ...OptionLoad) (Elevator, error) {
+	elevator, _, err := load(opts...)
+
+	return elevator, err
+}
+
+// load is Load returning the local files read so far, also when the config can not be composed from them
+func load(opts ...OptionLoad) (Elevator, []string, error) {
+	l := &loader{}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if len(l.errs) > 0 {
+		return Elevator{}, nil, errors.Join(l.errs...)
+	}
+
+	if len(l.sources) == 0 {
+		return Elevator{}, nil, errors.New("no config source given")
+	}
+
+	merged := map[string]any{}
+	var paths []string
+
+	for _, source := range l.sources {
+		document, err := l.load(source, nil, &paths)
+
+		if err != nil {
+			return Elevator{}, paths, err
+		}
+
+		merged = deepMerge(merged, document)
+	}
+
+	profiles, _ := merged[profilesKey].(map[string]any)
+	delete(merged, profilesKey)
+
+	if l.profile != "" {
+		profile, ok := profiles[l.profile]
+
+		if !ok {
+			return Elevator{}, paths, fmt.Errorf("profile %s not found", l.profile)
+		}
+
+		if profileDocument, ok := profile.(map[string]any); ok {
+			merged = deepMerge(merged, profileDocument)
+		}
+	}
+
+	config, err := decodeConfig(merged)
+
+	if err != nil {
+		return Elevator{}, paths, err
+	}
+
+	return Elevator{
+		Config: config,
+		paths:  paths,
+	}, paths, nil
+}
+
+// load reads the source with its includes, stack holds the files being loaded to detect include cycles
+func (l *loader) load(source configSource, stack []string, paths *[]string) (map[string]any, error) {
+	if source.name != "" && slices.Contains(stack, source.name) {
+		return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), source.name)
+	}
+
+	data := source.data
+
+	if data == nil {
+		var err error
+
+		if source.fsys != nil {
+			data, err = fs.ReadFile(source.fsys, source.name)
+		} else {
+			data, err = readYml(source.name)
+			*paths = appendUnique(*paths, source.name)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	document := map[string]any{}
+
+	if err := yaml.Unmarshal([]byte(injectEnv(data)), &document); err != nil {
+		return nil, fmt.Errorf("%s: %w", sourceName(source), err)
+	}
+
+	if err := resolveQueryFiles(source, document, paths); err != nil {
+		return nil, fmt.Errorf("%s: %w", sourceName(source), err)
+	}
+
+	includes, err := includePatterns(document[includeKey])
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", sourceName(source), err)
+	}
+
+	delete(document, includeKey)
+
+	merged := map[string]any{}
+
+	for _, pattern := range includes {
+		includedSources, err := source.resolve(pattern)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", sourceName(source), err)
+		}
+
+		for _, includedSource := range includedSources {
+			included, err := l.load(includedSource, append(stack, source.name), paths)
+
+			if err != nil {
+				return nil, err
+			}
+
+			merged = deepMerge(merged, included)
+		}
+	}
+
+	return deepMerge(merged, document), nil
+}
+
+// resolve finds the included files relative to the including source
+func (s configSource) resolve(pattern string) ([]configSource, error) {
+	var matches []string
+	var err error
+
+	if s.fsys != nil {
+		pattern = path.Join(path.Dir(s.name), pattern)
+		matches, err = fs.Glob(s.fsys, pattern)
+	} else {
+		if !filepath.IsAbs(pattern) && s.name != "" {
+			pattern = filepath.Join(filepath.Dir(s.name), pattern)
+		}
+		matches, err = filepath.Glob(pattern)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("include %s: no config file found", pattern)
+	}
+
+	sort.Strings(matches)
+
+	sources := make([]configSource, len(matches))
+
+	for i, match := range matches {
+		sources[i] = configSource{name: match, fsys: s.fsys}
+	}
+
+	return sources, nil
+}
+
+// resolveQueryFiles reads the queryFile of the bodies into their query, the files are relative to the source declaring them
+func resolveQueryFiles(source configSource, node any, paths *[]string) error {
+	switch node := node.(type) {
+	case map[string]any:
+		if body, ok := node["body"].(map[string]any); ok {
+			if queryFile, ok := body["queryFile"].(string); ok && queryFile != "" {
+				query, err := source.readRelative(queryFile, paths)
+
+				if err != nil {
+					return err
+				}
+
+				body["query"] = string(query)
+			}
+		}
+
+		for _, value := range node {
+			if err := resolveQueryFiles(source, value, paths); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, value := range node {
+			if err := resolveQueryFiles(source, value, paths); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readRelative reads the file relative to the source, local files are added to the watched paths
+func (s configSource) readRelative(name string, paths *[]string) ([]byte, error) {
+	if s.fsys != nil {
+		return fs.ReadFile(s.fsys, path.Join(path.Dir(s.name), name))
+	}
+
+	if !filepath.IsAbs(name) && s.name != "" {
+		name = filepath.Join(filepath.Dir(s.name), name)
+	}
+
+	data, err := os.ReadFile(name)
+
+	if err == nil {
+		*paths = appendUnique(*paths, name)
+	}
+
+	return data, err
+}
+
+func includePatterns(v any) ([]string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		patterns := make([]string, len(v))
+
+		for i, pattern := range v {
+			s, ok := pattern.(string)
+
+			if !ok {
+				return nil, fmt.Errorf("include must be a list of file paths")
+			}
+
+			patterns[i] = s
+		}
+
+		return patterns, nil
+	}
+
+	return nil, fmt.Errorf("include must be a list of file paths")
+}
+
+// deepMerge merges src over dst, maps are merged key by key and any other value is replaced
+func deepMerge(dst, src map[string]any) map[string]any {
+	for key, srcValue := range src {
+		srcMap, srcIsMap := srcValue.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+
+		if srcIsMap && dstIsMap {
+			dst[key] = deepMerge(dstMap, srcMap)
+		} else {
+			dst[key] = srcValue
+		}
+	}
+
+	return dst
+}
+
+func decodeConfig(document map[string]any) (config IntegrationConfig, err error) {
+	content, err := yaml.Marshal(document)
+
+	if err != nil {
+		return config, err
+	}
+
+	return unmarshalYmlContent(string(content))
+}
+
+func sourceName(source configSource) string {
+	if source.name == "" {
+		return "<bytes>"
+	}
+
+	return source.name
+}
+
+func isYml(name string) bool {
+	return strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml")
+}
+
+func appendUnique(paths []string, p string) []string {
+	if slices.Contains(paths, p) {
+		return paths
+	}
+
+	return append(paths, p)
+}
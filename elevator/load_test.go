@@ -0,0 +1,210 @@
+package elevator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const loadTestBaseYml = `include:
+  - rooms/*.yml
+defaults: &defaults
+  timeout: 15
+  headers:
+    Content-Type: "application/json"
+flat:
+  rooms:
+    todoRoom:
+      connection:
+        <<: *defaults
+        baseUrl: https://example.com
+profiles:
+  staging:
+    flat:
+      rooms:
+        todoRoom:
+          connection:
+            baseUrl: https://staging.example.com
+`
+
+const loadTestRoomYml = `flat:
+  rooms:
+    todoRoom:
+      connection:
+        baseUrl: https://included.example.com
+      requests:
+        addTodo:
+          method: "POST"
+          path: "todos/add"
+    userRoom:
+      connection:
+        baseUrl: https://users.example.com
+`
+
+func writeLoadTestFiles(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	if err := os.Mkdir(filepath.Join(dir, "rooms"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"integration.yml":    loadTestBaseYml,
+		"rooms/todo.yml":     loadTestRoomYml,
+		"rooms/ignored.json": "{}",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestLoad_Include(t *testing.T) {
+	dir := writeLoadTestFiles(t)
+
+	el, err := Load(WithFiles(filepath.Join(dir, "integration.yml")))
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	todoRoom := el.Config.Flat.Rooms["todoRoom"]
+
+	if todoRoom.Connection.BaseURL != "https://example.com" {
+		t.Errorf("Load() returned baseUrl %s, expected the including file to override the included one", todoRoom.Connection.BaseURL)
+	}
+
+	if todoRoom.Connection.Timeout != 15 || todoRoom.Connection.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Load() did not resolve the anchor, got %+v", todoRoom.Connection)
+	}
+
+	if _, ok := todoRoom.Requests["addTodo"]; !ok {
+		t.Error("Load() did not merge the requests of the included file")
+	}
+
+	if _, ok := el.Config.Flat.Rooms["userRoom"]; !ok {
+		t.Error("Load() did not merge the rooms of the included file")
+	}
+
+	if len(el.Paths()) != 2 {
+		t.Errorf("Paths() returned %v, expected the file and its include", el.Paths())
+	}
+}
+
+func TestLoad_Profile(t *testing.T) {
+	dir := writeLoadTestFiles(t)
+
+	el, err := Load(WithFiles(filepath.Join(dir, "integration.yml")), WithProfile("staging"))
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	connection := el.Config.Flat.Rooms["todoRoom"].Connection
+
+	if connection.BaseURL != "https://staging.example.com" {
+		t.Errorf("Load() returned baseUrl %s, expected the staging profile", connection.BaseURL)
+	}
+
+	if connection.Timeout != 15 {
+		t.Errorf("Load() returned timeout %d, expected the profile to be merged over the base", connection.Timeout)
+	}
+
+	if _, err = Load(WithFiles(filepath.Join(dir, "integration.yml")), WithProfile("prod")); err == nil {
+		t.Error("Load() did not return an error for an unknown profile")
+	}
+}
+
+func TestLoad_FSAndBytes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/integration.yml": {Data: []byte("include: [rooms/todo.yml]\n")},
+		"config/rooms/todo.yml":  {Data: []byte(loadTestRoomYml)},
+	}
+
+	el, err := Load(
+		WithFS(fsys, "config/integration.yml"),
+		WithBytes([]byte("flat:\n  rooms:\n    userRoom:\n      connection:\n        timeout: 5\n")),
+	)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	userRoom := el.Config.Flat.Rooms["userRoom"]
+
+	if userRoom.Connection.BaseURL != "https://users.example.com" || userRoom.Connection.Timeout != 5 {
+		t.Errorf("Load() did not merge the sources in order, got %+v", userRoom.Connection)
+	}
+
+	if len(el.Paths()) != 0 {
+		t.Errorf("Paths() returned %v, expected no local files", el.Paths())
+	}
+}
+
+func TestLoad_IncludeCycle(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.yml": {Data: []byte("include: [b.yml]\n")},
+		"b.yml": {Data: []byte("include: [a.yml]\n")},
+	}
+
+	_, err := Load(WithFS(fsys, "a.yml"))
+
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("Load() returned %v, expected an include cycle error", err)
+	}
+}
+
+func TestLoad_QueryFile(t *testing.T) {
+	const roomYml = `flat:
+  rooms:
+    userRoom:
+      connection:
+        baseUrl: https://graphql.example.com
+      requests:
+        getUser:
+          path: graphql
+          body:
+            type: graphql
+            queryFile: queries/user.graphql
+`
+	const query = "query User($id: ID!) { user(id: $id) { name } }"
+
+	dir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(dir, "rooms", "queries"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "rooms", "user.yml"), []byte(roomYml), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "rooms", "queries", "user.graphql"), []byte(query), 0o644)
+
+	el, err := Load(WithFiles(filepath.Join(dir, "rooms", "user.yml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := el.GetRequest("userRoom", "getUser").Body.Query; got != query {
+		t.Errorf("query = %q", got)
+	}
+
+	if paths := el.Paths(); len(paths) != 2 || !strings.HasSuffix(paths[1], "user.graphql") {
+		t.Errorf("paths = %v", paths)
+	}
+
+	el, err = Load(WithFS(fstest.MapFS{
+		"config/user.yml":             {Data: []byte(roomYml)},
+		"config/queries/user.graphql": {Data: []byte(query)},
+	}, "config/*.yml"))
+	if err != nil || el.GetRequest("userRoom", "getUser").Body.Query != query {
+		t.Errorf("Load(WithFS) = %v", err)
+	}
+
+	if _, err = Load(WithBytes([]byte(roomYml))); err == nil {
+		t.Error("expected an error for the missing query file")
+	}
+}
@@ -0,0 +1,210 @@
+package elevator
+
+import (
+	"fmt"
+	"github.com/WEG-Technology/room/internal/maputil"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"unicode/utf8"
+)
+
+var knownSchemaTypes = []string{"string", "number", "integer", "boolean", "object", "array", "null"}
+
+// knownSchemaKeywords are the keywords validateSchema checks and the annotations it allows without checking them,
+// checkSchema rejects any other keyword, like $ref, oneOf or format, so a contract can not pass by ignoring it
+var knownSchemaKeywords = []string{
+	"type", "enum", "const", "properties", "required", "additionalProperties", "items",
+	"minimum", "maximum", "minLength", "maxLength", "pattern", "minItems", "maxItems",
+	"title", "description", "default", "examples", "$schema", "$comment",
+}
+
+// validateSchema checks the json document against a json schema, it supports the keywords
+// type, enum, const, properties, required, additionalProperties, items, minimum, maximum,
+// minLength, maxLength, pattern, minItems and maxItems. Both values are expected as decoded by encoding/json.
+func validateSchema(schema any, document any, path string) []string {
+	s, ok := schema.(map[string]any)
+
+	if !ok {
+		if b, isBool := schema.(bool); isBool && !b {
+			return []string{path + ": is not allowed"}
+		}
+
+		return nil
+	}
+
+	if types := schemaTypes(s["type"]); len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool { return schemaTypeOf(t, document) }) {
+		return []string{fmt.Sprintf("%s: expected %v, got %s", path, joinTypes(types), jsonTypeOf(document))}
+	}
+
+	var errs []string
+
+	if enum, ok := s["enum"].([]any); ok && !slices.ContainsFunc(enum, func(v any) bool { return reflect.DeepEqual(v, document) }) {
+		errs = append(errs, fmt.Sprintf("%s: %v is not one of %v", path, document, enum))
+	}
+
+	if constant, ok := s["const"]; ok && !reflect.DeepEqual(constant, document) {
+		errs = append(errs, fmt.Sprintf("%s: expected %v, got %v", path, constant, document))
+	}
+
+	switch v := document.(type) {
+	case map[string]any:
+		errs = append(errs, validateSchemaObject(s, v, path)...)
+	case []any:
+		if items, ok := s["items"]; ok {
+			for i, item := range v {
+				errs = append(errs, validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+
+		errs = append(errs, validateSchemaBounds(path, "items", float64(len(v)), s["minItems"], s["maxItems"])...)
+	case string:
+		errs = append(errs, validateSchemaBounds(path, "length", float64(utf8.RuneCountInString(v)), s["minLength"], s["maxLength"])...)
+
+		if pattern, ok := s["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				errs = append(errs, fmt.Sprintf("%s: %q does not match %s", path, v, pattern))
+			}
+		}
+	case float64:
+		errs = append(errs, validateSchemaBounds(path, "value", v, s["minimum"], s["maximum"])...)
+	}
+
+	return errs
+}
+
+func validateSchemaObject(s map[string]any, document map[string]any, path string) []string {
+	var errs []string
+
+	if required, ok := s["required"].([]any); ok {
+		for _, key := range required {
+			if _, exists := document[fmt.Sprint(key)]; !exists {
+				errs = append(errs, fmt.Sprintf("%s.%v: is required", path, key))
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]any)
+
+	keys := make([]string, 0, len(document))
+	for key := range document {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if property, ok := properties[key]; ok {
+			errs = append(errs, validateSchema(property, document[key], path+"."+key)...)
+		} else if additional, ok := s["additionalProperties"]; ok {
+			errs = append(errs, validateSchema(additional, document[key], path+"."+key)...)
+		}
+	}
+
+	return errs
+}
+
+func validateSchemaBounds(path, name string, value float64, min, max any) []string {
+	var errs []string
+
+	if m, ok := min.(float64); ok && value < m {
+		errs = append(errs, fmt.Sprintf("%s: %s %v is less than %v", path, name, value, m))
+	}
+
+	if m, ok := max.(float64); ok && value > m {
+		errs = append(errs, fmt.Sprintf("%s: %s %v is greater than %v", path, name, value, m))
+	}
+
+	return errs
+}
+
+// checkSchema reports the unsupported keywords and types and the invalid patterns of a schema
+func checkSchema(schema any, path string) []error {
+	s, ok := schema.(map[string]any)
+
+	if !ok {
+		return nil
+	}
+
+	var errs []error
+
+	for _, keyword := range maputil.SortedKeys(s) {
+		if !slices.Contains(knownSchemaKeywords, keyword) {
+			errs = append(errs, fmt.Errorf("%s.%s is not supported", path, keyword))
+		}
+	}
+
+	for _, t := range schemaTypes(s["type"]) {
+		if !slices.Contains(knownSchemaTypes, t) {
+			errs = append(errs, fmt.Errorf("%s.type %q is not supported", path, t))
+		}
+	}
+
+	if pattern, ok := s["pattern"].(string); ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("%s.pattern: %w", path, err))
+		}
+	}
+
+	if properties, ok := s["properties"].(map[string]any); ok {
+		for key, property := range properties {
+			errs = append(errs, checkSchema(property, path+".properties."+key)...)
+		}
+	}
+
+	errs = append(errs, checkSchema(s["items"], path+".items")...)
+	errs = append(errs, checkSchema(s["additionalProperties"], path+".additionalProperties")...)
+
+	return errs
+}
+
+func schemaTypes(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			types = append(types, fmt.Sprint(item))
+		}
+		return types
+	}
+
+	return nil
+}
+
+func schemaTypeOf(t string, v any) bool {
+	if t == "integer" {
+		f, ok := v.(float64)
+		return ok && f == float64(int64(f))
+	}
+
+	return jsonTypeOf(v) == t
+}
+
+func jsonTypeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+func joinTypes(types []string) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+
+	return fmt.Sprint(types)
+}
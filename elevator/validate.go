@@ -0,0 +1,429 @@
+package elevator
+
+import (
+	"errors"
+	"fmt"
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/jsonpath"
+	"slices"
+)
+
+var (
+	knownBodyTypes     = []string{"", "json", "form", "multipart-form", BodyTypeGraphQL, BodyTypeJSONRPC, BodyTypeSOAP}
+	knownAuthTypes     = []string{"", "bearer"}
+	knownDynamicTypes  = []string{"", DynamicTypeString, DynamicTypeBoolean, DynamicTypeInteger, DynamicTypeNumber, DynamicTypeObject, DynamicTypeArray}
+	knownDynamicFormat = []string{"", DynamicFormatEmail, DynamicFormatUUID, DynamicFormatDateTime}
+	knownPagination    = []string{"", PaginationPage, PaginationOffset, PaginationCursor, PaginationLink}
+	knownStreams       = []string{"", StreamSSE}
+	knownProtocols     = []string{"", ProtocolHTTP, ProtocolWebSocket}
+	knownMethods       = []room.HTTPMethod{"", room.GET, room.POST, room.PUT, room.PATCH, room.DELETE, room.HEAD}
+)
+
+// Validate checks the config for mistakes that would otherwise show up only when a request is executed
+func (e Elevator) Validate() error {
+	var errs []error
+
+	for roomKey, r := range e.Config.Flat.Rooms {
+		path := "rooms." + roomKey
+
+		if r.Connection.BaseURL == "" {
+			errs = append(errs, fmt.Errorf("%s.connection.baseUrl is required", path))
+		}
+
+		if r.Connection.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("%s.connection.timeout can not be negative", path))
+		}
+
+		if r.Connection.Logging.MaxBodySize < 0 {
+			errs = append(errs, fmt.Errorf("%s.connection.logging.maxBodySize can not be negative", path))
+		}
+
+		if err := room.ValidateRedactedFields(r.Connection.Logging.RedactedFields...); err != nil {
+			errs = append(errs, fmt.Errorf("%s.connection.logging.redactFields: %w", path, err))
+		}
+
+		errs = append(errs, validateCircuitBreaker(path+".connection.circuitBreaker", r.Connection.CircuitBreaker)...)
+		errs = append(errs, validateCompression(path+".connection.compression", r.Connection.Compression)...)
+
+		if !slices.Contains(knownProtocols, r.Connection.Protocol) {
+			errs = append(errs, fmt.Errorf("%s.connection.protocol %q is not supported", path, r.Connection.Protocol))
+		}
+
+		if r.Connection.Protocol == ProtocolWebSocket {
+			errs = append(errs, validateWebSocket(path, r)...)
+		}
+
+		if !slices.Contains(knownAuthTypes, r.Connection.Auth.Type) {
+			errs = append(errs, fmt.Errorf("%s.connection.auth.type %q is not supported", path, r.Connection.Auth.Type))
+		}
+
+		if r.Connection.Auth.Type == "bearer" {
+			if r.Connection.Auth.AccessTokenKey == "" {
+				errs = append(errs, fmt.Errorf("%s.connection.auth.accessTokenKey is required", path))
+			}
+
+			errs = append(errs, validateRequest(path+".connection.auth.request", r.Connection.Auth.Request)...)
+		}
+
+		for requestKey, req := range r.Requests {
+			errs = append(errs, validateRequest(path+".requests."+requestKey, req)...)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateWebSocket(path string, r Room) []error {
+	var errs []error
+
+	w := r.Connection.WebSocket
+
+	if w.PingInterval < 0 || w.ReconnectDelay < 0 || w.MaxReconnectDelay < 0 {
+		errs = append(errs, fmt.Errorf("%s.connection.webSocket durations can not be negative", path))
+	}
+
+	if _, err := jsonpath.Parse(w.MessageTypePath); w.MessageTypePath != "" && err != nil {
+		errs = append(errs, fmt.Errorf("%s.connection.webSocket: %w", path, err))
+	}
+
+	for requestKey, req := range r.Requests {
+		if (req.Method != "" && req.Method != string(room.GET)) || req.Body.Type != "" || req.Body.Content != nil {
+			errs = append(errs, fmt.Errorf("%s.requests.%s of a websocket room can not declare a method or body", path, requestKey))
+		}
+
+		if req.Stream != "" || req.Poll != nil || req.Pagination != nil || req.Hedge != nil {
+			errs = append(errs, fmt.Errorf("%s.requests.%s of a websocket room can not declare stream, poll, pagination or hedge", path, requestKey))
+		}
+	}
+
+	return errs
+}
+
+func validateCompression(path string, c Compression) []error {
+	var errs []error
+
+	if c.Encoding != "" && !slices.Contains(room.Encodings, c.Encoding) {
+		errs = append(errs, fmt.Errorf("%s.encoding %q is not supported, use one of %v", path, c.Encoding, room.Encodings))
+	}
+
+	if c.Threshold < 0 {
+		errs = append(errs, fmt.Errorf("%s.threshold can not be negative", path))
+	}
+
+	for _, encoding := range c.AcceptEncoding {
+		if !slices.Contains(room.Encodings, encoding) && encoding != "identity" {
+			errs = append(errs, fmt.Errorf("%s.acceptEncoding %q is not supported, use one of %v", path, encoding, room.Encodings))
+		}
+	}
+
+	return errs
+}
+
+func validateCircuitBreaker(path string, c CircuitBreaker) []error {
+	var errs []error
+
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{"consecutiveFailures", c.ConsecutiveFailures},
+		{"window", c.Window},
+		{"minRequests", c.MinRequests},
+		{"openTimeout", c.OpenTimeout},
+		{"halfOpenRequests", c.HalfOpenRequests},
+	} {
+		if field.value < 0 {
+			errs = append(errs, fmt.Errorf("%s.%s can not be negative", path, field.name))
+		}
+	}
+
+	if c.FailureRate < 0 || c.FailureRate > 1 {
+		errs = append(errs, fmt.Errorf("%s.failureRate must be between 0 and 1", path))
+	}
+
+	if c.FailureRate > 0 && c.Window == 0 {
+		errs = append(errs, fmt.Errorf("%s.window is required with failureRate", path))
+	}
+
+	return errs
+}
+
+func validateRequest(path string, req Request) []error {
+	var errs []error
+
+	if !slices.Contains(knownMethods, room.HTTPMethod(req.Method)) {
+		errs = append(errs, fmt.Errorf("%s.method %q is not supported", path, req.Method))
+	}
+
+	if !slices.Contains(knownBodyTypes, req.Body.Type) {
+		errs = append(errs, fmt.Errorf("%s.body.type %q is not supported", path, req.Body.Type))
+	}
+
+	for _, dynamicContent := range req.Body.DynamicContent {
+		errs = append(errs, validateDynamicContent(path+".body.dynamicContent."+dynamicContent.Key, dynamicContent, true)...)
+	}
+
+	if req.Body.Type == BodyTypeGraphQL {
+		errs = append(errs, validateGraphQL(path+".body", req)...)
+	} else if req.Body.Query != "" || req.Body.QueryFile != "" || req.Body.OperationName != "" {
+		errs = append(errs, fmt.Errorf("%s.body.query, queryFile and operationName need the graphql body type", path))
+	}
+
+	if req.Body.Type == BodyTypeJSONRPC {
+		errs = append(errs, validateJSONRPC(path+".body", req)...)
+	} else if req.Body.Method != "" || req.Body.PositionalParams {
+		errs = append(errs, fmt.Errorf("%s.body.method and positionalParams need the jsonrpc body type", path))
+	}
+
+	if req.Body.Type == BodyTypeSOAP {
+		errs = append(errs, validateSOAP(path+".body", req)...)
+	} else if req.Body.Operation != "" || req.Body.Namespace != "" || req.Body.SOAPAction != "" || req.Body.SOAPVersion != "" || req.Body.Security != nil {
+		errs = append(errs, fmt.Errorf("%s.body.operation, namespace, soapAction, soapVersion and security need the soap body type", path))
+	}
+
+	for _, dynamicContent := range req.Query.DynamicContent {
+		errs = append(errs, validateDynamicContent(path+".query.dynamicContent."+dynamicContent.Key, dynamicContent, true)...)
+	}
+
+	if req.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("%s.timeout can not be negative", path))
+	}
+
+	for _, status := range req.ExpectedStatus {
+		if status < 100 || status > 599 {
+			errs = append(errs, fmt.Errorf("%s.expectedStatus %d is not a valid status code", path, status))
+		}
+	}
+
+	if req.Expect != nil {
+		errs = append(errs, validateExpect(path+".expect", *req.Expect)...)
+	}
+
+	if req.AttemptTimeout < 0 {
+		errs = append(errs, fmt.Errorf("%s.attemptTimeout can not be negative", path))
+	}
+
+	if req.Hedge != nil {
+		errs = append(errs, validateHedge(path+".hedge", req)...)
+	}
+
+	if req.Pagination != nil {
+		errs = append(errs, validatePagination(path+".pagination", *req.Pagination)...)
+	}
+
+	if req.Poll != nil {
+		errs = append(errs, validatePoll(path+".poll", *req.Poll)...)
+	}
+
+	if !slices.Contains(knownStreams, req.Stream) {
+		errs = append(errs, fmt.Errorf("%s.stream %q is not supported", path, req.Stream))
+	} else if req.Stream != "" && (req.Poll != nil || req.Pagination != nil || req.Hedge != nil) {
+		errs = append(errs, fmt.Errorf("%s.stream can not be combined with poll, pagination or hedge", path))
+	}
+
+	return errs
+}
+
+func validateGraphQL(path string, req Request) []error {
+	var errs []error
+
+	if req.Body.Query == "" && req.Body.PersistedQueryHash == "" {
+		if req.Body.QueryFile != "" {
+			errs = append(errs, fmt.Errorf("%s.queryFile %s is not read, configs with query files are loaded by Load", path, req.Body.QueryFile))
+		} else {
+			errs = append(errs, fmt.Errorf("%s.query, queryFile or persistedQueryHash is required with graphql body", path))
+		}
+	}
+
+	if _, isMap := req.Body.Content.(map[string]any); req.Body.Content != nil && !isMap {
+		errs = append(errs, fmt.Errorf("%s.content of a graphql body must be a map of variables", path))
+	}
+
+	if req.Method != "" && req.Method != string(room.POST) {
+		errs = append(errs, fmt.Errorf("%s: graphql requests are sent with POST, it is the default method of graphql bodies", path))
+	}
+
+	return errs
+}
+
+func validateSOAP(path string, req Request) []error {
+	var errs []error
+
+	if req.Body.Operation == "" {
+		errs = append(errs, fmt.Errorf("%s.operation is required with soap body", path))
+	}
+
+	if _, isMap := req.Body.Content.(map[string]any); req.Body.Content != nil && !isMap {
+		errs = append(errs, fmt.Errorf("%s.content of a soap body must be a map of params", path))
+	}
+
+	if !slices.Contains([]string{"", string(room.SOAP11), string(room.SOAP12)}, req.Body.SOAPVersion) {
+		errs = append(errs, fmt.Errorf("%s.soapVersion %q is not supported, use %s or %s", path, req.Body.SOAPVersion, room.SOAP11, room.SOAP12))
+	}
+
+	if req.Body.Security != nil && req.Body.Security.Username == "" {
+		errs = append(errs, fmt.Errorf("%s.security.username is required", path))
+	}
+
+	if req.Method != "" && req.Method != string(room.POST) {
+		errs = append(errs, fmt.Errorf("%s: soap requests are sent with POST, it is the default method of soap bodies", path))
+	}
+
+	return errs
+}
+
+func validateJSONRPC(path string, req Request) []error {
+	var errs []error
+
+	if req.Body.Method == "" {
+		errs = append(errs, fmt.Errorf("%s.method is required with jsonrpc body", path))
+	}
+
+	switch req.Body.Content.(type) {
+	case nil, []any:
+	case map[string]any:
+		if req.Body.PositionalParams {
+			errs = append(errs, fmt.Errorf("%s.content must be a list with positionalParams", path))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s.content of a jsonrpc body must be a map or a list of params", path))
+	}
+
+	if req.Method != "" && req.Method != string(room.POST) {
+		errs = append(errs, fmt.Errorf("%s: jsonrpc requests are sent with POST, it is the default method of jsonrpc bodies", path))
+	}
+
+	return errs
+}
+
+func validatePagination(path string, p Pagination) []error {
+	var errs []error
+
+	if !slices.Contains(knownPagination, p.Type) {
+		errs = append(errs, fmt.Errorf("%s.type %q is not supported", path, p.Type))
+	}
+
+	if p.Type == PaginationOffset && p.Size <= 0 {
+		errs = append(errs, fmt.Errorf("%s.size is required with offset pagination", path))
+	}
+
+	if p.Type == PaginationCursor && p.CursorPath == "" {
+		errs = append(errs, fmt.Errorf("%s.cursorPath is required with cursor pagination", path))
+	}
+
+	for _, expression := range []string{p.CursorPath, p.ItemsPath} {
+		if _, err := jsonpath.Parse(expression); expression != "" && err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{"size", p.Size},
+		{"maxPages", p.MaxPages},
+		{"maxItems", p.MaxItems},
+		{"concurrency", p.Concurrency},
+	} {
+		if field.value < 0 {
+			errs = append(errs, fmt.Errorf("%s.%s can not be negative", path, field.name))
+		}
+	}
+
+	return errs
+}
+
+func validateHedge(path string, req Request) []error {
+	var errs []error
+
+	if method := room.HTTPMethod(req.Method); method != "" && method != room.GET && method != room.HEAD {
+		errs = append(errs, fmt.Errorf("%s is supported for GET and HEAD requests only, %s is not idempotent", path, method))
+	}
+
+	if req.Hedge.Delay <= 0 {
+		errs = append(errs, fmt.Errorf("%s.delay is required", path))
+	}
+
+	if req.Hedge.Percentile < 0 || req.Hedge.Percentile >= 1 {
+		errs = append(errs, fmt.Errorf("%s.percentile must be between 0 and 1", path))
+	}
+
+	if req.Hedge.MaxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("%s.maxAttempts can not be negative", path))
+	}
+
+	return errs
+}
+
+func validateExpect(path string, expect Expect) []error {
+	var errs []error
+
+	for _, status := range expect.Status {
+		if status < 100 || status > 599 {
+			errs = append(errs, fmt.Errorf("%s.status %d is not a valid status code", path, status))
+		}
+	}
+
+	for expression := range expect.JSONPath {
+		if _, err := jsonpath.Parse(expression); err != nil {
+			errs = append(errs, fmt.Errorf("%s.jsonPath: %w", path, err))
+		}
+	}
+
+	if schema, err := normalizeJson(expect.JSONSchema); err != nil {
+		errs = append(errs, fmt.Errorf("%s.jsonSchema is not json: %w", path, err))
+	} else {
+		errs = append(errs, checkSchema(schema, path+".jsonSchema")...)
+	}
+
+	return errs
+}
+
+func validateDynamicContent(path string, dynamicContent DynamicContent, keyed bool) []error {
+	var errs []error
+
+	if keyed && dynamicContent.Key == "" {
+		errs = append(errs, fmt.Errorf("%s.key is required", path))
+	}
+
+	if !slices.Contains(knownDynamicTypes, dynamicContent.Type) {
+		errs = append(errs, fmt.Errorf("%s.type %q is not supported", path, dynamicContent.Type))
+	}
+
+	if !slices.Contains(knownDynamicFormat, dynamicContent.Format) {
+		errs = append(errs, fmt.Errorf("%s.format %q is not supported", path, dynamicContent.Format))
+	}
+
+	for _, property := range dynamicContent.Properties {
+		errs = append(errs, validateDynamicContent(path+"."+property.Key, property, true)...)
+	}
+
+	if dynamicContent.Items != nil {
+		errs = append(errs, validateDynamicContent(path+"[]", *dynamicContent.Items, false)...)
+	}
+
+	return errs
+}
+
+func validatePoll(path string, p Poll) []error {
+	var errs []error
+
+	for _, expression := range []string{p.StatusUrlPath, p.ResultUrlPath} {
+		if _, err := jsonpath.Parse(expression); expression != "" && err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	for _, condition := range []string{p.DoneWhen, p.FailedWhen} {
+		if _, err := jsonpath.ParseCondition(condition); condition != "" && err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	if p.Interval < 0 || p.MaxInterval < 0 || p.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("%s durations can not be negative", path))
+	}
+
+	return errs
+}
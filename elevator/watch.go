@@ -0,0 +1,212 @@
+package elevator
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultWatchInterval = time.Second
+
+// ReloadEvent is emitted by the Watcher after every reload attempt,
+// Err is set when the changed config is rejected and the previous one is kept
+type ReloadEvent struct {
+	Paths []string
+	Err   error
+	At    time.Time
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher keeps an ElevatorEngine in sync with its config files
+type Watcher struct {
+	loadOpts  []OptionLoad
+	interval  time.Duration
+	engine    *ElevatorEngine
+	events    chan ReloadEvent
+	stamps    map[string]fileStamp
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type OptionWatcher func(watcher *Watcher)
+
+// WithWatchInterval sets how often the config files are checked for changes, it must be positive
+func WithWatchInterval(interval time.Duration) OptionWatcher {
+	return func(watcher *Watcher) {
+		watcher.interval = interval
+	}
+}
+
+// WithWatchLoadOptions loads the config with the options after the watched path, like WithProfile or further files,
+// they are applied on every reload. Only the files on the local file system are watched.
+func WithWatchLoadOptions(opts ...OptionLoad) OptionWatcher {
+	return func(watcher *Watcher) {
+		watcher.loadOpts = append(watcher.loadOpts, opts...)
+	}
+}
+
+// WithWatchEngineOptions applies the options to the engine kept by the watcher
+func WithWatchEngineOptions(opts ...OptionEngine) OptionWatcher {
+	return func(watcher *Watcher) {
+		for _, opt := range opts {
+			opt(watcher.engine)
+		}
+	}
+}
+
+// Watch loads the integration yml and reloads the engine whenever the file or one of its included files changes.
+// A changed config is validated before it is applied, the previous config is kept if it is invalid.
+// The path may be empty when the config is given by WithWatchLoadOptions.
+func Watch(path string, opts ...OptionWatcher) (*Watcher, error) {
+	w := &Watcher{
+		interval: defaultWatchInterval,
+		engine:   &ElevatorEngine{},
+		events:   make(chan ReloadEvent, 8),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if path != "" {
+		w.loadOpts = []OptionLoad{WithFiles(path)}
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.interval <= 0 {
+		return nil, errors.New("elevator: watch interval must be positive")
+	}
+
+	elevator, _, err := loadValidElevator(w.loadOpts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	w.engine.elevator = elevator
+	w.stamps = stampFiles(elevator.Paths())
+
+	w.engine.WarmUp()
+
+	go w.run()
+
+	return w, nil
+}
+
+// Engine returns the engine which is kept up to date by the watcher
+func (w *Watcher) Engine() *ElevatorEngine {
+	return w.engine
+}
+
+// Events returns the reload events, events are dropped when the channel is not consumed
+func (w *Watcher) Events() <-chan ReloadEvent {
+	return w.events
+}
+
+// Close stops watching and closes the events channel, the engine keeps working with the last config
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+		close(w.events)
+	})
+
+	return nil
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if w.changed() {
+				w.reload()
+			}
+		}
+	}
+}
+
+func (w *Watcher) changed() bool {
+	for path, stamp := range w.stamps {
+		if stampFile(path) != stamp {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *Watcher) reload() {
+	elevator, attempted, err := loadValidElevator(w.loadOpts)
+
+	event := ReloadEvent{Err: err, At: time.Now()}
+
+	if err != nil {
+		// the stamps are refreshed anyway, so a broken file is reported once instead of on every tick,
+		// the files read by the failed attempt are watched too, like an include added by the broken edit
+		event.Paths = append([]string(nil), w.engine.config().Paths()...)
+
+		for _, path := range attempted {
+			event.Paths = appendUnique(event.Paths, path)
+		}
+
+		w.stamps = stampFiles(event.Paths)
+	} else {
+		w.engine.reload(elevator)
+		event.Paths = elevator.Paths()
+		w.stamps = stampFiles(event.Paths)
+	}
+
+	select {
+	case w.events <- event:
+	default:
+	}
+}
+
+// loadValidElevator loads and validates the config, the files read are returned even if it is rejected
+func loadValidElevator(opts []OptionLoad) (Elevator, []string, error) {
+	elevator, paths, err := load(opts...)
+
+	if err != nil {
+		return Elevator{}, paths, err
+	}
+
+	if err = elevator.Validate(); err != nil {
+		return Elevator{}, paths, err
+	}
+
+	return elevator, paths, nil
+}
+
+func stampFiles(paths []string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp, len(paths))
+
+	for _, path := range paths {
+		stamps[path] = stampFile(path)
+	}
+
+	return stamps
+}
+
+func stampFile(path string) fileStamp {
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return fileStamp{}
+	}
+
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
@@ -0,0 +1,195 @@
+package elevator
+
+import (
+	"fmt"
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/store"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const watchTestYml = `flat:
+  rooms:
+    todoRoom:
+      connection:
+        baseUrl: %s
+      requests:
+        getTodo:
+          method: "GET"
+          path: "todos/1"
+`
+
+func writeWatchTestYml(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitReloadEvent(t *testing.T, watcher *Watcher) ReloadEvent {
+	t.Helper()
+
+	select {
+	case event := <-watcher.Events():
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("Watcher did not emit a reload event")
+	}
+
+	return ReloadEvent{}
+}
+
+func TestWatch(t *testing.T) {
+	first := newEchoServer()
+	defer first.Close()
+
+	second := newEchoServer()
+	defer second.Close()
+
+	path := filepath.Join(t.TempDir(), "integration.yml")
+	writeWatchTestYml(t, path, fmt.Sprintf(watchTestYml, first.URL))
+
+	watcher, err := Watch(path, WithWatchInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Watch() returned error: %v", err)
+	}
+	defer watcher.Close()
+
+	writeWatchTestYml(t, path, fmt.Sprintf(watchTestYml, second.URL)+"\n")
+
+	if event := waitReloadEvent(t, watcher); event.Err != nil {
+		t.Fatalf("Watcher reload failed: %v", event.Err)
+	}
+
+	response, err := watcher.Engine().Execute("todoRoom", "getTodo")
+	if err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+
+	if response.Request.URI.Authority() != strings.TrimPrefix(second.URL, "http://") {
+		t.Errorf("Execute() requested %s, expected the reloaded base url %s", response.Request.URI.String(), second.URL)
+	}
+
+	writeWatchTestYml(t, path, "flat:\n  rooms:\n    todoRoom:\n      connection:\n        baseUrl: \"\"\n")
+
+	if event := waitReloadEvent(t, watcher); event.Err == nil {
+		t.Fatal("Watcher accepted an invalid config")
+	}
+
+	if _, err = watcher.Engine().Execute("todoRoom", "getTodo"); err != nil {
+		t.Errorf("Execute() returned error after an invalid reload: %v", err)
+	}
+}
+
+func TestWatch_Reload(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "integration.yml")
+	writeWatchTestYml(t, path, fmt.Sprintf(watchTestYml, server.URL))
+
+	if _, err := Watch(path, WithWatchInterval(0)); err == nil {
+		t.Error("Watch() accepted a zero interval")
+	}
+
+	watcher, err := Watch(path, WithWatchInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Watch() returned error: %v", err)
+	}
+	defer watcher.Close()
+
+	watcher.engine.PutQuery("todoRoom", "getTodo", room.NewQuery(store.NewMapStore(map[string]any{"page": "2"})))
+
+	// the override is applied to the rebuilt template
+	writeWatchTestYml(t, path, fmt.Sprintf(watchTestYml, server.URL)+"\n")
+
+	if event := waitReloadEvent(t, watcher); event.Err != nil {
+		t.Fatalf("Watcher reload failed: %v", event.Err)
+	}
+
+	response, err := watcher.Engine().Execute("todoRoom", "getTodo")
+	if err != nil || !strings.Contains(response.Request.URI.String(), "page=2") {
+		t.Errorf("Execute() requested %s, %v", response.Request.URI.String(), err)
+	}
+
+	// a request removed by a reload is an error instead of a panic
+	writeWatchTestYml(t, path, strings.Replace(fmt.Sprintf(watchTestYml, server.URL), "getTodo", "listTodos", 1))
+
+	if event := waitReloadEvent(t, watcher); event.Err != nil {
+		t.Fatalf("Watcher reload failed: %v", event.Err)
+	}
+
+	if _, err = watcher.Engine().Execute("todoRoom", "getTodo"); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("Execute() = %v", err)
+	}
+}
+
+func TestWatch_LoadOptions(t *testing.T) {
+	base := newEchoServer()
+	defer base.Close()
+
+	staging := newEchoServer()
+	defer staging.Close()
+
+	profile := "profiles:\n  staging:\n    flat:\n      rooms:\n        todoRoom:\n          connection:\n            baseUrl: " + staging.URL + "\n"
+
+	path := filepath.Join(t.TempDir(), "integration.yml")
+	writeWatchTestYml(t, path, fmt.Sprintf(watchTestYml, base.URL)+profile)
+
+	watcher, err := Watch(path, WithWatchInterval(10*time.Millisecond), WithWatchLoadOptions(WithProfile("staging")))
+	if err != nil {
+		t.Fatalf("Watch() returned error: %v", err)
+	}
+	defer watcher.Close()
+
+	// the profile is applied again by the reload
+	writeWatchTestYml(t, path, fmt.Sprintf(watchTestYml, base.URL)+"\n"+profile)
+
+	if event := waitReloadEvent(t, watcher); event.Err != nil {
+		t.Fatalf("Watcher reload failed: %v", event.Err)
+	}
+
+	response, err := watcher.Engine().Execute("todoRoom", "getTodo")
+	if err != nil || response.Request.URI.Authority() != strings.TrimPrefix(staging.URL, "http://") {
+		t.Errorf("Execute() requested %s, %v, expected the staging base url %s", response.Request.URI.String(), err, staging.URL)
+	}
+}
+
+func TestWatch_IncludeOfFailedReload(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "integration.yml")
+	included := filepath.Join(dir, "rooms.yml")
+
+	writeWatchTestYml(t, path, fmt.Sprintf(watchTestYml, server.URL))
+
+	watcher, err := Watch(path, WithWatchInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Watch() returned error: %v", err)
+	}
+	defer watcher.Close()
+
+	// the edit adds an include which is broken, only the included file is fixed afterwards
+	writeWatchTestYml(t, included, "flat: [")
+	writeWatchTestYml(t, path, "include:\n  - rooms.yml\n"+fmt.Sprintf(watchTestYml, server.URL))
+
+	if event := waitReloadEvent(t, watcher); event.Err == nil {
+		t.Fatal("Watcher accepted a broken include")
+	}
+
+	writeWatchTestYml(t, included, "flat:\n  rooms:\n    todoRoom:\n      requests:\n        listTodos:\n          path: \"todos\"\n")
+
+	if event := waitReloadEvent(t, watcher); event.Err != nil {
+		t.Fatalf("Watcher reload failed: %v", event.Err)
+	}
+
+	if _, err = watcher.Engine().Execute("todoRoom", "listTodos"); err != nil {
+		t.Errorf("Execute() returned error for the included request: %v", err)
+	}
+}
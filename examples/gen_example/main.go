@@ -0,0 +1,44 @@
+package main
+
+//go:generate go run github.com/WEG-Technology/room/cmd/room gen -f integration.yml -package todoclient -out todoclient/todoclient_gen.go
+
+import (
+	"fmt"
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/elevator"
+	"github.com/WEG-Technology/room/examples/gen_example/todoclient"
+)
+
+type Todo struct {
+	Id        int    `json:"id"`
+	Todo      string `json:"todo"`
+	Completed bool   `json:"completed"`
+	UserId    int    `json:"userId"`
+}
+
+func main() {
+	el := elevator.NewElevator("examples/gen_example/integration.yml")
+	engine := elevator.NewElevatorEngine(el)
+	engine.WarmUp()
+
+	client := todoclient.New(engine)
+
+	todo, err := room.DecodeDTO[Todo](client.TodoRoom.GetTodo("1"))
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Todo: ", todo)
+
+	response, err := client.TodoRoom.AddTodo(todoclient.TodoRoomAddTodoRequest{
+		Todo:   "lorem",
+		UserId: 1,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response Body: ", response.ResponseBody())
+}
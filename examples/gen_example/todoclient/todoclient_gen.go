@@ -0,0 +1,123 @@
+// Code generated by room gen from integration.yml. DO NOT EDIT.
+
+package todoclient
+
+import (
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/elevator"
+)
+
+// Client executes the requests of integration.yml, room.DecodeDTO decodes the responses into typed DTOs
+type Client struct {
+	TodoRoom *TodoRoom
+	UserRoom *UserRoom
+	engine   elevator.IOptionExecutor
+}
+
+// New creates a client on top of a warmed up engine of integration.yml
+func New(engine elevator.IOptionExecutor) *Client {
+	return &Client{
+		TodoRoom: &TodoRoom{engine: engine},
+		UserRoom: &UserRoom{engine: engine},
+		engine:   engine,
+	}
+}
+
+// ConcurrentAdd executes the requests with the concurrent key add
+func (c *Client) ConcurrentAdd(appliedRooms ...string) map[string]room.Response {
+	return c.engine.ExecuteConcurrent("add", appliedRooms...)
+}
+
+// TodoRoom executes the requests of the room todoRoom
+type TodoRoom struct {
+	engine elevator.IOptionExecutor
+}
+
+// AddTodo sends POST todos/add
+func (r *TodoRoom) AddTodo(payload TodoRoomAddTodoRequest, opts ...room.OptionRequest) (room.Response, error) {
+	return r.engine.DynamicExecuteWith("todoRoom", "addTodo", payload, opts...)
+}
+
+// GetTodo sends GET todos/{id}
+func (r *TodoRoom) GetTodo(id string, opts ...room.OptionRequest) (room.Response, error) {
+	opts = append([]room.OptionRequest{room.WithPathParams(map[string]string{
+		"id": id,
+	})}, opts...)
+
+	return r.engine.ExecuteWith("todoRoom", "getTodo", opts...)
+}
+
+// ListTodos sends GET todos
+func (r *TodoRoom) ListTodos(payload TodoRoomListTodosRequest, opts ...room.OptionRequest) (room.Response, error) {
+	return r.engine.DynamicExecuteWith("todoRoom", "listTodos", payload, opts...)
+}
+
+// UserRoom executes the requests of the room userRoom
+type UserRoom struct {
+	engine elevator.IOptionExecutor
+}
+
+// AddUser sends POST users/add
+func (r *UserRoom) AddUser(payload UserRoomAddUserRequest, opts ...room.OptionRequest) (room.Response, error) {
+	return r.engine.DynamicExecuteWith("userRoom", "addUser", payload, opts...)
+}
+
+// GetUser sends GET users/{id}
+func (r *UserRoom) GetUser(id string, opts ...room.OptionRequest) (room.Response, error) {
+	opts = append([]room.OptionRequest{room.WithPathParams(map[string]string{
+		"id": id,
+	})}, opts...)
+
+	return r.engine.ExecuteWith("userRoom", "getUser", opts...)
+}
+
+// UpdateUser sends PUT users/{id}
+func (r *UserRoom) UpdateUser(id string, payload UserRoomUpdateUserRequest, opts ...room.OptionRequest) (room.Response, error) {
+	opts = append([]room.OptionRequest{room.WithPathParams(map[string]string{
+		"id": id,
+	})}, opts...)
+
+	return r.engine.DynamicExecuteWith("userRoom", "updateUser", payload, opts...)
+}
+
+// TodoRoomAddTodoRequest is the payload of todoRoom.addTodo
+type TodoRoomAddTodoRequest struct {
+	Todo      string                           `json:"todo"`
+	Completed *bool                            `json:"completed,omitempty"` // default false
+	UserId    int                              `json:"userId"`
+	Priority  *string                          `json:"priority,omitempty"` // one of low, high
+	Tags      []TodoRoomAddTodoRequestTagsItem `json:"tags,omitempty"`
+}
+
+// TodoRoomAddTodoRequestTagsItem is the tags item object of TodoRoomAddTodoRequest
+type TodoRoomAddTodoRequestTagsItem struct {
+	Name string `json:"name"`
+}
+
+// TodoRoomListTodosRequest is the payload of todoRoom.listTodos
+type TodoRoomListTodosRequest struct {
+	Limit *int `json:"limit,omitempty"` // default 10
+}
+
+// UserRoomAddUserRequest is the payload of userRoom.addUser
+type UserRoomAddUserRequest struct {
+	Name    string                        `json:"name"`
+	Address UserRoomAddUserRequestAddress `json:"address"`
+}
+
+// UserRoomAddUserRequestAddress is the address object of UserRoomAddUserRequest
+type UserRoomAddUserRequestAddress struct {
+	City string `json:"city"`
+}
+
+// UserRoomUpdateUserRequest is the payload of userRoom.updateUser
+type UserRoomUpdateUserRequest struct {
+	Email   string                            `json:"email"` // format email
+	Address *UserRoomUpdateUserRequestAddress `json:"address,omitempty"`
+}
+
+// UserRoomUpdateUserRequestAddress is the address object of UserRoomUpdateUserRequest
+type UserRoomUpdateUserRequestAddress struct {
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code"`
+}
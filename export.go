@@ -0,0 +1,413 @@
+package room
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const harVersion = "1.2"
+
+type exportConfig struct {
+	redactor *Redactor
+}
+
+type OptionExport func(config *exportConfig)
+
+// WithExportRedactor redacts the exported headers, query params and bodies, nothing is redacted by default
+func WithExportRedactor(redactor Redactor) OptionExport {
+	return func(config *exportConfig) {
+		config.redactor = &redactor
+	}
+}
+
+func newExportConfig(opts []OptionExport) exportConfig {
+	config := exportConfig{}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return config
+}
+
+func (c exportConfig) header(header IHeader) http.Header {
+	h := http.Header{}
+
+	if header != nil {
+		header.Properties().Each(func(key string, value any) {
+			h.Set(key, fmt.Sprint(value))
+		})
+	}
+
+	if c.redactor != nil {
+		return c.redactor.Header(h)
+	}
+
+	return h
+}
+
+func (c exportConfig) url(uri URI) string {
+	u, err := url.Parse(uri.String())
+
+	if err != nil || c.redactor == nil {
+		return uri.String()
+	}
+
+	return c.redactor.URL(u)
+}
+
+func (c exportConfig) body(contentType string, data []byte) []byte {
+	if c.redactor == nil {
+		return data
+	}
+
+	return c.redactor.Body(contentType, data)
+}
+
+// HAR is an HTTP Archive 1.2 document
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARTimings are in milliseconds, -1 means the phase does not apply
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// ToHAR exports the request and the response as an HTTP Archive with a single entry
+func (r Response) ToHAR(opts ...OptionExport) HAR {
+	return HAR{Log: HARLog{
+		Version: harVersion,
+		Creator: HARCreator{Name: "room"},
+		Entries: []HAREntry{r.ToHAREntry(opts...)},
+	}}
+}
+
+// ToHAREntry exports the request and the response as an HTTP Archive entry
+func (r Response) ToHAREntry(opts ...OptionExport) HAREntry {
+	config := newExportConfig(opts)
+
+	requestHeader := config.header(r.Request.Header)
+	responseHeader := config.header(r.Header)
+
+	// the request is sent over the connection of the response
+	httpVersion := r.Proto
+	if httpVersion == "" {
+		httpVersion = "HTTP/1.1"
+	}
+
+	request := HARRequest{
+		Method:      r.Request.Method,
+		URL:         config.url(r.Request.URI),
+		HTTPVersion: httpVersion,
+		Cookies:     []HARNameValue{},
+		Headers:     harHeaders(requestHeader),
+		QueryString: harQuery(r.Request.URI.Query(), config),
+		HeadersSize: -1,
+		BodySize:    len(r.Request.Data),
+	}
+
+	if len(r.Request.Data) > 0 {
+		contentType := requestHeader.Get(headerKeyContentType)
+
+		request.PostData = &HARPostData{
+			MimeType: contentType,
+			Text:     string(config.body(contentType, r.Request.Data)),
+		}
+	}
+
+	responseContentType := responseHeader.Get(headerKeyContentType)
+
+	response := HARResponse{
+		Status:      r.StatusCode,
+		StatusText:  http.StatusText(r.StatusCode),
+		HTTPVersion: httpVersion,
+		Cookies:     []HARNameValue{},
+		Headers:     harHeaders(responseHeader),
+		Content: HARContent{
+			Size:     len(r.Data),
+			MimeType: responseContentType,
+			Text:     string(config.body(responseContentType, r.Data)),
+		},
+		HeadersSize: -1,
+		BodySize:    len(r.Data),
+	}
+
+	startedAt := r.Timing.StartedAt
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+
+	// the connect phase of HAR includes the ssl phase
+	connect := r.Timing.Connect + r.Timing.TLSHandshake
+
+	wait := r.Timing.TimeToFirstByte - r.Timing.DNS - connect
+	if wait < 0 {
+		wait = 0
+	}
+
+	return HAREntry{
+		StartedDateTime: startedAt.Format(time.RFC3339Nano),
+		Time:            milliseconds(r.Timing.Total),
+		Request:         request,
+		Response:        response,
+		Timings: HARTimings{
+			Blocked: -1,
+			DNS:     harPhase(r.Timing.DNS),
+			Connect: harPhase(connect),
+			Send:    0,
+			Wait:    milliseconds(wait),
+			Receive: milliseconds(r.Timing.BodyRead),
+			SSL:     harPhase(r.Timing.TLSHandshake),
+		},
+	}
+}
+
+// ToCurl returns the curl command which reproduces the request
+func (r RequestDTO) ToCurl(opts ...OptionExport) string {
+	config := newExportConfig(opts)
+
+	header := config.header(r.Header)
+
+	parts := []string{"curl"}
+
+	if r.Method != "" && (r.Method != GET.String() || len(r.Data) > 0) {
+		parts = append(parts, "-X", r.Method)
+	}
+
+	parts = append(parts, shellQuote(config.url(r.URI)))
+
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range header[key] {
+			parts = append(parts, "-H", shellQuote(key+": "+value))
+		}
+	}
+
+	if len(r.Data) > 0 {
+		parts = append(parts, "--data-raw", shellQuote(string(config.body(header.Get(headerKeyContentType), r.Data))))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// ToCurl returns the curl command of the request as it would be sent, without the connector's base url and header,
+// see Connector.ToCurl
+func (r *Request) ToCurl(opts ...OptionExport) string {
+	return newResponse(r.Clone().request(context.Background())).Request.ToCurl(opts...)
+}
+
+// ToCurl returns the curl command of the request as the connector would send it, with its base url and header.
+// The body is not compressed to keep the command readable.
+func (c *Connector) ToCurl(request *Request, opts ...OptionExport) string {
+	r := c.prepare(request)
+	r.compression = compression{}
+
+	return r.ToCurl(opts...)
+}
+
+// HARRecorder writes an HTTP Archive file for every request of the connectors it is attached to
+type HARRecorder struct {
+	dir  string
+	opts []OptionExport
+	mu   sync.Mutex
+	seq  int
+	err  error
+}
+
+// NewHARRecorder creates a recorder which writes the archives into dir,
+// they are redacted by NewRedactor(nil, nil) unless WithExportRedactor is given
+func NewHARRecorder(dir string, opts ...OptionExport) *HARRecorder {
+	return &HARRecorder{dir: dir, opts: append([]OptionExport{WithExportRedactor(NewRedactor(nil, nil))}, opts...)}
+}
+
+// WithHARRecorder records every request of the connector by the recorder
+func WithHARRecorder(recorder *HARRecorder) OptionConnector {
+	return WithResponseHook(func(response Response, _ error) {
+		_ = recorder.Record(response)
+	})
+}
+
+// Record writes the archive of the response, the first failure is also kept for Err
+func (h *HARRecorder) Record(response Response) error {
+	h.mu.Lock()
+	h.seq++
+	seq := h.seq
+	h.mu.Unlock()
+
+	err := h.write(response, seq)
+
+	if err != nil {
+		h.mu.Lock()
+		if h.err == nil {
+			h.err = err
+		}
+		h.mu.Unlock()
+	}
+
+	return err
+}
+
+// Err returns the first error of writing an archive
+func (h *HARRecorder) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.err
+}
+
+func (h *HARRecorder) write(response Response, seq int) error {
+	data, err := json.MarshalIndent(response.ToHAR(h.opts...), "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(h.dir, 0o755); err != nil {
+		return err
+	}
+
+	startedAt := response.Timing.StartedAt
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+
+	name := fmt.Sprintf("%s-%04d-%s.har", startedAt.Format("20060102T150405.000"), seq, strings.ToLower(response.Request.Method))
+
+	return os.WriteFile(filepath.Join(h.dir, name), data, 0o644)
+}
+
+func harHeaders(header http.Header) []HARNameValue {
+	values := []HARNameValue{}
+
+	for key, headerValues := range header {
+		for _, value := range headerValues {
+			values = append(values, HARNameValue{Name: key, Value: value})
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+
+	return values
+}
+
+func harQuery(rawQuery string, config exportConfig) []HARNameValue {
+	values := []HARNameValue{}
+
+	query, err := url.ParseQuery(rawQuery)
+
+	if err != nil {
+		return values
+	}
+
+	if config.redactor != nil {
+		query = config.redactor.values(query)
+	}
+
+	for key, queryValues := range query {
+		for _, value := range queryValues {
+			values = append(values, HARNameValue{Name: key, Value: value})
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+
+	return values
+}
+
+// harPhase returns -1 for the connection phases that did not happen, like on a reused connection
+func harPhase(d time.Duration) float64 {
+	if d == 0 {
+		return -1
+	}
+
+	return milliseconds(d)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
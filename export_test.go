@@ -0,0 +1,136 @@
+package room
+
+import (
+	"encoding/json"
+	"github.com/WEG-Technology/room/segment"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRequest_ToCurl(t *testing.T) {
+	request := NewRequest("https://example.com/todos/add?token=abc",
+		WithMethod(POST),
+		WithHeader(NewHeader().Add("Authorization", "Bearer secret")),
+		WithBody(NewJsonBodyParser(map[string]any{"todo": "it's done", "password": "secret"})),
+	)
+
+	expected := `curl -X POST 'https://example.com/todos/add?token=abc' -H 'Authorization: Bearer secret' -H 'Content-Type: application/json' --data-raw '{"password":"secret","todo":"it'\''s done"}` + "\n'"
+	if curl := request.ToCurl(); curl != expected {
+		t.Errorf("ToCurl() returned %s, expected %s", curl, expected)
+	}
+
+	expected = `curl -X POST 'https://example.com/todos/add?token=%5BREDACTED%5D' -H 'Authorization: [REDACTED]' -H 'Content-Type: application/json' --data-raw '{"password":"[REDACTED]","todo":"it'\''s done"}'`
+	if curl := request.ToCurl(WithExportRedactor(NewRedactor(nil, nil))); curl != expected {
+		t.Errorf("ToCurl() returned %s, expected %s", curl, expected)
+	}
+
+	if curl := NewRequest("https://example.com/todos").ToCurl(); curl != "curl 'https://example.com/todos'" {
+		t.Errorf("ToCurl() returned %s for a GET request", curl)
+	}
+}
+
+func TestConnector_ToCurl(t *testing.T) {
+	connector := NewConnector("https://api.example.com/v1", WithHeaderConnector(NewHeader().Add("X-Partner", "room")), WithRequestCompression(EncodingGzip, 0))
+
+	expected := `curl -X POST 'https://api.example.com/v1/todos' -H 'Content-Type: application/json' -H 'X-Partner: room' --data-raw '{"todo":"lorem"}` + "\n'"
+	if curl := connector.ToCurl(NewRequest("todos", WithMethod(POST), WithBody(NewJsonBodyParser(map[string]any{"todo": "lorem"})))); curl != expected {
+		t.Errorf("ToCurl() returned %s, expected %s", curl, expected)
+	}
+}
+
+func TestResponse_ToHAREntry(t *testing.T) {
+	response := Response{
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/2.0",
+		Timing: segment.Timing{
+			DNS:             time.Millisecond,
+			Connect:         2 * time.Millisecond,
+			TLSHandshake:    3 * time.Millisecond,
+			TimeToFirstByte: 10 * time.Millisecond,
+		},
+	}
+
+	entry := response.ToHAREntry()
+
+	// the connect phase includes the ssl phase, the wait is what is left of the time to first byte
+	if entry.Timings.Connect != 5 || entry.Timings.SSL != 3 || entry.Timings.Wait != 4 {
+		t.Errorf("ToHAREntry() timings = %+v", entry.Timings)
+	}
+
+	if entry.Request.HTTPVersion != "HTTP/2.0" || entry.Response.HTTPVersion != "HTTP/2.0" {
+		t.Errorf("ToHAREntry() versions = %s, %s", entry.Request.HTTPVersion, entry.Response.HTTPVersion)
+	}
+}
+
+func TestWithHARRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder := NewHARRecorder(dir)
+
+	connector := NewConnector(server.URL, WithHARRecorder(recorder), WithHeaderConnector(NewHeader().Add("Authorization", "Bearer secret")))
+
+	if _, err := connector.Do(NewRequest("todos/add?limit=1", WithMethod(POST), WithBody(NewJsonBodyParser(map[string]any{"todo": "lorem"})))); err != nil {
+		t.Fatalf("Do() returned error: %v", err)
+	}
+
+	if recorder.Err() != nil {
+		t.Fatalf("HARRecorder returned error: %v", recorder.Err())
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("HARRecorder wrote %d files, expected 1", len(files))
+	}
+
+	data, _ := os.ReadFile(dir + "/" + files[0].Name())
+
+	var har HAR
+	if err := json.Unmarshal(data, &har); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := har.Log.Entries[0]
+
+	if har.Log.Version != "1.2" || entry.Request.Method != "POST" || entry.Response.Status != http.StatusCreated {
+		t.Errorf("HARRecorder wrote an unexpected archive: %s", data)
+	}
+
+	if entry.Request.PostData == nil || entry.Response.Content.Text != `{"id":1}` {
+		t.Errorf("HARRecorder did not keep the bodies: %s", data)
+	}
+
+	if len(entry.Request.QueryString) != 1 || entry.Request.QueryString[0].Name != "limit" {
+		t.Errorf("HARRecorder did not keep the query: %s", data)
+	}
+
+	if len(entry.Request.Headers) == 0 || entry.Response.HTTPVersion != "HTTP/1.1" {
+		t.Errorf("HARRecorder did not keep the headers or the version: %s", data)
+	}
+
+	if authorization := http.Header(harHeaderMap(entry.Request.Headers)).Get("Authorization"); authorization != RedactedValue {
+		t.Errorf("HARRecorder wrote the Authorization header %q", authorization)
+	}
+
+	if entry.Time <= 0 {
+		t.Errorf("HARRecorder did not keep the timing: %s", data)
+	}
+}
+
+func harHeaderMap(values []HARNameValue) map[string][]string {
+	header := map[string][]string{}
+
+	for _, value := range values {
+		header[value.Name] = append(header[value.Name], value.Value)
+	}
+
+	return header
+}
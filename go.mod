@@ -3,7 +3,9 @@ module github.com/WEG-Technology/room
 go 1.21.1
 
 require (
+	github.com/andybalholm/brotli v1.1.0
 	github.com/google/go-querystring v1.1.0
+	github.com/klauspost/compress v1.17.11
 	github.com/stretchr/testify v1.8.4
 	gopkg.in/yaml.v3 v3.0.1
 )
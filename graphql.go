@@ -0,0 +1,211 @@
+package room
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GraphQLBody is the json body of a GraphQL operation
+type GraphQLBody struct {
+	query         string
+	variables     any
+	operationName string
+	// persistedHash is the sha256 hash of the query, the query is sent with it only after the server asks for it
+	persistedHash string
+	sendQuery     bool
+}
+
+type OptionGraphQL func(body *GraphQLBody)
+
+// WithPersistedQuery sends the sha256 hash of the query instead of the query, an empty hash is computed from the query.
+// SendGraphQL sends the query with its hash when the server does not know the hash yet.
+func WithPersistedQuery(hash string) OptionGraphQL {
+	return func(body *GraphQLBody) {
+		if hash == "" {
+			sum := sha256.Sum256([]byte(body.query))
+			hash = hex.EncodeToString(sum[:])
+		}
+
+		body.persistedHash = hash
+	}
+}
+
+func NewGraphQLBodyParser(query string, variables any, operationName string, opts ...OptionGraphQL) IBodyParser {
+	body := &GraphQLBody{query: query, variables: variables, operationName: operationName}
+
+	for _, opt := range opts {
+		opt(body)
+	}
+
+	return body
+}
+
+func (b *GraphQLBody) Parse() *bytes.Buffer {
+	payload := map[string]any{}
+
+	if b.persistedHash == "" || b.sendQuery {
+		payload["query"] = b.query
+	}
+
+	if b.persistedHash != "" {
+		payload["extensions"] = map[string]any{
+			"persistedQuery": map[string]any{"version": 1, "sha256Hash": b.persistedHash},
+		}
+	}
+
+	if b.variables != nil {
+		payload["variables"] = b.variables
+	}
+
+	if b.operationName != "" {
+		payload["operationName"] = b.operationName
+	}
+
+	return NewJsonBodyParser(payload).Parse()
+}
+
+func (b *GraphQLBody) ContentType() string {
+	return headerValueApplicationJson
+}
+
+// GraphQLError is an error of a GraphQL response
+type GraphQLError struct {
+	Message    string            `json:"message"`
+	Locations  []GraphQLLocation `json:"locations,omitempty"`
+	Path       []any             `json:"path,omitempty"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
+
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (e GraphQLError) Error() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+
+	path := make([]string, len(e.Path))
+	for i, segment := range e.Path {
+		path[i] = fmt.Sprint(segment)
+	}
+
+	return fmt.Sprintf("%s (at %s)", e.Message, strings.Join(path, "."))
+}
+
+// GraphQLErrors are the errors of a GraphQL response, they are returned as an error even with status 200
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// persistedQueryNotFound reports whether the server asks for the query of a persisted hash
+func (e GraphQLErrors) persistedQueryNotFound() bool {
+	for _, err := range e {
+		if err.Message == "PersistedQueryNotFound" || err.Extensions["code"] == "PERSISTED_QUERY_NOT_FOUND" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GraphQLResponse splits the data and the errors of a GraphQL response, the data may be partial when there are errors
+type GraphQLResponse struct {
+	Data       json.RawMessage `json:"data"`
+	Errors     GraphQLErrors   `json:"errors,omitempty"`
+	Extensions map[string]any  `json:"extensions,omitempty"`
+}
+
+// ParseGraphQL splits the GraphQL response, it fails only when the response is not a GraphQL response
+func ParseGraphQL(response Response) (GraphQLResponse, error) {
+	var result GraphQLResponse
+
+	if err := json.Unmarshal(response.Data, &result); err != nil {
+		return result, fmt.Errorf("room: response is not a graphql response: %w", err)
+	}
+
+	return result, nil
+}
+
+// SendGraphQL sends the request and returns the GraphQLErrors of the response as the error, even with status 200.
+// A persisted query unknown to the server is sent again with its query.
+func SendGraphQL(doer Doer, request *Request) (Response, error) {
+	response, err := sendGraphQL(doer, request)
+
+	var errs GraphQLErrors
+
+	if body, isGraphQL := request.BodyParser.(*GraphQLBody); isGraphQL && body.persistedHash != "" && body.query != "" &&
+		errors.As(err, &errs) && errs.persistedQueryNotFound() {
+		withQuery := *body
+		withQuery.sendQuery = true
+
+		retry := request.Clone()
+		retry.BodyParser = &withQuery
+		retry.attempt = request.attempt + 1
+
+		return sendGraphQL(doer, retry)
+	}
+
+	return response, err
+}
+
+func sendGraphQL(doer Doer, request *Request) (Response, error) {
+	response, err := doer.Do(request)
+
+	if err != nil {
+		return response, err
+	}
+
+	result, err := ParseGraphQL(response)
+
+	if err != nil {
+		// a failed status without a graphql body, like a gateway error, is not a graphql error
+		return response, nil
+	}
+
+	if len(result.Errors) > 0 {
+		return response, result.Errors
+	}
+
+	return response, nil
+}
+
+// DecodeGraphQL decodes the data of the GraphQL response into T, the partial data is decoded along with the errors:
+//
+//	user, err := room.DecodeGraphQL[User](room.SendGraphQL(connector, request))
+func DecodeGraphQL[T any](response Response, err error) (T, error) {
+	var data T
+
+	var errs GraphQLErrors
+
+	if err != nil && !errors.As(err, &errs) {
+		return data, err
+	}
+
+	result, parseErr := ParseGraphQL(response)
+
+	if parseErr != nil {
+		return data, parseErr
+	}
+
+	if len(result.Data) > 0 && string(result.Data) != "null" {
+		if decodeErr := json.Unmarshal(result.Data, &data); decodeErr != nil {
+			return data, decodeErr
+		}
+	}
+
+	return data, err
+}
@@ -0,0 +1,85 @@
+package room
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGraphQLBody_Parse(t *testing.T) {
+	for name, tc := range map[string]struct {
+		parser IBodyParser
+		want   string
+	}{
+		"query": {
+			parser: NewGraphQLBodyParser("query User($id: ID!) { user(id: $id) { name } }", map[string]any{"id": "1"}, "User"),
+			want:   `{"operationName":"User","query":"query User($id: ID!) { user(id: $id) { name } }","variables":{"id":"1"}}`,
+		},
+		"persisted query": {
+			parser: NewGraphQLBodyParser("{ me { name } }", nil, "", WithPersistedQuery("")),
+			want:   `{"extensions":{"persistedQuery":{"sha256Hash":"b8d9506e34c83b0e53c2aa463624fcea354713bc38f95276e6f0bd893ffb5b88","version":1}}}`,
+		},
+		"persisted hash": {
+			parser: NewGraphQLBodyParser("", nil, "Me", WithPersistedQuery("abc")),
+			want:   `{"extensions":{"persistedQuery":{"sha256Hash":"abc","version":1}},"operationName":"Me"}`,
+		},
+	} {
+		if got := string(tc.parser.Parse().Bytes()); got != tc.want+"\n" {
+			t.Errorf("%s: Parse() = %s", name, got)
+		}
+
+		if tc.parser.ContentType() != "application/json" {
+			t.Errorf("%s: ContentType() = %s", name, tc.parser.ContentType())
+		}
+	}
+}
+
+type graphQLUser struct {
+	Name    string `json:"name"`
+	Friends []any  `json:"friends"`
+}
+
+func TestSendGraphQL(t *testing.T) {
+	var bodies []map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if body["query"] == nil {
+			_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"user":{"name":"Ada","friends":null}},"errors":[{"message":"friends are not available","path":["user","friends"]}]}`))
+	}))
+	defer server.Close()
+
+	request := NewRequest("graphql", WithMethod(POST), WithBody(NewGraphQLBodyParser("{ user { name friends } }", nil, "", WithPersistedQuery(""))))
+
+	response, err := SendGraphQL(NewConnector(server.URL), request)
+
+	var errs GraphQLErrors
+
+	if !errors.As(err, &errs) || len(errs) != 1 || err.Error() != "graphql: friends are not available (at user.friends)" {
+		t.Fatalf("SendGraphQL() error = %v", err)
+	}
+
+	// the persisted hash is sent first, then with the query the server asked for
+	if len(bodies) != 2 || bodies[1]["query"] == nil || bodies[1]["extensions"] == nil {
+		t.Errorf("bodies = %v", bodies)
+	}
+
+	data, err := DecodeGraphQL[struct {
+		User graphQLUser `json:"user"`
+	}](response, err)
+
+	if data.User.Name != "Ada" || !errors.As(err, &errs) {
+		t.Errorf("DecodeGraphQL() = %+v, %v", data, err)
+	}
+}
@@ -1,6 +1,9 @@
 package room
 
-import "github.com/WEG-Technology/room/store"
+import (
+	"fmt"
+	"github.com/WEG-Technology/room/store"
+)
 
 type IHeader interface {
 	Properties() store.IMap
@@ -27,7 +30,7 @@ func (h *Header) Get(key string) string {
 		return ""
 	}
 
-	return s.(string)
+	return fmt.Sprint(s)
 }
 
 func (h *Header) Merge(header IHeader) IHeader {
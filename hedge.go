@@ -0,0 +1,181 @@
+package room
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// hedgeSamples is the number of recent latencies the percentile delay is computed from
+	hedgeSamples = 100
+	// hedgeMinSamples is the number of latencies needed before the percentile replaces the fixed delay
+	hedgeMinSamples = 10
+)
+
+// Hedging sends a duplicate of a GET or HEAD request when no response arrives within the delay
+// and takes the first successful response, the slower attempts are cancelled.
+// A Hedging keeps the latencies of its requests, so it is shared by the executions of a request.
+type Hedging struct {
+	mu          sync.Mutex
+	delay       time.Duration
+	percentile  float64
+	maxAttempts int
+	latencies   []time.Duration
+	next        int
+}
+
+type OptionHedging func(hedging *Hedging)
+
+// WithHedgePercentile replaces the fixed delay with the percentile of the recent latencies, like 0.95 for p95,
+// the fixed delay is used until enough latencies are observed
+func WithHedgePercentile(percentile float64) OptionHedging {
+	return func(hedging *Hedging) {
+		hedging.percentile = percentile
+	}
+}
+
+// WithHedgeAttempts sets the maximum number of attempts including the first one, it is 2 by default
+func WithHedgeAttempts(n int) OptionHedging {
+	return func(hedging *Hedging) {
+		hedging.maxAttempts = n
+	}
+}
+
+func NewHedging(delay time.Duration, opts ...OptionHedging) *Hedging {
+	h := &Hedging{
+		delay:       delay,
+		maxAttempts: 2,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.maxAttempts < 1 {
+		h.maxAttempts = 1
+	}
+
+	return h
+}
+
+// WithHedging hedges the request, it applies to GET and HEAD requests only as other methods are not idempotent
+func WithHedging(hedging *Hedging) OptionRequest {
+	return func(request *Request) {
+		request.hedging = hedging
+	}
+}
+
+// WithAttemptTimeout limits every attempt of the request, the context builder still limits the whole request
+func WithAttemptTimeout(timeout time.Duration) OptionRequest {
+	return func(request *Request) {
+		request.attemptTimeout = timeout
+	}
+}
+
+// Delay returns the time to wait for a response before sending the next attempt
+func (h *Hedging) Delay() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.percentile <= 0 || len(h.latencies) < hedgeMinSamples {
+		return h.delay
+	}
+
+	sorted := slices.Clone(h.latencies)
+	slices.Sort(sorted)
+
+	index := int(h.percentile*float64(len(sorted))+0.5) - 1
+
+	return sorted[max(0, min(index, len(sorted)-1))]
+}
+
+func (h *Hedging) observe(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < hedgeSamples {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % hedgeSamples
+}
+
+func (h *Hedging) applies(method HTTPMethod) bool {
+	return h != nil && h.maxAttempts > 1 && (method == GET || method == HEAD)
+}
+
+type hedgeResult struct {
+	response Response
+	err      error
+	attempt  int
+	latency  time.Duration
+}
+
+// sendHedged starts another attempt every delay until one succeeds or the attempts run out,
+// the last failure is returned when all started attempts fail.
+// The latency of the first attempt is observed whichever attempt wins, up to the end of the request when it is cancelled.
+func (r *Request) sendHedged(ctx context.Context) (Response, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	started, observed := time.Now(), false
+
+	defer func() {
+		if !observed {
+			r.hedging.observe(time.Since(started))
+		}
+	}()
+
+	delay := r.hedging.Delay()
+	results := make(chan hedgeResult, r.hedging.maxAttempts)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	launched, finished := 0, 0
+
+	launch := func() {
+		attempt := r.Clone()
+		attempt.attempt = launched
+		launched++
+
+		go func() {
+			start := time.Now()
+			response, err := attempt.sendAttempt(ctx)
+			results <- hedgeResult{response: response, err: err, attempt: attempt.attempt, latency: time.Since(start)}
+		}()
+	}
+
+	launch()
+
+	for {
+		select {
+		case <-timer.C:
+			if launched < r.hedging.maxAttempts {
+				launch()
+				timer.Reset(delay)
+			}
+		case result := <-results:
+			finished++
+
+			if result.attempt == 0 {
+				r.hedging.observe(result.latency)
+				observed = true
+			}
+
+			// server failures may be answered by an attempt in flight, other outcomes end the request
+			if !isServerFailure(result.response, result.err) {
+				return result.response, result.err
+			}
+
+			// hedging is not a retry, a failure is returned once no attempt is in flight
+			if finished == launched {
+				return result.response, result.err
+			}
+		}
+	}
+}
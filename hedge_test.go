@@ -0,0 +1,131 @@
+package room
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequest_Hedging(t *testing.T) {
+	var hits atomic.Int32
+	cancelled := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first attempt hangs until the hedged attempt wins and cancels it
+		if hits.Add(1) == 1 {
+			<-r.Context().Done()
+			close(cancelled)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var attempts []int
+
+	connector := NewConnector(server.URL, WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			info, _ := InfoFromContext(req.Context())
+
+			mu.Lock()
+			attempts = append(attempts, info.Attempt)
+			mu.Unlock()
+
+			return next.RoundTrip(req)
+		})
+	}))
+
+	hedging := NewHedging(20 * time.Millisecond)
+
+	response, err := connector.Do(NewRequest("todos", WithHedging(hedging)))
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("Do() = %d, %v", response.StatusCode, err)
+	}
+
+	// the latency of the slow first attempt is observed, not the one of the fast hedge
+	if hedging.mu.Lock(); len(hedging.latencies) != 1 || hedging.latencies[0] < 20*time.Millisecond {
+		t.Errorf("latencies = %v", hedging.latencies)
+	}
+	hedging.mu.Unlock()
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("the slow attempt was not cancelled")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(attempts) != 2 || attempts[0] != 0 || attempts[1] != 1 {
+		t.Errorf("attempts = %v, want [0 1]", attempts)
+	}
+}
+
+func TestRequest_HedgingSkipsPost(t *testing.T) {
+	var hits atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	_, err := NewConnector(server.URL).Do(NewRequest("todos", WithMethod(POST), WithHedging(NewHedging(time.Millisecond))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hits.Load() != 1 {
+		t.Errorf("server got %d requests, a POST must not be hedged", hits.Load())
+	}
+}
+
+func TestRequest_AttemptTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	start := time.Now()
+
+	_, err := NewConnector(server.URL).Do(NewRequest("todos", WithAttemptTimeout(20*time.Millisecond)))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Do() error = %v, want deadline exceeded", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Do() took %s, the attempt timeout was ignored", elapsed)
+	}
+}
+
+func TestHedging_PercentileDelay(t *testing.T) {
+	hedging := NewHedging(time.Second, WithHedgePercentile(0.9))
+
+	for i := 1; i < hedgeMinSamples; i++ {
+		hedging.observe(time.Duration(i) * time.Millisecond)
+	}
+
+	if hedging.Delay() != time.Second {
+		t.Errorf("Delay() = %s, want the fixed delay until enough latencies", hedging.Delay())
+	}
+
+	for i := hedgeMinSamples; i <= hedgeSamples+10; i++ {
+		hedging.observe(time.Duration(i) * time.Millisecond)
+	}
+
+	// the window keeps the last 100 latencies, 11ms to 110ms
+	if delay := hedging.Delay(); delay != 100*time.Millisecond {
+		t.Errorf("Delay() = %s, want p90 of the window 100ms", delay)
+	}
+}
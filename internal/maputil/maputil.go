@@ -0,0 +1,17 @@
+// Package maputil keeps the map helpers shared by the packages of the module.
+package maputil
+
+import "sort"
+
+// SortedKeys returns the keys of the map in ascending order, so maps are iterated in a stable order
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
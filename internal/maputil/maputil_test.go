@@ -0,0 +1,14 @@
+package maputil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortedKeys(t *testing.T) {
+	keys := SortedKeys(map[string]int{"b": 2, "c": 3, "a": 1})
+
+	if !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+		t.Errorf("SortedKeys() returned %v, expected [a b c]", keys)
+	}
+}
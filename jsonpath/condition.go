@@ -0,0 +1,83 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Condition is a path compared to a json literal like `$.status == "done"` or `$.progress != 100`,
+// a path alone matches when its first value is present and not null, false or an empty string
+type Condition struct {
+	raw      string
+	path     Path
+	operator string
+	value    any
+}
+
+// ParseCondition parses the condition, string literals can be quoted by double or single quotes
+func ParseCondition(expression string) (Condition, error) {
+	c := Condition{raw: expression}
+
+	left, right := expression, ""
+
+	for _, operator := range []string{"==", "!="} {
+		if before, after, found := strings.Cut(expression, operator); found {
+			left, right, c.operator = before, strings.TrimSpace(after), operator
+			break
+		}
+	}
+
+	path, err := Parse(strings.TrimSpace(left))
+
+	if err != nil {
+		return c, err
+	}
+
+	c.path = path
+
+	if c.operator == "" {
+		return c, nil
+	}
+
+	if len(right) >= 2 && strings.HasPrefix(right, "'") && strings.HasSuffix(right, "'") {
+		c.value = right[1 : len(right)-1]
+		return c, nil
+	}
+
+	if err = json.Unmarshal([]byte(right), &c.value); err != nil {
+		return c, fmt.Errorf("%w: %s, the value is not a json literal", ErrInvalidPath, expression)
+	}
+
+	return c, nil
+}
+
+// MustParseCondition is like ParseCondition but panics if the expression can not be parsed
+func MustParseCondition(expression string) Condition {
+	c, err := ParseCondition(expression)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+func (c Condition) String() string {
+	return c.raw
+}
+
+// Match evaluates the condition on a decoded json document
+func (c Condition) Match(document any) bool {
+	value, found := c.path.First(document)
+
+	switch c.operator {
+	case "==":
+		return found && reflect.DeepEqual(value, c.value)
+	case "!=":
+		return !found || !reflect.DeepEqual(value, c.value)
+	}
+
+	return found && value != nil && value != false && value != ""
+}
@@ -0,0 +1,305 @@
+// Package jsonpath evaluates a subset of JSONPath on decoded JSON documents.
+//
+// Supported syntax: `$` root, `.key` and `['key']` members, `[n]` indexes, `[*]` and `.*` wildcards
+// and `..key` recursive descent. Documents are the values produced by encoding/json, map[string]any and []any.
+// Conditions like `$.status == "done"` compare the first matched value to a json literal.
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidPath = errors.New("invalid jsonpath")
+
+type segmentKind int
+
+const (
+	memberSegment segmentKind = iota + 1
+	indexSegment
+	wildcardSegment
+	descendantSegment
+)
+
+type segment struct {
+	kind  segmentKind
+	key   string
+	index int
+}
+
+// Path is a parsed JSONPath expression
+type Path struct {
+	raw      string
+	segments []segment
+}
+
+// Parse parses the expression, a leading `$` is optional
+func Parse(expression string) (Path, error) {
+	p := Path{raw: expression}
+
+	rest := strings.TrimSpace(expression)
+	rest = strings.TrimPrefix(rest, "$")
+
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			key, remaining := readKey(rest[2:])
+
+			if key == "" {
+				return p, fmt.Errorf("%w: %s", ErrInvalidPath, expression)
+			}
+
+			p.segments = append(p.segments, segment{kind: descendantSegment, key: key})
+			rest = remaining
+		case strings.HasPrefix(rest, "."):
+			key, remaining := readKey(rest[1:])
+
+			switch key {
+			case "":
+				return p, fmt.Errorf("%w: %s", ErrInvalidPath, expression)
+			case "*":
+				p.segments = append(p.segments, segment{kind: wildcardSegment})
+			default:
+				p.segments = append(p.segments, segment{kind: memberSegment, key: key})
+			}
+
+			rest = remaining
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+
+			if end < 0 {
+				return p, fmt.Errorf("%w: %s", ErrInvalidPath, expression)
+			}
+
+			selector := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			switch {
+			case selector == "*":
+				p.segments = append(p.segments, segment{kind: wildcardSegment})
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				p.segments = append(p.segments, segment{kind: memberSegment, key: selector[1 : len(selector)-1]})
+			default:
+				index, err := strconv.Atoi(selector)
+
+				if err != nil {
+					return p, fmt.Errorf("%w: %s", ErrInvalidPath, expression)
+				}
+
+				p.segments = append(p.segments, segment{kind: indexSegment, index: index})
+			}
+		default:
+			// a path without a leading `$.` like `user.name`
+			key, remaining := readKey(rest)
+
+			if key == "" {
+				return p, fmt.Errorf("%w: %s", ErrInvalidPath, expression)
+			}
+
+			p.segments = append(p.segments, segment{kind: memberSegment, key: key})
+			rest = remaining
+		}
+	}
+
+	return p, nil
+}
+
+// MustParse is like Parse but panics if the expression can not be parsed
+func MustParse(expression string) Path {
+	p, err := Parse(expression)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func (p Path) String() string {
+	return p.raw
+}
+
+// Get returns all values matched by the path
+func (p Path) Get(document any) []any {
+	values := []any{document}
+
+	for _, s := range p.segments {
+		var next []any
+
+		for _, value := range values {
+			next = append(next, s.match(value)...)
+		}
+
+		values = next
+	}
+
+	return values
+}
+
+// First returns the first value matched by the path
+func (p Path) First(document any) (any, bool) {
+	values := p.Get(document)
+
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	return values[0], true
+}
+
+// Replace replaces every matched value in place by the result of fn, the root itself is never replaced
+func (p Path) Replace(document any, fn func(value any) any) {
+	if len(p.segments) == 0 {
+		return
+	}
+
+	parents := []any{document}
+
+	for _, s := range p.segments[:len(p.segments)-1] {
+		var next []any
+
+		for _, parent := range parents {
+			next = append(next, s.match(parent)...)
+		}
+
+		parents = next
+	}
+
+	last := p.segments[len(p.segments)-1]
+
+	for _, parent := range parents {
+		last.replace(parent, fn)
+	}
+}
+
+// Get parses the expression and returns the first matched value
+func Get(document any, expression string) (any, bool, error) {
+	p, err := Parse(expression)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, found := p.First(document)
+
+	return value, found, nil
+}
+
+func (s segment) match(value any) []any {
+	switch s.kind {
+	case memberSegment:
+		if m, ok := value.(map[string]any); ok {
+			if v, ok := m[s.key]; ok {
+				return []any{v}
+			}
+		}
+	case indexSegment:
+		if l, ok := value.([]any); ok {
+			index := s.index
+
+			if index < 0 {
+				index += len(l)
+			}
+
+			if index >= 0 && index < len(l) {
+				return []any{l[index]}
+			}
+		}
+	case wildcardSegment:
+		return children(value)
+	case descendantSegment:
+		var matches []any
+
+		walk(value, func(v any) {
+			if m, ok := v.(map[string]any); ok {
+				if found, ok := m[s.key]; ok {
+					matches = append(matches, found)
+				}
+			}
+		})
+
+		return matches
+	}
+
+	return nil
+}
+
+func (s segment) replace(parent any, fn func(value any) any) {
+	switch s.kind {
+	case memberSegment:
+		if m, ok := parent.(map[string]any); ok {
+			if v, ok := m[s.key]; ok {
+				m[s.key] = fn(v)
+			}
+		}
+	case indexSegment:
+		if l, ok := parent.([]any); ok {
+			index := s.index
+
+			if index < 0 {
+				index += len(l)
+			}
+
+			if index >= 0 && index < len(l) {
+				l[index] = fn(l[index])
+			}
+		}
+	case wildcardSegment:
+		switch v := parent.(type) {
+		case map[string]any:
+			for key, child := range v {
+				v[key] = fn(child)
+			}
+		case []any:
+			for i, child := range v {
+				v[i] = fn(child)
+			}
+		}
+	case descendantSegment:
+		walk(parent, func(v any) {
+			if m, ok := v.(map[string]any); ok {
+				if found, ok := m[s.key]; ok {
+					m[s.key] = fn(found)
+				}
+			}
+		})
+	}
+}
+
+func children(value any) []any {
+	switch v := value.(type) {
+	case map[string]any:
+		values := make([]any, 0, len(v))
+
+		for _, child := range v {
+			values = append(values, child)
+		}
+
+		return values
+	case []any:
+		return append([]any(nil), v...)
+	}
+
+	return nil
+}
+
+// walk calls fn for the value and all of its descendants
+func walk(value any, fn func(v any)) {
+	fn(value)
+
+	for _, child := range children(value) {
+		walk(child, fn)
+	}
+}
+
+func readKey(s string) (key string, rest string) {
+	end := strings.IndexAny(s, ".[")
+
+	if end < 0 {
+		return s, ""
+	}
+
+	return s[:end], s[end:]
+}
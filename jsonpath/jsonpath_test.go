@@ -0,0 +1,114 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testDocument = `{
+	"status": "done",
+	"user": {"name": "john", "token": "secret"},
+	"items": [{"id": 1, "token": "a"}, {"id": 2, "token": "b"}],
+	"meta": {"next": {"cursor": "abc"}}
+}`
+
+func decode(t *testing.T) any {
+	t.Helper()
+
+	var document any
+	if err := json.Unmarshal([]byte(testDocument), &document); err != nil {
+		t.Fatal(err)
+	}
+
+	return document
+}
+
+func TestPath_Get(t *testing.T) {
+	document := decode(t)
+
+	tests := map[string][]any{
+		"$.status":           {"done"},
+		"user.name":          {"john"},
+		"$['user']['name']":  {"john"},
+		"$.items[1].id":      {float64(2)},
+		"$.items[-1].id":     {float64(2)},
+		"$.items[*].id":      {float64(1), float64(2)},
+		"$.meta.next.cursor": {"abc"},
+		"$..cursor":          {"abc"},
+		"$.missing":          nil,
+		"$.items[5]":         nil,
+	}
+
+	for expression, expected := range tests {
+		values := MustParse(expression).Get(document)
+
+		if !reflect.DeepEqual(values, expected) {
+			t.Errorf("Get(%s) returned %v, expected %v", expression, values, expected)
+		}
+	}
+}
+
+func TestPath_Replace(t *testing.T) {
+	document := decode(t)
+
+	redact := func(any) any { return "***" }
+
+	MustParse("$..token").Replace(document, redact)
+	MustParse("$.user.name").Replace(document, redact)
+
+	for _, expression := range []string{"$.user.token", "$.items[0].token", "$.items[1].token", "$.user.name"} {
+		if value, _ := MustParse(expression).First(document); value != "***" {
+			t.Errorf("Replace() did not replace %s, got %v", expression, value)
+		}
+	}
+
+	if value, _ := MustParse("$.items[0].id").First(document); value != float64(1) {
+		t.Errorf("Replace() changed an unmatched value, got %v", value)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expression := range []string{"$.", "$..", "$.items[", "$.items[x]"} {
+		if _, err := Parse(expression); err == nil {
+			t.Errorf("Parse(%s) did not return an error", expression)
+		}
+	}
+}
+
+func TestCondition_Match(t *testing.T) {
+	document := decode(t)
+
+	tests := map[string]bool{
+		`$.status == "done"`:              true,
+		`$.status == 'done'`:              true,
+		`$.status != "done"`:              false,
+		`$.status == "failed"`:            false,
+		`$.items[1].id == 2`:              true,
+		`$.items[*].id == 1`:              true,
+		`$.missing != null`:               true,
+		`$.meta.next.cursor`:              true,
+		`$.missing`:                       false,
+		`$.user == {"name":"x"}`:          false,
+		`$.meta.next == {"cursor":"abc"}`: true,
+	}
+
+	for expression, want := range tests {
+		condition, err := ParseCondition(expression)
+		if err != nil {
+			t.Fatalf("ParseCondition(%q) = %v", expression, err)
+		}
+
+		if got := condition.Match(document); got != want {
+			t.Errorf("%s matched %v, want %v", expression, got, want)
+		}
+	}
+}
+
+func TestParseCondition_Invalid(t *testing.T) {
+	for _, expression := range []string{`$.status == done`, `$[ == "done"`} {
+		if _, err := ParseCondition(expression); err == nil {
+			t.Errorf("ParseCondition(%q) returned no error", expression)
+		}
+	}
+}
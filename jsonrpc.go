@@ -0,0 +1,327 @@
+package room
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+const jsonRPCVersion = "2.0"
+
+// JSONRPCError is the error object of a JSON-RPC response
+type JSONRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("jsonrpc: %d %s", e.Code, e.Message)
+}
+
+type jsonRPCRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+	ID      *int64 `json:"id,omitempty"`
+}
+
+type jsonRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// JSONRPCBody is the envelope of a JSON-RPC call, every Parse numbers the call with the next id of the body starting with 1.
+// The ids only correlate a response with its call, so a recorded call is sent with the same id again.
+type JSONRPCBody struct {
+	method       string
+	params       any
+	notification bool
+	ids          *atomic.Int64
+}
+
+type OptionJSONRPC func(body *JSONRPCBody)
+
+// AsNotification sends the call without an id, the server does not answer notifications
+func AsNotification() OptionJSONRPC {
+	return func(body *JSONRPCBody) {
+		body.notification = true
+	}
+}
+
+// NewJSONRPCBodyParser creates the envelope of a call, params are a struct or map for named params and a slice for positional ones
+func NewJSONRPCBodyParser(method string, params any, opts ...OptionJSONRPC) IBodyParser {
+	body := &JSONRPCBody{method: method, params: params, ids: new(atomic.Int64)}
+
+	for _, opt := range opts {
+		opt(body)
+	}
+
+	return body
+}
+
+func (b *JSONRPCBody) Parse() *bytes.Buffer {
+	return NewJsonBodyParser(b.envelope()).Parse()
+}
+
+func (b *JSONRPCBody) ContentType() string {
+	return headerValueApplicationJson
+}
+
+func (b *JSONRPCBody) envelope() jsonRPCRequest {
+	envelope := jsonRPCRequest{JSONRPC: jsonRPCVersion, Method: b.method, Params: b.params}
+
+	if !b.notification {
+		id := b.ids.Add(1)
+		envelope.ID = &id
+	}
+
+	return envelope
+}
+
+// SendJSONRPC sends a JSON-RPC call and returns the error object of the response as a *JSONRPCError, even with status 200.
+// The id of the response must match the id of the call.
+func SendJSONRPC(doer Doer, request *Request) (Response, error) {
+	response, err := doer.Do(request)
+
+	var statusErr UnexpectedStatusError
+
+	// an unexpected status may still carry the error object of the call
+	if err != nil && !errors.As(err, &statusErr) {
+		return response, err
+	}
+
+	var sent jsonRPCRequest
+
+	if json.Unmarshal(response.Request.Data, &sent) == nil && sent.ID == nil {
+		return response, err
+	}
+
+	var result jsonRPCResponse
+
+	if json.Unmarshal(response.Data, &result) != nil || result.JSONRPC != jsonRPCVersion {
+		if err != nil {
+			return response, err
+		}
+
+		return response, errors.New("room: response is not a json-rpc response")
+	}
+
+	if result.Error != nil {
+		return response, result.Error
+	}
+
+	if sent.ID != nil && !sameID(result.ID, *sent.ID) {
+		return response, fmt.Errorf("room: json-rpc response id %s does not match the call id %d", result.ID, *sent.ID)
+	}
+
+	return response, err
+}
+
+// DecodeJSONRPC decodes the result of the JSON-RPC response into T:
+//
+//	balance, err := room.DecodeJSONRPC[string](room.SendJSONRPC(connector, request))
+func DecodeJSONRPC[T any](response Response, err error) (T, error) {
+	var v T
+
+	if err != nil {
+		return v, err
+	}
+
+	var result jsonRPCResponse
+
+	if err = json.Unmarshal(response.Data, &result); err != nil {
+		return v, err
+	}
+
+	if len(result.Result) > 0 {
+		err = json.Unmarshal(result.Result, &v)
+	}
+
+	return v, err
+}
+
+func sameID(raw json.RawMessage, id int64) bool {
+	var number json.Number
+
+	if json.Unmarshal(raw, &number) != nil {
+		var text string
+		if json.Unmarshal(raw, &text) != nil {
+			return false
+		}
+
+		number = json.Number(text)
+	}
+
+	parsed, err := strconv.ParseInt(number.String(), 10, 64)
+
+	return err == nil && parsed == id
+}
+
+// JSONRPCClient calls the methods of a JSON-RPC endpoint, the request is the template of the calls like its path and headers.
+// The calls of a client are numbered from 1.
+type JSONRPCClient struct {
+	doer    Doer
+	request *Request
+	ids     atomic.Int64
+}
+
+// NewJSONRPCClient creates a client of the endpoint at the path, the doer is a *Connector or a room adapted by DoerFunc
+func NewJSONRPCClient(doer Doer, path string, opts ...OptionRequest) *JSONRPCClient {
+	return &JSONRPCClient{
+		doer:    doer,
+		request: NewRequest(path, append([]OptionRequest{WithMethod(POST)}, opts...)...),
+	}
+}
+
+// Call calls the method and decodes its result into result, which may be nil to drop it
+func (c *JSONRPCClient) Call(method string, params any, result any) error {
+	response, err := SendJSONRPC(c.doer, c.call(c.body(method, params, false)))
+
+	if err != nil || result == nil {
+		return err
+	}
+
+	var envelope jsonRPCResponse
+
+	if err = json.Unmarshal(response.Data, &envelope); err != nil || len(envelope.Result) == 0 {
+		return err
+	}
+
+	return json.Unmarshal(envelope.Result, result)
+}
+
+// Notify sends the method as a notification, its response is not read
+func (c *JSONRPCClient) Notify(method string, params any) error {
+	_, err := c.doer.Do(c.call(c.body(method, params, true)))
+
+	return err
+}
+
+// CallJSONRPC calls the method and decodes its result into T
+func CallJSONRPC[T any](client *JSONRPCClient, method string, params any) (T, error) {
+	var result T
+
+	err := client.Call(method, params, &result)
+
+	return result, err
+}
+
+func (c *JSONRPCClient) body(method string, params any, notification bool) *JSONRPCBody {
+	return &JSONRPCBody{method: method, params: params, notification: notification, ids: &c.ids}
+}
+
+func (c *JSONRPCClient) call(body IBodyParser) *Request {
+	r := c.request.Clone()
+	r.BodyParser = body
+
+	return r
+}
+
+// Batch collects calls which are sent in a single request by Send
+func (c *JSONRPCClient) Batch() *JSONRPCBatch {
+	return &JSONRPCBatch{client: c}
+}
+
+// JSONRPCBatch is a batch of calls, the results are read from the calls after Send
+type JSONRPCBatch struct {
+	client   *JSONRPCClient
+	requests []jsonRPCRequest
+	calls    []*JSONRPCCall
+}
+
+// JSONRPCCall is a call of a batch
+type JSONRPCCall struct {
+	id     int64
+	result json.RawMessage
+	err    error
+}
+
+// Call adds a call to the batch
+func (b *JSONRPCBatch) Call(method string, params any) *JSONRPCCall {
+	envelope := b.client.body(method, params, false).envelope()
+	call := &JSONRPCCall{id: *envelope.ID, err: errors.New("room: the batch is not sent")}
+
+	b.requests = append(b.requests, envelope)
+	b.calls = append(b.calls, call)
+
+	return call
+}
+
+// Notify adds a notification to the batch
+func (b *JSONRPCBatch) Notify(method string, params any) {
+	b.requests = append(b.requests, b.client.body(method, params, true).envelope())
+}
+
+// Send sends the calls in one request and correlates the results by their ids,
+// it fails when the batch is not sent while the calls hold their own errors
+func (b *JSONRPCBatch) Send() error {
+	if len(b.requests) == 0 {
+		return errors.New("room: json-rpc batch is empty")
+	}
+
+	response, err := b.client.doer.Do(b.client.call(NewJsonBodyParser(b.requests)))
+
+	if err != nil {
+		return err
+	}
+
+	// a batch of notifications is not answered
+	if len(b.calls) == 0 {
+		return nil
+	}
+
+	var results []jsonRPCResponse
+
+	if err = json.Unmarshal(response.Data, &results); err != nil {
+		// the server answers a single error object when it rejects the whole batch
+		var single jsonRPCResponse
+
+		if json.Unmarshal(response.Data, &single) != nil || single.Error == nil {
+			return fmt.Errorf("room: response is not a json-rpc batch response: %w", err)
+		}
+
+		for _, call := range b.calls {
+			call.err = single.Error
+		}
+
+		return nil
+	}
+
+	for _, call := range b.calls {
+		call.err = fmt.Errorf("room: json-rpc batch has no response for the call %d", call.id)
+
+		for _, result := range results {
+			if !sameID(result.ID, call.id) {
+				continue
+			}
+
+			call.result, call.err = result.Result, nil
+
+			if result.Error != nil {
+				call.err = result.Error
+			}
+		}
+	}
+
+	return nil
+}
+
+// Err returns the error object of the call, or why the call has no result
+func (c *JSONRPCCall) Err() error {
+	return c.err
+}
+
+// Decode decodes the result of the call into v
+func (c *JSONRPCCall) Decode(v any) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	return json.Unmarshal(c.result, v)
+}
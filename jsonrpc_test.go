@@ -0,0 +1,154 @@
+package room
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// newJSONRPCServer serves `add` with positional params, any other method fails with -32601
+func newJSONRPCServer(t *testing.T, idOffset int) *httptest.Server {
+	t.Helper()
+
+	answer := func(request map[string]any) map[string]any {
+		response := map[string]any{"jsonrpc": "2.0", "id": request["id"]}
+
+		if id, ok := request["id"].(float64); ok {
+			response["id"] = id + float64(idOffset)
+		}
+
+		if request["method"] != "add" {
+			response["error"] = map[string]any{"code": -32601, "message": "Method not found"}
+			return response
+		}
+
+		sum := 0.0
+		for _, param := range request["params"].([]any) {
+			sum += param.(float64)
+		}
+
+		response["result"] = sum
+
+		return response
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if batch, isBatch := body.([]any); isBatch {
+			var responses []map[string]any
+
+			// the responses of a batch may come in any order
+			slices.Reverse(batch)
+
+			for _, request := range batch {
+				if request.(map[string]any)["id"] != nil {
+					responses = append(responses, answer(request.(map[string]any)))
+				}
+			}
+
+			_ = json.NewEncoder(w).Encode(responses)
+			return
+		}
+
+		if body.(map[string]any)["id"] == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(answer(body.(map[string]any)))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestJSONRPCClient_Call(t *testing.T) {
+	client := NewJSONRPCClient(NewConnector(newJSONRPCServer(t, 0).URL), "rpc")
+
+	sum, err := CallJSONRPC[int](client, "add", []int{1, 2, 3})
+
+	if err != nil || sum != 6 {
+		t.Errorf("CallJSONRPC() = %d, %v", sum, err)
+	}
+
+	var rpcErr *JSONRPCError
+
+	if err = client.Call("sub", []int{1}, nil); !errors.As(err, &rpcErr) || rpcErr.Code != -32601 {
+		t.Errorf("Call() error = %v", err)
+	}
+
+	if err = client.Notify("log", map[string]string{"level": "info"}); err != nil {
+		t.Errorf("Notify() = %v", err)
+	}
+
+	// a response of another call is not taken as the result
+	client = NewJSONRPCClient(NewConnector(newJSONRPCServer(t, 1).URL), "rpc")
+
+	if _, err = CallJSONRPC[int](client, "add", []int{1}); err == nil {
+		t.Error("expected an error for the mismatched id")
+	}
+}
+
+func TestJSONRPCBody_IDs(t *testing.T) {
+	ids := func(body IBodyParser) []float64 {
+		var numbered []float64
+
+		for i := 0; i < 2; i++ {
+			var envelope map[string]any
+			_ = json.Unmarshal(body.Parse().Bytes(), &envelope)
+			numbered = append(numbered, envelope["id"].(float64))
+		}
+
+		return numbered
+	}
+
+	// every body and client numbers its calls from 1, so they are sent alike when they are replayed
+	first, second := ids(NewJSONRPCBodyParser("add", []int{1})), ids(NewJSONRPCBodyParser("add", []int{1}))
+
+	if !slices.Equal(first, []float64{1, 2}) || !slices.Equal(second, first) {
+		t.Errorf("ids = %v, %v", first, second)
+	}
+
+	client := NewJSONRPCClient(NewConnector("rpc"), "rpc")
+
+	if calls := ids(client.body("add", nil, false)); !slices.Equal(calls, []float64{1, 2}) || client.Batch().Call("add", nil).id != 3 {
+		t.Errorf("client ids = %v", calls)
+	}
+}
+
+func TestJSONRPCBatch(t *testing.T) {
+	client := NewJSONRPCClient(NewConnector(newJSONRPCServer(t, 0).URL), "rpc")
+
+	batch := client.Batch()
+	first := batch.Call("add", []int{1, 2})
+	batch.Notify("log", nil)
+	second := batch.Call("add", []int{10, 20})
+	failed := batch.Call("mul", []int{2, 3})
+
+	if err := batch.Send(); err != nil {
+		t.Fatal(err)
+	}
+
+	var a, b int
+
+	if first.Decode(&a) != nil || second.Decode(&b) != nil || a != 3 || b != 30 {
+		t.Errorf("results = %d, %d", a, b)
+	}
+
+	var rpcErr *JSONRPCError
+
+	if !errors.As(failed.Err(), &rpcErr) || rpcErr.Message != "Method not found" {
+		t.Errorf("failed call error = %v", failed.Err())
+	}
+
+	if err := client.Batch().Send(); err == nil {
+		t.Error("expected an error for the empty batch")
+	}
+}
@@ -0,0 +1,165 @@
+package room
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const defaultLogBodyLimit = 4096
+
+type loggingConfig struct {
+	headers         bool
+	bodies          bool
+	bodyLimit       int
+	redactedHeaders []string
+	redactedFields  []string
+}
+
+type OptionLogging func(config *loggingConfig)
+
+// WithLogHeaders dumps the request and response headers at debug level
+func WithLogHeaders() OptionLogging {
+	return func(config *loggingConfig) {
+		config.headers = true
+	}
+}
+
+// WithLogBodies dumps the request and response bodies at debug level
+func WithLogBodies() OptionLogging {
+	return func(config *loggingConfig) {
+		config.bodies = true
+	}
+}
+
+// WithLogBodyLimit truncates the dumped bodies to the given number of bytes, 0 disables truncation
+func WithLogBodyLimit(limit int) OptionLogging {
+	return func(config *loggingConfig) {
+		config.bodyLimit = limit
+	}
+}
+
+// WithRedactedHeaders redacts the headers in addition to DefaultRedactedHeaders
+func WithRedactedHeaders(headers ...string) OptionLogging {
+	return func(config *loggingConfig) {
+		config.redactedHeaders = append(config.redactedHeaders, headers...)
+	}
+}
+
+// WithRedactedFields redacts the body fields in addition to DefaultRedactedFields, see NewRedactor
+func WithRedactedFields(fields ...string) OptionLogging {
+	return func(config *loggingConfig) {
+		config.redactedFields = append(config.redactedFields, fields...)
+	}
+}
+
+// WithLogging logs every request of the connector by the logger
+func WithLogging(logger *slog.Logger, opts ...OptionLogging) OptionConnector {
+	return WithMiddleware(LoggingMiddleware(logger, opts...))
+}
+
+// LoggingMiddleware logs the method, uri, status and duration of every request at info level,
+// headers and bodies are dumped at debug level when they are enabled by the options
+func LoggingMiddleware(logger *slog.Logger, opts ...OptionLogging) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	config := loggingConfig{bodyLimit: defaultLogBodyLimit}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	redactor := NewRedactor(config.redactedHeaders, config.redactedFields)
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			dump := (config.headers || config.bodies) && logger.Enabled(ctx, slog.LevelDebug)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("uri", redactor.URL(req.URL)),
+			}
+
+			if info, ok := InfoFromContext(ctx); ok {
+				for key, value := range info.Labels {
+					attrs = append(attrs, slog.String(key, value))
+				}
+			}
+
+			var requestBody []byte
+
+			if dump && config.bodies && req.GetBody != nil {
+				if body, err := req.GetBody(); err == nil {
+					requestBody, _ = io.ReadAll(body)
+					requestBody, _ = decode(req.Header, requestBody)
+					_ = body.Close()
+				}
+			}
+
+			start := time.Now()
+
+			res, err := next.RoundTrip(req)
+
+			attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+
+			if err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "room request failed", append(attrs, slog.String("error", err.Error()))...)
+
+				return res, err
+			}
+
+			attrs = append(attrs, slog.Int("status", res.StatusCode))
+
+			logger.LogAttrs(ctx, slog.LevelInfo, "room request", attrs...)
+
+			if !dump {
+				return res, nil
+			}
+
+			dumpAttrs := attrs
+
+			if config.headers {
+				dumpAttrs = append(dumpAttrs,
+					slog.Any("request_headers", redactor.Header(req.Header)),
+					slog.Any("response_headers", redactor.Header(res.Header)),
+				)
+			}
+
+			if config.bodies && !IsStream(res) {
+				responseBody, readErr := io.ReadAll(res.Body)
+				_ = res.Body.Close()
+				res.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+				if readErr != nil {
+					return res, readErr
+				}
+
+				// the compressed bodies are dumped decoded, so they can be read and redacted
+				loggedBody, _ := decode(res.Header, responseBody)
+
+				dumpAttrs = append(dumpAttrs,
+					slog.String("request_body", truncate(redactor.Body(req.Header.Get(headerKeyContentType), requestBody), config.bodyLimit)),
+					slog.String("response_body", truncate(redactor.Body(res.Header.Get(headerKeyContentType), loggedBody), config.bodyLimit)),
+				)
+			}
+
+			logger.LogAttrs(ctx, slog.LevelDebug, "room request dump", dumpAttrs...)
+
+			return res, nil
+		})
+	}
+}
+
+func truncate(body []byte, limit int) string {
+	if limit <= 0 || len(body) <= limit {
+		return string(body)
+	}
+
+	return string(body[:limit]) + fmt.Sprintf("...(%d bytes truncated)", len(body)-limit)
+}
@@ -0,0 +1,62 @@
+package room
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token":"server-secret","id":1}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	connector := NewConnector(server.URL,
+		WithHeaderConnector(NewHeader().Add("Authorization", "Bearer client-secret")),
+		WithLogging(logger, WithLogHeaders(), WithLogBodies(), WithLogBodyLimit(64)),
+	)
+
+	response, err := connector.Do(NewRequest("auth/login",
+		WithMethod(POST),
+		WithBody(NewJsonBodyParser(map[string]any{"username": "john", "password": "client-password"})),
+	))
+	if err != nil {
+		t.Fatalf("Do() returned error: %v", err)
+	}
+
+	if response.ResponseBody()["token"] != "server-secret" {
+		t.Error("WithLogging() did not keep the response body readable")
+	}
+
+	logs := buf.String()
+
+	for _, expected := range []string{`"msg":"room request"`, `"msg":"room request dump"`, `"status":200`, `"method":"POST"`, `"username\":\"john`} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("WithLogging() did not log %s, got %s", expected, logs)
+		}
+	}
+
+	for _, secret := range []string{"client-secret", "client-password", "server-secret"} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("WithLogging() logged the secret %s, got %s", secret, logs)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if truncated := truncate([]byte("0123456789"), 4); truncated != "0123...(6 bytes truncated)" {
+		t.Errorf("truncate() returned %s", truncated)
+	}
+
+	if truncated := truncate([]byte("0123"), 0); truncated != "0123" {
+		t.Errorf("truncate() returned %s", truncated)
+	}
+}
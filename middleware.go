@@ -0,0 +1,60 @@
+package room
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Middleware wraps the transport of a connector, it is the extension point for tracing, logging and recording
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// RequestInfo describes the room request behind an http request, middlewares read it by InfoFromContext
+type RequestInfo struct {
+	// PathTemplate is the path before the path params are applied, like `/todos/{id}`
+	PathTemplate string
+	// Labels are set by WithLabels, the elevator engine labels requests with their room and request keys
+	Labels map[string]string
+	// Attempt counts the resends of the request, it is 0 for the first attempt
+	Attempt int
+}
+
+type requestInfoKey struct{}
+
+// InfoFromContext returns the RequestInfo of an http request sent by room
+func InfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+
+	return info, ok
+}
+
+func contextWithInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// IsStream reports whether the response is a websocket upgrade or an event stream,
+// middlewares must not read the body of a stream as it stays open
+func IsStream(response *http.Response) bool {
+	return response.StatusCode == http.StatusSwitchingProtocols ||
+		strings.HasPrefix(response.Header.Get(headerKeyContentType), headerValueEventStream)
+}
+
+// chainMiddlewares wraps the transport so the first middleware is the outermost one
+func chainMiddlewares(transport http.RoundTripper, middlewares []Middleware) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		transport = middlewares[i](transport)
+	}
+
+	return transport
+}
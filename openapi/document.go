@@ -0,0 +1,287 @@
+// Package openapi imports OpenAPI 3 documents as elevator configs and generates the DTOs of their schemas.
+package openapi
+
+import (
+	"fmt"
+	"github.com/WEG-Technology/room/internal/maputil"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+const (
+	schemasRef       = "#/components/schemas/"
+	parametersRef    = "#/components/parameters/"
+	requestBodiesRef = "#/components/requestBodies/"
+	responsesRef     = "#/components/responses/"
+)
+
+// Document is the subset of an OpenAPI 3 document which is needed to import it
+type Document struct {
+	OpenAPI    string              `yaml:"openapi"`
+	Info       Info                `yaml:"info"`
+	Servers    []Server            `yaml:"servers"`
+	Paths      map[string]PathItem `yaml:"paths"`
+	Components Components          `yaml:"components"`
+}
+
+type Info struct {
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+type Server struct {
+	URL       string                    `yaml:"url"`
+	Variables map[string]ServerVariable `yaml:"variables"`
+}
+
+type ServerVariable struct {
+	Default string `yaml:"default"`
+}
+
+type PathItem struct {
+	Parameters []Parameter `yaml:"parameters"`
+	Servers    []Server    `yaml:"servers"`
+	Get        *Operation  `yaml:"get"`
+	Put        *Operation  `yaml:"put"`
+	Post       *Operation  `yaml:"post"`
+	Delete     *Operation  `yaml:"delete"`
+	Patch      *Operation  `yaml:"patch"`
+	Head       *Operation  `yaml:"head"`
+}
+
+type Operation struct {
+	OperationID string              `yaml:"operationId"`
+	Tags        []string            `yaml:"tags"`
+	Summary     string              `yaml:"summary"`
+	Parameters  []Parameter         `yaml:"parameters"`
+	RequestBody *RequestBody        `yaml:"requestBody"`
+	Responses   map[string]Response `yaml:"responses"`
+	Servers     []Server            `yaml:"servers"`
+}
+
+type Parameter struct {
+	Ref      string  `yaml:"$ref"`
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Schema   *Schema `yaml:"schema"`
+	Example  any     `yaml:"example"`
+}
+
+type RequestBody struct {
+	Ref      string               `yaml:"$ref"`
+	Required bool                 `yaml:"required"`
+	Content  map[string]MediaType `yaml:"content"`
+}
+
+type Response struct {
+	Ref         string               `yaml:"$ref"`
+	Description string               `yaml:"description"`
+	Content     map[string]MediaType `yaml:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `yaml:"schema"`
+}
+
+type Components struct {
+	Schemas       map[string]*Schema     `yaml:"schemas"`
+	Parameters    map[string]Parameter   `yaml:"parameters"`
+	RequestBodies map[string]RequestBody `yaml:"requestBodies"`
+	Responses     map[string]Response    `yaml:"responses"`
+}
+
+// Schema is a JSON schema of OpenAPI, Type is a string or a list of strings like `[string, "null"]` in 3.1
+type Schema struct {
+	Ref                  string             `yaml:"$ref"`
+	Type                 any                `yaml:"type"`
+	Format               string             `yaml:"format"`
+	Description          string             `yaml:"description"`
+	Properties           map[string]*Schema `yaml:"properties"`
+	Required             []string           `yaml:"required"`
+	Items                *Schema            `yaml:"items"`
+	AdditionalProperties any                `yaml:"additionalProperties"`
+	Enum                 []any              `yaml:"enum"`
+	Default              any                `yaml:"default"`
+	Example              any                `yaml:"example"`
+	AllOf                []*Schema          `yaml:"allOf"`
+	OneOf                []*Schema          `yaml:"oneOf"`
+	AnyOf                []*Schema          `yaml:"anyOf"`
+}
+
+// Parse decodes an OpenAPI 3 document in yaml or json
+func Parse(data []byte) (*Document, error) {
+	var document Document
+
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		return nil, fmt.Errorf("openapi: version %q is not supported, expected 3.x", document.OpenAPI)
+	}
+
+	return &document, nil
+}
+
+// ParseFile reads and decodes an OpenAPI 3 document
+func ParseFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// SchemaType returns the type of the schema, `null` of the 3.1 type lists is skipped
+func (s *Schema) SchemaType() string {
+	switch t := s.Type.(type) {
+	case string:
+		return t
+	case []any:
+		for _, item := range t {
+			if item != "null" {
+				return fmt.Sprint(item)
+			}
+		}
+	}
+
+	if len(s.Properties) > 0 {
+		return "object"
+	}
+
+	return ""
+}
+
+// additionalProperties returns the schema of the additional properties, nil when they are not declared by a schema
+func (s *Schema) additionalProperties() *Schema {
+	m, ok := s.AdditionalProperties.(map[string]any)
+
+	if !ok {
+		return nil
+	}
+
+	data, err := yaml.Marshal(m)
+
+	if err != nil {
+		return nil
+	}
+
+	var schema Schema
+
+	if yaml.Unmarshal(data, &schema) != nil {
+		return nil
+	}
+
+	return &schema
+}
+
+// resolve follows the $ref of the schema and merges allOf, the name of the referenced component is returned too
+func (d *Document) resolve(schema *Schema) (*Schema, string) {
+	name := ""
+
+	for depth := 0; schema != nil && schema.Ref != "" && depth < 32; depth++ {
+		name = strings.TrimPrefix(schema.Ref, schemasRef)
+		schema = d.Components.Schemas[name]
+	}
+
+	if schema == nil || len(schema.AllOf) == 0 {
+		return schema, name
+	}
+
+	merged := *schema
+	merged.AllOf = nil
+	merged.Properties = map[string]*Schema{}
+	merged.Required = append([]string(nil), schema.Required...)
+
+	for key, property := range schema.Properties {
+		merged.Properties[key] = property
+	}
+
+	for _, part := range schema.AllOf {
+		resolved, _ := d.resolve(part)
+
+		if resolved == nil {
+			continue
+		}
+
+		for key, property := range resolved.Properties {
+			merged.Properties[key] = property
+		}
+
+		merged.Required = append(merged.Required, resolved.Required...)
+
+		if merged.Type == nil {
+			merged.Type = resolved.Type
+		}
+	}
+
+	return &merged, name
+}
+
+func (d *Document) parameter(parameter Parameter) Parameter {
+	if parameter.Ref == "" {
+		return parameter
+	}
+
+	return d.Components.Parameters[strings.TrimPrefix(parameter.Ref, parametersRef)]
+}
+
+func (d *Document) requestBody(body *RequestBody) *RequestBody {
+	if body == nil || body.Ref == "" {
+		return body
+	}
+
+	resolved, ok := d.Components.RequestBodies[strings.TrimPrefix(body.Ref, requestBodiesRef)]
+
+	if !ok {
+		return nil
+	}
+
+	return &resolved
+}
+
+func (d *Document) response(response Response) Response {
+	if response.Ref == "" {
+		return response
+	}
+
+	return d.Components.Responses[strings.TrimPrefix(response.Ref, responsesRef)]
+}
+
+type operationEntry struct {
+	method    string
+	path      string
+	item      PathItem
+	operation *Operation
+}
+
+// operations returns the operations sorted by path and method
+func (d *Document) operations() []operationEntry {
+	var entries []operationEntry
+
+	for _, path := range maputil.SortedKeys(d.Paths) {
+		item := d.Paths[path]
+
+		for _, entry := range []struct {
+			method    string
+			operation *Operation
+		}{
+			{"GET", item.Get},
+			{"POST", item.Post},
+			{"PUT", item.Put},
+			{"PATCH", item.Patch},
+			{"DELETE", item.Delete},
+			{"HEAD", item.Head},
+		} {
+			if entry.operation != nil {
+				entries = append(entries, operationEntry{method: entry.method, path: path, item: item, operation: entry.operation})
+			}
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,332 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/WEG-Technology/room/codegen"
+	"github.com/WEG-Technology/room/internal/maputil"
+	"go/format"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+type dtoGenerator struct {
+	document    *Document
+	packageName string
+	source      string
+	types       []dtoType
+	names       map[string]bool
+	structs     map[string]bool
+	inline      map[*Schema]string
+}
+
+type dtoType struct {
+	Name   string
+	Doc    string
+	Alias  string
+	Fields []dtoField
+}
+
+type dtoField struct {
+	Name string
+	Type string
+	Tag  string
+	Doc  string
+}
+
+type OptionDTO func(generator *dtoGenerator)
+
+// WithPackage sets the package name of the generated DTOs, it is `dto` by default
+func WithPackage(name string) OptionDTO {
+	return func(generator *dtoGenerator) {
+		generator.packageName = name
+	}
+}
+
+// WithSource names the OpenAPI document in the header comment of the generated file
+func WithSource(source string) OptionDTO {
+	return func(generator *dtoGenerator) {
+		generator.source = source
+	}
+}
+
+// GenerateDTOs returns the formatted source of a type for every component schema and for every inline schema
+// of the successful json responses, named like `GetTodoResponse`, to decode the responses by room.DecodeDTO
+func GenerateDTOs(document *Document, opts ...OptionDTO) ([]byte, error) {
+	g := &dtoGenerator{
+		document:    document,
+		packageName: "dto",
+		source:      "openapi.yml",
+		names:       map[string]bool{},
+		structs:     map[string]bool{},
+		inline:      map[*Schema]string{},
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if !token.IsIdentifier(g.packageName) {
+		return nil, fmt.Errorf("openapi: %q is not a valid package name", g.packageName)
+	}
+
+	// the component types are named first so the references resolve to them whatever the order is
+	componentNames := maputil.SortedKeys(document.Components.Schemas)
+
+	for _, name := range componentNames {
+		typeName := codegen.GoName(name)
+
+		if g.names[typeName] {
+			return nil, fmt.Errorf("openapi: schemas of %s are both named %s", name, typeName)
+		}
+
+		g.names[typeName] = true
+
+		g.structs[typeName] = g.isStruct(document.Components.Schemas[name])
+	}
+
+	for _, name := range componentNames {
+		g.declare(codegen.GoName(name), "is the schema "+name, document.Components.Schemas[name])
+	}
+
+	for _, entry := range document.operations() {
+		schema := g.responseSchema(entry.operation)
+
+		if schema == nil || schema.Ref != "" {
+			continue
+		}
+
+		name := g.uniqueName(codegen.GoName(RequestKey(entry.method, entry.path, entry.operation.OperationID)) + "Response")
+		g.names[name] = true
+
+		g.structs[name] = g.isStruct(schema)
+
+		g.declare(name, fmt.Sprintf("is the response of %s %s", entry.method, entry.path), schema)
+	}
+
+	var buf bytes.Buffer
+
+	if err := dtoTemplate.Execute(&buf, g); err != nil {
+		return nil, err
+	}
+
+	source, err := format.Source(buf.Bytes())
+
+	if err != nil {
+		return nil, fmt.Errorf("openapi: generated invalid code: %w", err)
+	}
+
+	return source, nil
+}
+
+// Package and Source are used by the template
+func (g *dtoGenerator) Package() string {
+	return g.packageName
+}
+
+func (g *dtoGenerator) Source() string {
+	return g.source
+}
+
+func (g *dtoGenerator) Types() []dtoType {
+	return g.types
+}
+
+// isStruct reports whether the schema is declared as a struct, objects without properties are maps
+func (g *dtoGenerator) isStruct(schema *Schema) bool {
+	resolved, _ := g.document.resolve(schema)
+
+	return resolved != nil && resolved.SchemaType() == "object" && len(resolved.Properties) > 0 && resolved.additionalProperties() == nil
+}
+
+// responseSchema returns the json schema of the first successful response
+func (g *dtoGenerator) responseSchema(operation *Operation) *Schema {
+	for _, code := range maputil.SortedKeys(operation.Responses) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+
+		response := g.document.response(operation.Responses[code])
+
+		if mediaType, bodyType, ok := bodyMediaType(response.Content); ok && bodyType == "json" {
+			return mediaType.Schema
+		}
+	}
+
+	return nil
+}
+
+func (g *dtoGenerator) declare(name, doc string, schema *Schema) {
+	resolved, _ := g.document.resolve(schema)
+
+	if resolved == nil {
+		g.types = append(g.types, dtoType{Name: name, Doc: doc, Alias: "any"})
+		return
+	}
+
+	if resolved.Description != "" {
+		doc += ", " + firstLine(resolved.Description)
+	}
+
+	if !g.structs[name] {
+		// the alias is resolved without the schema itself, otherwise a named schema would refer to its own name
+		alias := *resolved
+		alias.Ref = ""
+
+		g.types = append(g.types, dtoType{Name: name, Doc: doc, Alias: g.goType(name, "", &alias)})
+		return
+	}
+
+	t := dtoType{Name: name, Doc: doc}
+	index := len(g.types)
+	g.types = append(g.types, t)
+
+	for _, key := range maputil.SortedKeys(resolved.Properties) {
+		property := resolved.Properties[key]
+		required := slices.Contains(resolved.Required, key)
+
+		field := dtoField{Name: codegen.GoName(key), Tag: fmt.Sprintf("`json:%q`", key)}
+
+		if !required {
+			field.Tag = fmt.Sprintf("`json:%q`", key+",omitempty")
+		}
+
+		field.Type = g.goType(name, field.Name, property)
+
+		// optional and self referencing structs are pointers, a struct can not contain itself
+		if g.structs[field.Type] && (!required || field.Type == name) {
+			field.Type = "*" + field.Type
+		}
+
+		if propertyResolved, _ := g.document.resolve(property); propertyResolved != nil {
+			field.Doc = fieldDoc(propertyResolved)
+		}
+
+		t.Fields = append(t.Fields, field)
+	}
+
+	g.types[index] = t
+}
+
+// goType returns the Go type of the schema, inline objects are declared as `ParentField` structs
+func (g *dtoGenerator) goType(parent, field string, schema *Schema) string {
+	if schema == nil {
+		return "any"
+	}
+
+	if schema.Ref != "" {
+		if !strings.HasPrefix(schema.Ref, schemasRef) {
+			return "any"
+		}
+
+		return codegen.GoName(strings.TrimPrefix(schema.Ref, schemasRef))
+	}
+
+	resolved, _ := g.document.resolve(schema)
+
+	switch resolved.SchemaType() {
+	case "string":
+		return "string"
+	case "boolean":
+		return "bool"
+	case "integer":
+		switch resolved.Format {
+		case "int64":
+			return "int64"
+		case "int32":
+			return "int32"
+		}
+
+		return "int"
+	case "number":
+		if resolved.Format == "float" {
+			return "float32"
+		}
+
+		return "float64"
+	case "array":
+		return "[]" + g.goType(parent, field+"Item", resolved.Items)
+	case "object":
+		if additional := resolved.additionalProperties(); additional != nil {
+			return "map[string]" + g.goType(parent, field+"Value", additional)
+		}
+
+		if len(resolved.Properties) == 0 {
+			return "map[string]any"
+		}
+
+		// the properties merged by allOf share their schemas, they are declared once
+		if name, declared := g.inline[resolved]; declared {
+			return name
+		}
+
+		name := g.uniqueName(parent + field)
+		g.inline[resolved] = name
+		g.names[name] = true
+		g.structs[name] = true
+
+		g.declare(name, "is the "+strings.TrimSuffix(field, "Item")+" of "+parent, resolved)
+
+		return name
+	}
+
+	return "any"
+}
+
+func (g *dtoGenerator) uniqueName(name string) string {
+	unique := name
+
+	for n := 2; g.names[unique]; n++ {
+		unique = name + strconv.Itoa(n)
+	}
+
+	return unique
+}
+
+func fieldDoc(schema *Schema) string {
+	var notes []string
+
+	if schema.Format != "" {
+		notes = append(notes, "format "+schema.Format)
+	}
+
+	if len(schema.Enum) > 0 {
+		values := make([]string, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			values = append(values, fmt.Sprint(value))
+		}
+
+		notes = append(notes, "one of "+strings.Join(values, ", "))
+	}
+
+	if schema.Description != "" {
+		notes = append(notes, firstLine(schema.Description))
+	}
+
+	return strings.Join(notes, ", ")
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+
+	return line
+}
+
+var dtoTemplate = template.Must(template.New("dto").Parse(`// Code generated by room import from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+{{range .Types}}
+// {{.Name}} {{.Doc}}
+{{- if .Alias}}
+type {{.Name}} {{.Alias}}
+{{else}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}}{{if .Doc}} // {{.Doc}}{{end}}
+{{- end}}
+}
+{{end}}
+{{- end}}`))
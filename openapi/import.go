@@ -0,0 +1,333 @@
+package openapi
+
+import (
+	"fmt"
+	"github.com/WEG-Technology/room/codegen"
+	"github.com/WEG-Technology/room/elevator"
+	"github.com/WEG-Technology/room/internal/maputil"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// baseUrlEnv is the placeholder of the baseUrl when the document has no absolute server url
+const baseUrlEnv = "${BASE_URL}"
+
+var knownFormats = []string{elevator.DynamicFormatEmail, elevator.DynamicFormatUUID, elevator.DynamicFormatDateTime}
+
+// Result is the imported config with the parts of the document which could not be imported
+type Result struct {
+	Config   elevator.IntegrationConfig
+	Warnings []string
+}
+
+type importer struct {
+	document *Document
+	baseUrl  string
+	result   Result
+}
+
+type OptionImport func(importer *importer)
+
+// WithBaseUrl sets the baseUrl of all rooms instead of the servers of the document
+func WithBaseUrl(baseUrl string) OptionImport {
+	return func(importer *importer) {
+		importer.baseUrl = baseUrl
+	}
+}
+
+// Import converts the operations of the document to requests, operations are grouped into rooms by their first tag
+// or by their server when they are not tagged. Path params stay as `{param}` for room.WithPathParams,
+// query params and the properties of the request body become dynamic contents and header params
+// are read from environment variables like `${X_API_KEY}` unless they have an example.
+func Import(document *Document, opts ...OptionImport) Result {
+	i := &importer{
+		document: document,
+		result:   Result{Config: elevator.IntegrationConfig{Flat: elevator.Flat{Rooms: map[string]elevator.Room{}}}},
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	for _, entry := range document.operations() {
+		i.operation(entry)
+	}
+
+	return i.result
+}
+
+func (i *importer) operation(entry operationEntry) {
+	server := i.server(entry)
+	roomKey := i.roomKey(entry.operation, server)
+
+	configRoom, exists := i.result.Config.Flat.Rooms[roomKey]
+
+	if !exists {
+		configRoom = elevator.Room{Connection: elevator.Connection{BaseURL: server}, Requests: map[string]elevator.Request{}}
+	} else if configRoom.Connection.BaseURL != server {
+		i.warn("%s %s: server %s differs from %s of room %s", entry.method, entry.path, server, configRoom.Connection.BaseURL, roomKey)
+	}
+
+	requestKey := RequestKey(entry.method, entry.path, entry.operation.OperationID)
+
+	for n := 2; ; n++ {
+		if _, taken := configRoom.Requests[requestKey]; !taken {
+			break
+		}
+
+		requestKey = fmt.Sprintf("%s%d", RequestKey(entry.method, entry.path, entry.operation.OperationID), n)
+	}
+
+	configRoom.Requests[requestKey] = i.request(entry)
+	i.result.Config.Flat.Rooms[roomKey] = configRoom
+}
+
+func (i *importer) request(entry operationEntry) elevator.Request {
+	request := elevator.Request{
+		Method: entry.method,
+		Path:   strings.TrimPrefix(entry.path, "/"),
+	}
+
+	for _, parameter := range i.parameters(entry) {
+		switch parameter.In {
+		case "query":
+			request.Query.DynamicContent = append(request.Query.DynamicContent, i.dynamicContent(parameter.Name, parameter.Schema, parameter.Required, map[string]bool{}))
+		case "header":
+			if request.Headers == nil {
+				request.Headers = map[string]any{}
+			}
+
+			request.Headers[parameter.Name] = fmt.Sprint(parameterValue(parameter))
+		case "cookie":
+			if request.Cookies == nil {
+				request.Cookies = map[string]string{}
+			}
+
+			request.Cookies[parameter.Name] = fmt.Sprint(parameterValue(parameter))
+		}
+	}
+
+	if body := i.document.requestBody(entry.operation.RequestBody); body != nil {
+		request.Body = i.body(entry, body)
+	}
+
+	return request
+}
+
+// parameters returns the parameters of the operation, they override the path item parameters with the same name and location
+func (i *importer) parameters(entry operationEntry) []Parameter {
+	var parameters []Parameter
+	index := map[string]int{}
+
+	for _, parameter := range append(append([]Parameter(nil), entry.item.Parameters...), entry.operation.Parameters...) {
+		parameter = i.document.parameter(parameter)
+		key := parameter.In + ":" + parameter.Name
+
+		if at, exists := index[key]; exists {
+			parameters[at] = parameter
+			continue
+		}
+
+		index[key] = len(parameters)
+		parameters = append(parameters, parameter)
+	}
+
+	return parameters
+}
+
+func (i *importer) body(entry operationEntry, body *RequestBody) elevator.Body {
+	mediaType, bodyType, ok := bodyMediaType(body.Content)
+
+	if !ok {
+		i.warn("%s %s: request body %v is not supported", entry.method, entry.path, maputil.SortedKeys(body.Content))
+		return elevator.Body{}
+	}
+
+	schema, _ := i.document.resolve(mediaType.Schema)
+
+	if schema == nil || schema.SchemaType() != "object" {
+		i.warn("%s %s: request body is not an object, its content must be declared by hand", entry.method, entry.path)
+		return elevator.Body{Type: bodyType}
+	}
+
+	return elevator.Body{Type: bodyType, DynamicContent: i.properties(schema, map[string]bool{})}
+}
+
+func (i *importer) properties(schema *Schema, visiting map[string]bool) []elevator.DynamicContent {
+	var dynamicContents []elevator.DynamicContent
+
+	for _, key := range maputil.SortedKeys(schema.Properties) {
+		dynamicContents = append(dynamicContents, i.dynamicContent(key, schema.Properties[key], slices.Contains(schema.Required, key), visiting))
+	}
+
+	return dynamicContents
+}
+
+func (i *importer) dynamicContent(key string, schema *Schema, required bool, visiting map[string]bool) elevator.DynamicContent {
+	dynamicContent := elevator.DynamicContent{Key: key}
+
+	resolved, name := i.document.resolve(schema)
+
+	if resolved == nil {
+		return dynamicContent
+	}
+
+	// recursive schemas are cut at the first repetition, the nested value is sent as it is
+	if name != "" {
+		if visiting[name] {
+			dynamicContent.Type = elevator.DynamicTypeObject
+			return dynamicContent
+		}
+
+		visiting[name] = true
+		defer delete(visiting, name)
+	}
+
+	switch t := resolved.SchemaType(); t {
+	case elevator.DynamicTypeString, elevator.DynamicTypeBoolean, elevator.DynamicTypeInteger, elevator.DynamicTypeNumber:
+		dynamicContent.Type = t
+	case elevator.DynamicTypeObject:
+		dynamicContent.Type = t
+		dynamicContent.Properties = i.properties(resolved, visiting)
+	case elevator.DynamicTypeArray:
+		dynamicContent.Type = t
+
+		if resolved.Items != nil {
+			items := i.dynamicContent("", resolved.Items, true, visiting)
+			items.Required = nil
+			dynamicContent.Items = &items
+		}
+	}
+
+	if slices.Contains(knownFormats, resolved.Format) {
+		dynamicContent.Format = resolved.Format
+	}
+
+	dynamicContent.Enum = resolved.Enum
+	dynamicContent.Default = resolved.Default
+
+	switch {
+	case !required:
+		optional := false
+		dynamicContent.Required = &optional
+	case resolved.Default != nil:
+		mandatory := true
+		dynamicContent.Required = &mandatory
+	}
+
+	return dynamicContent
+}
+
+func (i *importer) server(entry operationEntry) string {
+	if i.baseUrl != "" {
+		return i.baseUrl
+	}
+
+	servers := i.document.Servers
+
+	if len(entry.item.Servers) > 0 {
+		servers = entry.item.Servers
+	}
+
+	if len(entry.operation.Servers) > 0 {
+		servers = entry.operation.Servers
+	}
+
+	if len(servers) == 0 {
+		return baseUrlEnv
+	}
+
+	u := servers[0].URL
+
+	for name, variable := range servers[0].Variables {
+		u = strings.ReplaceAll(u, "{"+name+"}", variable.Default)
+	}
+
+	u = strings.TrimSuffix(u, "/")
+
+	if parsed, err := url.Parse(u); err != nil || !parsed.IsAbs() {
+		return baseUrlEnv + u
+	}
+
+	return u
+}
+
+func (i *importer) roomKey(operation *Operation, server string) string {
+	if len(operation.Tags) > 0 {
+		return lowerName(operation.Tags[0])
+	}
+
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		return lowerName(u.Hostname())
+	}
+
+	return "default"
+}
+
+func (i *importer) warn(format string, args ...any) {
+	i.result.Warnings = append(i.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// RequestKey returns the key of an operation, its operationId or the method and the path like `getTodosId`
+func RequestKey(method, path, operationID string) string {
+	if operationID != "" {
+		return lowerName(operationID)
+	}
+
+	return lowerName(strings.ToLower(method) + " " + path)
+}
+
+// parameterValue returns the example or the default of a header or cookie param, otherwise an environment variable
+func parameterValue(parameter Parameter) any {
+	if parameter.Example != nil {
+		return parameter.Example
+	}
+
+	if parameter.Schema != nil {
+		if parameter.Schema.Example != nil {
+			return parameter.Schema.Example
+		}
+
+		if parameter.Schema.Default != nil {
+			return parameter.Schema.Default
+		}
+	}
+
+	return "${" + envName(parameter.Name) + "}"
+}
+
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+
+		return '_'
+	}, name)
+}
+
+// bodyMediaType picks json over form and multipart bodies
+func bodyMediaType(content map[string]MediaType) (MediaType, string, bool) {
+	for _, contentType := range maputil.SortedKeys(content) {
+		if contentType == "application/json" || strings.HasSuffix(contentType, "+json") {
+			return content[contentType], "json", true
+		}
+	}
+
+	for _, contentType := range []string{"application/x-www-form-urlencoded", "multipart/form-data"} {
+		if mediaType, ok := content[contentType]; ok {
+			return mediaType, map[string]string{"application/x-www-form-urlencoded": "form", "multipart/form-data": "multipart-form"}[contentType], true
+		}
+	}
+
+	return MediaType{}, "", false
+}
+
+func lowerName(key string) string {
+	name := []rune(codegen.GoName(key))
+	name[0] = unicode.ToLower(name[0])
+
+	return string(name)
+}
@@ -0,0 +1,275 @@
+package openapi
+
+import (
+	"github.com/WEG-Technology/room/elevator"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const petstore = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+servers:
+  - url: https://{env}.petstore.io/v1
+    variables:
+      env:
+        default: api
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+        - $ref: '#/components/parameters/ApiKey'
+        - name: X-Api-Version
+          in: header
+          schema:
+            type: integer
+            default: 2
+      responses:
+        '200':
+          description: pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+    post:
+      tags: [pets]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/NewPet'
+      responses:
+        '201':
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema:
+          type: string
+    get:
+      operationId: showPetById
+      tags: [pets]
+      responses:
+        '200':
+          description: pet
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [pet]
+                properties:
+                  pet:
+                    $ref: '#/components/schemas/Pet'
+                  links:
+                    type: object
+                    additionalProperties:
+                      type: string
+  /health:
+    get:
+      servers:
+        - url: /internal
+      responses:
+        '200':
+          description: ok
+    put:
+      requestBody:
+        content:
+          text/plain:
+            schema:
+              type: string
+      responses:
+        '204':
+          description: updated
+components:
+  parameters:
+    ApiKey:
+      name: X-Api-Key
+      in: header
+      required: true
+      schema:
+        type: string
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        email:
+          type: string
+          format: email
+        status:
+          type: string
+          enum: [available, sold]
+          default: available
+        tags:
+          type: array
+          items:
+            type: object
+            properties:
+              label:
+                type: string
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/NewPet'
+        - type: object
+          required: [id]
+          properties:
+            id:
+              type: integer
+              format: int64
+            parent:
+              $ref: '#/components/schemas/Pet'
+`
+
+func parsePetstore(t *testing.T) *Document {
+	t.Helper()
+
+	document, err := Parse([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return document
+}
+
+func TestParse_Version(t *testing.T) {
+	if _, err := Parse([]byte("swagger: '2.0'")); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Parse() error = %v, want version error", err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	result := Import(parsePetstore(t))
+
+	pets, ok := result.Config.Flat.Rooms["pets"]
+	if !ok {
+		t.Fatalf("rooms = %v, want pets", reflect.ValueOf(result.Config.Flat.Rooms).MapKeys())
+	}
+
+	if pets.Connection.BaseURL != "https://api.petstore.io/v1" {
+		t.Errorf("baseUrl = %q", pets.Connection.BaseURL)
+	}
+
+	list := pets.Requests["listPets"]
+	if list.Method != "GET" || list.Path != "pets" {
+		t.Errorf("listPets = %s %s", list.Method, list.Path)
+	}
+
+	if len(list.Query.DynamicContent) != 1 || list.Query.DynamicContent[0].Key != "limit" || list.Query.DynamicContent[0].Type != elevator.DynamicTypeInteger || list.Query.DynamicContent[0].IsRequired() {
+		t.Errorf("listPets query = %+v", list.Query.DynamicContent)
+	}
+
+	if list.Headers["X-Api-Key"] != "${X_API_KEY}" || list.Headers["X-Api-Version"] != "2" {
+		t.Errorf("listPets headers = %v", list.Headers)
+	}
+
+	if show := pets.Requests["showPetById"]; show.Path != "pets/{petId}" {
+		t.Errorf("showPetById path = %q", show.Path)
+	}
+
+	create, ok := pets.Requests["postPets"]
+	if !ok || create.Body.Type != "json" {
+		t.Fatalf("postPets = %+v", create)
+	}
+
+	fields := map[string]elevator.DynamicContent{}
+	for _, content := range create.Body.DynamicContent {
+		fields[content.Key] = content
+	}
+
+	if !fields["name"].IsRequired() || fields["email"].IsRequired() || fields["email"].Format != elevator.DynamicFormatEmail {
+		t.Errorf("postPets body = %+v", create.Body.DynamicContent)
+	}
+
+	if status := fields["status"]; status.Default != "available" || len(status.Enum) != 2 {
+		t.Errorf("status = %+v", status)
+	}
+
+	if tags := fields["tags"]; tags.Items == nil || tags.Items.Type != elevator.DynamicTypeObject || tags.Items.Properties[0].Key != "label" {
+		t.Errorf("tags = %+v", tags)
+	}
+
+	health, ok := result.Config.Flat.Rooms["default"]
+	if !ok || health.Connection.BaseURL != "${BASE_URL}/internal" {
+		t.Fatalf("default room = %+v", health)
+	}
+
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "PUT /health") {
+		t.Errorf("warnings = %q", result.Warnings)
+	}
+
+	el := elevator.Elevator{Config: result.Config}
+	if err := el.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestImport_WithBaseUrl(t *testing.T) {
+	result := Import(parsePetstore(t), WithBaseUrl("http://localhost:8080"))
+
+	for key, configRoom := range result.Config.Flat.Rooms {
+		if configRoom.Connection.BaseURL != "http://localhost:8080" {
+			t.Errorf("room %s baseUrl = %q", key, configRoom.Connection.BaseURL)
+		}
+	}
+
+	if len(result.Warnings) != 1 {
+		t.Errorf("warnings = %q", result.Warnings)
+	}
+}
+
+func TestGenerateDTOs(t *testing.T) {
+	source, err := GenerateDTOs(parsePetstore(t), WithPackage("petstore"), WithSource("petstore.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = parser.ParseFile(token.NewFileSet(), "petstore_gen.go", source, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, source)
+	}
+
+	for _, want := range []string{
+		"// Code generated by room import from petstore.yml. DO NOT EDIT.",
+		"package petstore",
+		"type NewPet struct {",
+		"Name string `json:\"name\"`",
+		"Tags []NewPetTagsItem `json:\"tags,omitempty\"`",
+		"type NewPetTagsItem struct {",
+		"Id int64 `json:\"id\"` // format int64",
+		"Parent *Pet `json:\"parent,omitempty\"`",
+		"type ListPetsResponse []Pet",
+		"type ShowPetByIdResponse struct {",
+		"Links map[string]string `json:\"links,omitempty\"`",
+		"Pet Pet `json:\"pet\"`",
+	} {
+		if !strings.Contains(strings.Join(strings.Fields(string(source)), " "), want) {
+			t.Errorf("generated code does not contain %q\n%s", want, source)
+		}
+	}
+}
+
+func TestGenerateDTOs_InvalidPackage(t *testing.T) {
+	if _, err := GenerateDTOs(parsePetstore(t), WithPackage("not-valid")); err == nil {
+		t.Error("GenerateDTOs() error = nil, want invalid package")
+	}
+}
@@ -0,0 +1,21 @@
+module github.com/WEG-Technology/room/otelroom
+
+go 1.21.1
+
+require (
+	github.com/WEG-Technology/room v0.0.0-20261019180249-330eaed975e2
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/metric v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/sdk/metric v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/go-querystring v1.1.0 // indirect
+	github.com/klauspost/compress v1.17.11 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+)
@@ -0,0 +1,207 @@
+// Package otelroom instruments room connectors with OpenTelemetry traces and metrics.
+//
+//	connector := room.NewConnector("https://dummyjson.com", otelroom.WithTelemetry())
+//
+// otelroom is a module of its own, so only its users depend on OpenTelemetry:
+//
+//	go get github.com/WEG-Technology/room/otelroom
+package otelroom
+
+import (
+	"github.com/WEG-Technology/room"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const instrumentationName = "github.com/WEG-Technology/room/otelroom"
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagator     propagation.TextMapPropagator
+}
+
+type Option func(config *config)
+
+// WithTracerProvider sets the tracer provider, the global one is used by default
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(config *config) {
+		config.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider, the global one is used by default
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(config *config) {
+		config.meterProvider = provider
+	}
+}
+
+// WithPropagator sets the propagator of the outgoing headers, W3C trace context and baggage are used by default
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(config *config) {
+		config.propagator = propagator
+	}
+}
+
+// WithTelemetry is the connector option which enables tracing and metrics for every request of the connector
+func WithTelemetry(opts ...Option) room.OptionConnector {
+	return room.WithMiddleware(Middleware(opts...))
+}
+
+// Middleware creates a span for every request and records the client metrics
+func Middleware(opts ...Option) room.Middleware {
+	c := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagator:     propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	meter := c.meterProvider.Meter(instrumentationName)
+
+	// instruments fall back to no-op ones when they can not be created, telemetry must never break a request
+	i := instruments{}
+	i.duration, _ = meter.Float64Histogram("http.client.request.duration", metric.WithUnit("s"), metric.WithDescription("Duration of HTTP client requests."))
+	i.active, _ = meter.Int64UpDownCounter("http.client.active_requests", metric.WithUnit("{request}"), metric.WithDescription("Number of active HTTP client requests."))
+	i.requestSize, _ = meter.Int64Counter("http.client.request.body.size", metric.WithUnit("By"), metric.WithDescription("Size of HTTP client request bodies."))
+	i.responseSize, _ = meter.Int64Counter("http.client.response.body.size", metric.WithUnit("By"), metric.WithDescription("Size of HTTP client response bodies."))
+
+	tracer := c.tracerProvider.Tracer(instrumentationName)
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &transport{
+			next:        next,
+			tracer:      tracer,
+			propagator:  c.propagator,
+			instruments: i,
+		}
+	}
+}
+
+type instruments struct {
+	duration     metric.Float64Histogram
+	active       metric.Int64UpDownCounter
+	requestSize  metric.Int64Counter
+	responseSize metric.Int64Counter
+}
+
+type transport struct {
+	next        http.RoundTripper
+	tracer      trace.Tracer
+	propagator  propagation.TextMapPropagator
+	instruments instruments
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	info, _ := room.InfoFromContext(req.Context())
+
+	template := info.PathTemplate
+	if template == "" {
+		template = req.URL.Path
+	}
+
+	attributes := []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+		attribute.String("server.address", req.URL.Hostname()),
+		attribute.String("url.template", template),
+	}
+
+	for key, value := range info.Labels {
+		attributes = append(attributes, attribute.String("room."+key, value))
+	}
+
+	ctx, span := t.tracer.Start(req.Context(), req.Method+" "+template,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+		trace.WithAttributes(attribute.String("url.full", req.URL.String())),
+	)
+
+	// the resend count is only set for resends, like hedged attempts and reconnects
+	if info.Attempt > 0 {
+		span.SetAttributes(attribute.Int("http.request.resend_count", info.Attempt))
+	}
+
+	req = req.Clone(ctx)
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	metricAttributes := metric.WithAttributes(attributes...)
+
+	t.instruments.active.Add(ctx, 1, metricAttributes)
+	if req.ContentLength > 0 {
+		t.instruments.requestSize.Add(ctx, req.ContentLength, metricAttributes)
+	}
+
+	start := time.Now()
+
+	res, err := t.next.RoundTrip(req)
+
+	t.instruments.active.Add(ctx, -1, metricAttributes)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+
+		t.instruments.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(append(attributes, attribute.String("error.type", "transport"))...))
+
+		return res, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+
+	if res.StatusCode >= 400 {
+		span.SetStatus(codes.Error, strconv.Itoa(res.StatusCode))
+	}
+
+	statusAttributes := metric.WithAttributes(append(attributes, attribute.Int("http.response.status_code", res.StatusCode))...)
+
+	// the span ends and the metrics are recorded when the body is read or closed by room
+	res.Body = &body{
+		ReadCloser: res.Body,
+		done: func(size int64) {
+			t.instruments.duration.Record(ctx, time.Since(start).Seconds(), statusAttributes)
+			t.instruments.responseSize.Add(ctx, size, statusAttributes)
+			span.End()
+		},
+	}
+
+	return res, nil
+}
+
+// body counts the bytes read from the response body and calls done once on EOF or Close
+type body struct {
+	io.ReadCloser
+	size int64
+	once sync.Once
+	done func(size int64)
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+
+	if err == io.EOF {
+		b.once.Do(func() { b.done(b.size) })
+	}
+
+	return n, err
+}
+
+func (b *body) Close() error {
+	b.once.Do(func() { b.done(b.size) })
+
+	return b.ReadCloser.Close()
+}
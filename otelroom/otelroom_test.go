@@ -0,0 +1,122 @@
+package otelroom
+
+import (
+	"context"
+	"github.com/WEG-Technology/room"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithTelemetry(t *testing.T) {
+	var traceparent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	spanRecorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	connector := room.NewConnector(server.URL, WithTelemetry(WithTracerProvider(tracerProvider), WithMeterProvider(meterProvider)))
+
+	_, err := connector.Do(room.NewRequest("todos/{id}",
+		room.WithPathParams(map[string]string{"id": "1"}),
+		room.WithLabels(map[string]string{"room": "todoRoom"}),
+	))
+	if err != nil {
+		t.Fatalf("Do() returned error: %v", err)
+	}
+
+	if traceparent == "" {
+		t.Error("WithTelemetry() did not inject the traceparent header")
+	}
+
+	spans := spanRecorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("WithTelemetry() recorded %d spans, expected 1", len(spans))
+	}
+
+	if spans[0].Name() != "GET /todos/{id}" {
+		t.Errorf("WithTelemetry() named the span %s, expected GET /todos/{id}", spans[0].Name())
+	}
+
+	attributes := attribute.NewSet(spans[0].Attributes()...)
+
+	if v, _ := attributes.Value("http.response.status_code"); v.AsInt64() != 200 {
+		t.Errorf("WithTelemetry() recorded status %v, expected 200", v.AsInt64())
+	}
+
+	if v, _ := attributes.Value("room.room"); v.AsString() != "todoRoom" {
+		t.Errorf("WithTelemetry() recorded room label %v, expected todoRoom", v.AsString())
+	}
+
+	var metrics metricdata.ResourceMetrics
+	if err = reader.Collect(context.Background(), &metrics); err != nil {
+		t.Fatal(err)
+	}
+
+	names := map[string]bool{}
+	for _, scopeMetrics := range metrics.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			names[m.Name] = true
+		}
+	}
+
+	for _, name := range []string{"http.client.request.duration", "http.client.active_requests", "http.client.response.body.size"} {
+		if !names[name] {
+			t.Errorf("WithTelemetry() did not record %s", name)
+		}
+	}
+}
+
+func TestWithTelemetry_ResendCount(t *testing.T) {
+	var requests int
+
+	// the persisted query is unknown on the first request, so the query is sent again
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if requests == 1 {
+			_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound"}]}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"me":{"name":"Ada"}}}`))
+	}))
+	defer server.Close()
+
+	spanRecorder := tracetest.NewSpanRecorder()
+	connector := room.NewConnector(server.URL, WithTelemetry(WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))))
+
+	request := room.NewRequest("graphql", room.WithMethod(room.POST), room.WithBody(room.NewGraphQLBodyParser("{ me { name } }", nil, "", room.WithPersistedQuery(""))))
+
+	if _, err := room.SendGraphQL(connector, request); err != nil {
+		t.Fatalf("SendGraphQL() returned error: %v", err)
+	}
+
+	spans := spanRecorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("WithTelemetry() recorded %d spans, expected 2", len(spans))
+	}
+
+	first, resent := attribute.NewSet(spans[0].Attributes()...), attribute.NewSet(spans[1].Attributes()...)
+
+	if _, set := first.Value("http.request.resend_count"); set {
+		t.Error("WithTelemetry() recorded a resend count for the first request")
+	}
+
+	if v, _ := resent.Value("http.request.resend_count"); v.AsInt64() != 1 {
+		t.Errorf("WithTelemetry() recorded resend count %v, expected 1", v.AsInt64())
+	}
+}
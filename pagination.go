@@ -0,0 +1,473 @@
+package room
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/WEG-Technology/room/jsonpath"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Doer sends a request, *Connector is a Doer and DoerFunc adapts rooms and other senders
+type Doer interface {
+	Do(request *Request) (Response, error)
+}
+
+// DoerFunc adapts a function to Doer
+type DoerFunc func(request *Request) (Response, error)
+
+func (f DoerFunc) Do(request *Request) (Response, error) {
+	return f(request)
+}
+
+// Page is a fetched page of a paginated request
+type Page struct {
+	// Index is the position of the page, it is 0 for the first page
+	Index    int
+	Request  *Request
+	Response Response
+	// Items are the raw json items of the page
+	Items []json.RawMessage
+}
+
+// PageStrategy finds the request of the next page
+type PageStrategy interface {
+	// Next returns the request of the page after the given one, the first page is requested with a nil page.
+	// It returns false when the given page is the last one.
+	Next(request *Request, page *Page) (*Request, bool)
+}
+
+// IndexedPageStrategy builds the request of a page from its index,
+// so the pages can be fetched concurrently without waiting for the previous response
+type IndexedPageStrategy interface {
+	PageStrategy
+	At(request *Request, index int) *Request
+}
+
+// PageNumber sends the page number in the pageParam starting with first, and the size in the sizeParam when it is not empty.
+// The page with fewer items than the size, or without items when the size is 0, is the last one.
+func PageNumber(pageParam string, first int, sizeParam string, size int) IndexedPageStrategy {
+	return pageNumberStrategy{pageParam: pageParam, first: first, sizeParam: sizeParam, size: size}
+}
+
+type pageNumberStrategy struct {
+	pageParam string
+	first     int
+	sizeParam string
+	size      int
+}
+
+func (s pageNumberStrategy) At(request *Request, index int) *Request {
+	params := map[string]string{s.pageParam: strconv.Itoa(s.first + index)}
+
+	if s.sizeParam != "" && s.size > 0 {
+		params[s.sizeParam] = strconv.Itoa(s.size)
+	}
+
+	return request.withQueryParams(params)
+}
+
+func (s pageNumberStrategy) Next(request *Request, page *Page) (*Request, bool) {
+	if page == nil {
+		return s.At(request, 0), true
+	}
+
+	if isLastPage(page, s.size) {
+		return nil, false
+	}
+
+	return s.At(request, page.Index+1), true
+}
+
+// Offset sends the offset of the first item in the offsetParam and the limit in the limitParam,
+// the page with fewer items than the limit is the last one
+func Offset(offsetParam, limitParam string, limit int) IndexedPageStrategy {
+	return offsetStrategy{offsetParam: offsetParam, limitParam: limitParam, limit: limit}
+}
+
+type offsetStrategy struct {
+	offsetParam string
+	limitParam  string
+	limit       int
+}
+
+func (s offsetStrategy) At(request *Request, index int) *Request {
+	return request.withQueryParams(map[string]string{
+		s.offsetParam: strconv.Itoa(index * s.limit),
+		s.limitParam:  strconv.Itoa(s.limit),
+	})
+}
+
+func (s offsetStrategy) Next(request *Request, page *Page) (*Request, bool) {
+	if page == nil {
+		return s.At(request, 0), true
+	}
+
+	if isLastPage(page, s.limit) {
+		return nil, false
+	}
+
+	return s.At(request, page.Index+1), true
+}
+
+func isLastPage(page *Page, size int) bool {
+	return len(page.Items) == 0 || (size > 0 && len(page.Items) < size)
+}
+
+// Cursor reads the token of the next page by the JSONPath from the response and sends it in the param,
+// the page without a token is the last one
+func Cursor(param, path string) PageStrategy {
+	return cursorStrategy{param: param, path: jsonpath.MustParse(path)}
+}
+
+type cursorStrategy struct {
+	param string
+	path  jsonpath.Path
+}
+
+func (s cursorStrategy) Next(request *Request, page *Page) (*Request, bool) {
+	if page == nil {
+		return request.Clone(), true
+	}
+
+	var body any
+
+	if err := json.Unmarshal(page.Response.Data, &body); err != nil {
+		return nil, false
+	}
+
+	token, found := s.path.First(body)
+
+	if !found || token == nil || token == "" || token == false {
+		return nil, false
+	}
+
+	if number, isNumber := token.(float64); isNumber {
+		token = strconv.FormatFloat(number, 'f', -1, 64)
+	}
+
+	return request.withQueryParams(map[string]string{s.param: fmt.Sprint(token)}), true
+}
+
+// LinkHeader follows the `rel="next"` url of the RFC 5988 Link header, the page without it is the last one
+func LinkHeader() PageStrategy {
+	return linkHeaderStrategy{}
+}
+
+type linkHeaderStrategy struct{}
+
+var linkPattern = regexp.MustCompile(`<([^>]*)>([^,]*)`)
+
+func (linkHeaderStrategy) Next(request *Request, page *Page) (*Request, bool) {
+	if page == nil {
+		return request.Clone(), true
+	}
+
+	next := nextLink(page.Response.Header.Get("Link"))
+
+	if next == "" {
+		return nil, false
+	}
+
+	// relative links are resolved against the url of the current page
+	if base, err := url.Parse(page.Response.Request.URI.String()); err == nil {
+		if ref, err := url.Parse(next); err == nil {
+			next = base.ResolveReference(ref).String()
+		}
+	}
+
+	return request.follow(next), true
+}
+
+func nextLink(header string) string {
+	for _, match := range linkPattern.FindAllStringSubmatch(header, -1) {
+		for _, param := range strings.Split(match[2], ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+
+			if !strings.EqualFold(key, "rel") {
+				continue
+			}
+
+			for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+				if strings.EqualFold(rel, "next") {
+					return strings.TrimSpace(match[1])
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// Pager walks the pages of a request, Paginate decodes the items of its pages
+type Pager struct {
+	doer        Doer
+	request     *Request
+	strategy    PageStrategy
+	itemsPath   *jsonpath.Path
+	maxPages    int
+	maxItems    int
+	concurrency int
+}
+
+type OptionPaginate func(pager *Pager)
+
+// WithItemsPath reads the items of a page by the JSONPath like `$.data`, the whole body is the item list by default
+func WithItemsPath(path string) OptionPaginate {
+	return func(pager *Pager) {
+		p := jsonpath.MustParse(path)
+		pager.itemsPath = &p
+	}
+}
+
+// WithMaxPages stops after n pages
+func WithMaxPages(n int) OptionPaginate {
+	return func(pager *Pager) {
+		pager.maxPages = n
+	}
+}
+
+// WithMaxItems stops after n items
+func WithMaxItems(n int) OptionPaginate {
+	return func(pager *Pager) {
+		pager.maxItems = n
+	}
+}
+
+// WithPageConcurrency fetches up to n pages at once, it applies to the IndexedPageStrategy strategies only.
+// The pages are still yielded in order, the pages fetched after the last one are dropped.
+func WithPageConcurrency(n int) OptionPaginate {
+	return func(pager *Pager) {
+		pager.concurrency = n
+	}
+}
+
+func NewPager(doer Doer, request *Request, strategy PageStrategy, opts ...OptionPaginate) *Pager {
+	p := &Pager{
+		doer:        doer,
+		request:     request,
+		strategy:    strategy,
+		concurrency: 1,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Walk calls fn with every page until fn returns false, the last page is reached or a request fails.
+// A failed page is passed with its error and ends the walk.
+func (p *Pager) Walk(fn func(page Page, err error) bool) {
+	indexed, isIndexed := p.strategy.(IndexedPageStrategy)
+
+	if isIndexed && p.concurrency > 1 {
+		p.walkConcurrent(indexed, fn)
+		return
+	}
+
+	request, ok := p.strategy.Next(p.request, nil)
+	items := 0
+
+	for index := 0; ok; index++ {
+		page, err := p.fetch(index, request)
+
+		if !fn(page, err) || err != nil {
+			return
+		}
+
+		items += len(page.Items)
+
+		if p.done(index, items) {
+			return
+		}
+
+		request, ok = p.strategy.Next(p.request, &page)
+	}
+}
+
+func (p *Pager) walkConcurrent(strategy IndexedPageStrategy, fn func(page Page, err error) bool) {
+	type result struct {
+		page Page
+		err  error
+	}
+
+	var pending []chan result
+	next := 0
+
+	launch := func() {
+		index := next
+		next++
+
+		results := make(chan result, 1)
+		pending = append(pending, results)
+
+		request := strategy.At(p.request, index)
+
+		go func() {
+			page, err := p.fetch(index, request)
+			results <- result{page: page, err: err}
+		}()
+	}
+
+	for len(pending) < p.concurrency && (p.maxPages <= 0 || next < p.maxPages) {
+		launch()
+	}
+
+	items := 0
+
+	for len(pending) > 0 {
+		r := <-pending[0]
+		pending = pending[1:]
+
+		if !fn(r.page, r.err) || r.err != nil {
+			return
+		}
+
+		items += len(r.page.Items)
+
+		if p.done(r.page.Index, items) {
+			return
+		}
+
+		if _, more := strategy.Next(p.request, &r.page); !more {
+			return
+		}
+
+		if p.maxPages <= 0 || next < p.maxPages {
+			launch()
+		}
+	}
+}
+
+func (p *Pager) done(index, items int) bool {
+	return (p.maxPages > 0 && index+1 >= p.maxPages) || (p.maxItems > 0 && items >= p.maxItems)
+}
+
+func (p *Pager) fetch(index int, request *Request) (Page, error) {
+	page := Page{Index: index, Request: request}
+
+	response, err := p.doer.Do(request)
+	page.Response = response
+
+	if err != nil {
+		return page, err
+	}
+
+	page.Items, err = p.items(response)
+
+	return page, err
+}
+
+func (p *Pager) items(response Response) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+
+	if p.itemsPath == nil {
+		if err := json.Unmarshal(response.Data, &items); err != nil {
+			return nil, fmt.Errorf("room: page is not a json array: %w", err)
+		}
+
+		return items, nil
+	}
+
+	var body any
+
+	if err := json.Unmarshal(response.Data, &body); err != nil {
+		return nil, fmt.Errorf("room: page is not json: %w", err)
+	}
+
+	value, found := p.itemsPath.First(body)
+
+	if !found || value == nil {
+		return nil, nil
+	}
+
+	list, isList := value.([]any)
+
+	if !isList {
+		return nil, fmt.Errorf("room: %s of the page is not an array", p.itemsPath)
+	}
+
+	for _, item := range list {
+		data, err := json.Marshal(item)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, data)
+	}
+
+	return items, nil
+}
+
+// decodeItems decodes the items of the page and calls fn with each of them until fn returns false,
+// it returns false when the walk must stop
+func decodeItems[T any](page Page, seen *int, maxItems int, fn func(item T, err error) bool) bool {
+	for _, data := range page.Items {
+		if maxItems > 0 && *seen >= maxItems {
+			return false
+		}
+
+		*seen++
+
+		var item T
+
+		if err := json.Unmarshal(data, &item); err != nil {
+			fn(item, fmt.Errorf("room: page %d: %w", page.Index, err))
+			return false
+		}
+
+		if !fn(item, nil) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// walkItems walks the pages of the pager and calls fn with their decoded items
+func walkItems[T any](pager *Pager, fn func(item T, err error) bool) {
+	seen := 0
+
+	pager.Walk(func(page Page, err error) bool {
+		if err != nil {
+			var zero T
+			fn(zero, err)
+			return false
+		}
+
+		return decodeItems(page, &seen, pager.maxItems, fn)
+	})
+}
+
+// pageQuery sets params over the query of the request
+type pageQuery struct {
+	query  IQuery
+	params map[string]string
+}
+
+func (q pageQuery) String() string {
+	values := url.Values{}
+
+	if q.query != nil {
+		values, _ = url.ParseQuery(q.query.String())
+	}
+
+	for key, value := range q.params {
+		values.Set(key, value)
+	}
+
+	return values.Encode()
+}
+
+// withQueryParams returns a copy of the request with the params set over its query
+func (r *Request) withQueryParams(params map[string]string) *Request {
+	c := r.Clone()
+	c.Query = pageQuery{query: r.Query, params: params}
+
+	return c
+}
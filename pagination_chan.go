@@ -0,0 +1,39 @@
+package room
+
+import "context"
+
+// PageItem is an item of a paginated request or the error which ended the pagination
+type PageItem[T any] struct {
+	Item T
+	Err  error
+}
+
+// PaginateChan walks the pages of the request with the strategy and sends their items decoded as T,
+// the channel is closed after the last page, the first error or when the context ends.
+// The context cancels the page requests as well, Paginate iterates the items on Go 1.23 and later.
+func PaginateChan[T any](ctx context.Context, doer Doer, request *Request, strategy PageStrategy, opts ...OptionPaginate) <-chan PageItem[T] {
+	request = request.Clone()
+	request.parent = ctx
+
+	return ItemsChan[T](ctx, NewPager(doer, request, strategy, opts...))
+}
+
+// ItemsChan sends the items of the pages of the pager decoded as T until the context ends
+func ItemsChan[T any](ctx context.Context, pager *Pager) <-chan PageItem[T] {
+	items := make(chan PageItem[T])
+
+	go func() {
+		defer close(items)
+
+		walkItems(pager, func(item T, err error) bool {
+			select {
+			case items <- PageItem[T]{Item: item, Err: err}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return items
+}
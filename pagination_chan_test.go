@@ -0,0 +1,69 @@
+package room
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPaginateChan(t *testing.T) {
+	// the pages 1 to 5 have two todos each
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		todos := []map[string]int{}
+		for id := page*2 - 1; page <= 5 && id <= page*2; id++ {
+			todos = append(todos, map[string]int{"id": id})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(todos)
+	}))
+	defer server.Close()
+
+	type todo struct {
+		ID int `json:"id"`
+	}
+
+	var ids []int
+
+	for item := range PaginateChan[todo](context.Background(), NewConnector(server.URL), NewRequest("todos"), PageNumber("page", 1, "", 0)) {
+		if item.Err != nil {
+			t.Fatal(item.Err)
+		}
+
+		ids = append(ids, item.Item.ID)
+	}
+
+	if len(ids) != 10 || ids[9] != 10 {
+		t.Errorf("ids = %v", ids)
+	}
+
+	// the walk stops with the context, the channel is closed without draining it
+	ctx, cancel := context.WithCancel(context.Background())
+
+	items := PaginateChan[todo](ctx, NewConnector(server.URL), NewRequest("todos"), PageNumber("page", 1, "", 0))
+
+	if item := <-items; item.Item.ID != 1 {
+		t.Errorf("first item = %+v", item)
+	}
+
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case _, open := <-items:
+			if !open {
+				return
+			}
+		case <-timeout:
+			t.Fatal("the channel is not closed after the context ends")
+		}
+	}
+}
@@ -0,0 +1,20 @@
+//go:build go1.23
+
+package room
+
+import "iter"
+
+// Paginate walks the pages of the request with the strategy and yields their items decoded as T,
+// the iteration ends after the last page or with the first error, PaginateChan sends them on a channel instead
+//
+//	for todo, err := range room.Paginate[Todo](connector, request, room.Cursor("cursor", "$.next")) {
+func Paginate[T any](doer Doer, request *Request, strategy PageStrategy, opts ...OptionPaginate) iter.Seq2[T, error] {
+	return Items[T](NewPager(doer, request, strategy, opts...))
+}
+
+// Items yields the items of the pages of the pager decoded as T
+func Items[T any](pager *Pager) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		walkItems(pager, yield)
+	}
+}
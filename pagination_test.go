@@ -0,0 +1,207 @@
+//go:build go1.23
+
+package room
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+type pageTodo struct {
+	ID int `json:"id"`
+}
+
+// newPageServer serves the todos 1 to 10, the handler picks the page of the request
+func newPageServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, todos []pageTodo)) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		todos := make([]pageTodo, 0, 10)
+		for i := 1; i <= 10; i++ {
+			todos = append(todos, pageTodo{ID: i})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r, todos)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func window(todos []pageTodo, offset, limit int) []pageTodo {
+	offset = min(offset, len(todos))
+
+	return todos[offset:min(offset+limit, len(todos))]
+}
+
+func collect(t *testing.T, items iter.Seq2[pageTodo, error]) []int {
+	t.Helper()
+
+	var ids []int
+
+	for todo, err := range items {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids = append(ids, todo.ID)
+	}
+
+	return ids
+}
+
+func TestPaginate_PageNumber(t *testing.T) {
+	var hits atomic.Int32
+
+	server := newPageServer(t, func(w http.ResponseWriter, r *http.Request, todos []pageTodo) {
+		hits.Add(1)
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": window(todos, (page-1)*size, size)})
+	})
+
+	connector := NewConnector(server.URL)
+	request := NewRequest("todos", WithQuery(NewQuery(struct {
+		Done bool `url:"done"`
+	}{true})))
+
+	for _, concurrency := range []int{1, 3} {
+		hits.Store(0)
+
+		ids := collect(t, Paginate[pageTodo](connector, request, PageNumber("page", 1, "size", 4), WithItemsPath("$.data"), WithPageConcurrency(concurrency)))
+
+		if !slices.Equal(ids, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}) {
+			t.Errorf("concurrency %d: ids = %v", concurrency, ids)
+		}
+
+		// the third page is short, a concurrent walk may have fetched pages after it
+		if hits.Load() < 3 || hits.Load() > int32(2+concurrency) {
+			t.Errorf("concurrency %d: server got %d requests", concurrency, hits.Load())
+		}
+	}
+}
+
+func TestPaginate_Offset(t *testing.T) {
+	server := newPageServer(t, func(w http.ResponseWriter, r *http.Request, todos []pageTodo) {
+		if r.URL.Query().Get("done") != "true" {
+			t.Errorf("query %s lost the params of the request", r.URL.RawQuery)
+		}
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		_ = json.NewEncoder(w).Encode(window(todos, offset, limit))
+	})
+
+	request := NewRequest("todos", WithQuery(NewQuery(struct {
+		Done bool `url:"done"`
+	}{true})))
+
+	ids := collect(t, Paginate[pageTodo](NewConnector(server.URL), request, Offset("offset", "limit", 5)))
+
+	if len(ids) != 10 {
+		t.Errorf("ids = %v", ids)
+	}
+
+	ids = collect(t, Paginate[pageTodo](NewConnector(server.URL), request, Offset("offset", "limit", 3), WithMaxItems(4)))
+
+	if !slices.Equal(ids, []int{1, 2, 3, 4}) {
+		t.Errorf("ids with max items = %v", ids)
+	}
+}
+
+func TestPaginate_Cursor(t *testing.T) {
+	server := newPageServer(t, func(w http.ResponseWriter, r *http.Request, todos []pageTodo) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		body := map[string]any{"items": window(todos, offset, 4)}
+
+		if offset+4 < len(todos) {
+			body["next"] = offset + 4
+		}
+
+		_ = json.NewEncoder(w).Encode(body)
+	})
+
+	ids := collect(t, Paginate[pageTodo](NewConnector(server.URL), NewRequest("todos"), Cursor("cursor", "$.next"), WithItemsPath("$.items")))
+
+	if len(ids) != 10 || ids[9] != 10 {
+		t.Errorf("ids = %v", ids)
+	}
+}
+
+func TestPaginate_LinkHeader(t *testing.T) {
+	server := newPageServer(t, func(w http.ResponseWriter, r *http.Request, todos []pageTodo) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+
+		if page < 2 {
+			w.Header().Set("Link", fmt.Sprintf(`</todos?p=%d>; rel="next", </todos?p=0>; rel="first"`, page+1))
+		}
+
+		_ = json.NewEncoder(w).Encode(window(todos, page*4, 4))
+	})
+
+	ids := collect(t, Paginate[pageTodo](NewConnector(server.URL), NewRequest("todos"), LinkHeader(), WithMaxPages(5)))
+
+	if len(ids) != 10 {
+		t.Errorf("ids = %v", ids)
+	}
+
+	ids = collect(t, Paginate[pageTodo](NewConnector(server.URL), NewRequest("todos"), LinkHeader(), WithMaxPages(2)))
+
+	if len(ids) != 8 {
+		t.Errorf("ids with max pages = %v", ids)
+	}
+}
+
+func TestPaginate_Error(t *testing.T) {
+	server := newPageServer(t, func(w http.ResponseWriter, r *http.Request, todos []pageTodo) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(window(todos, 0, 2))
+	})
+
+	request := NewRequest("todos", WithExpectedStatus(http.StatusOK))
+
+	var ids []int
+	var errs []error
+
+	for todo, err := range Paginate[pageTodo](NewConnector(server.URL), request, PageNumber("page", 1, "", 0)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		ids = append(ids, todo.ID)
+	}
+
+	if len(ids) != 2 || len(errs) != 1 {
+		t.Errorf("ids = %v, errs = %v", ids, errs)
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	for header, want := range map[string]string{
+		`<https://api.example.com/items?page=2>; rel="next"`:                         "https://api.example.com/items?page=2",
+		`<https://a.io/1>; rel="prev", <https://a.io/3>; title="x"; rel="last next"`: "https://a.io/3",
+		`<https://a.io/1>; rel=next`:                                                 "https://a.io/1",
+		`<https://a.io/1>; rel="nextpage"`:                                           "",
+		``:                                                                           "",
+	} {
+		if got := nextLink(header); got != want {
+			t.Errorf("nextLink(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
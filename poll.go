@@ -0,0 +1,262 @@
+package room
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/WEG-Technology/room/jsonpath"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrJobFailed is returned by AwaitCompletion when the status of the job matches the failure condition
+var ErrJobFailed = errors.New("room: job failed")
+
+// ErrJobStatus is returned by AwaitCompletion when the status url answers with a client error
+var ErrJobStatus = errors.New("room: job status is not available")
+
+type poller struct {
+	statusUrlPath *jsonpath.Path
+	resultUrlPath *jsonpath.Path
+	done          *jsonpath.Condition
+	failed        *jsonpath.Condition
+	interval      time.Duration
+	maxInterval   time.Duration
+	requestOpts   []OptionRequest
+}
+
+type OptionPoll func(poller *poller)
+
+// WithDoneWhen completes the job when the status matches the condition like `$.status == "done"`,
+// by default the job is complete once the status url answers a 2xx other than 202 Accepted or a 3xx
+func WithDoneWhen(condition string) OptionPoll {
+	return func(poller *poller) {
+		c := jsonpath.MustParseCondition(condition)
+		poller.done = &c
+	}
+}
+
+// WithFailedWhen fails the job with ErrJobFailed when the status matches the condition like `$.status == "error"`
+func WithFailedWhen(condition string) OptionPoll {
+	return func(poller *poller) {
+		c := jsonpath.MustParseCondition(condition)
+		poller.failed = &c
+	}
+}
+
+// WithStatusUrlPath reads the status url from the accepted response by the JSONPath instead of the Location header
+func WithStatusUrlPath(path string) OptionPoll {
+	return func(poller *poller) {
+		p := jsonpath.MustParse(path)
+		poller.statusUrlPath = &p
+	}
+}
+
+// WithResultUrlPath reads the url of the result from the completed status by the JSONPath,
+// the Location header of the completed status is followed otherwise and the status itself is the result without it
+func WithResultUrlPath(path string) OptionPoll {
+	return func(poller *poller) {
+		p := jsonpath.MustParse(path)
+		poller.resultUrlPath = &p
+	}
+}
+
+// WithPollInterval sets the first interval between the polls, it doubles after every poll up to the max interval.
+// It is 500 milliseconds up to 10 seconds by default, a Retry-After header of the status takes precedence.
+func WithPollInterval(interval, maxInterval time.Duration) OptionPoll {
+	return func(poller *poller) {
+		poller.interval = interval
+		poller.maxInterval = maxInterval
+	}
+}
+
+// WithPollRequestOptions applies the options to the status and result requests, like labels or headers
+func WithPollRequestOptions(opts ...OptionRequest) OptionPoll {
+	return func(poller *poller) {
+		poller.requestOpts = append(poller.requestOpts, opts...)
+	}
+}
+
+// AwaitCompletion follows the status url of a 202 Accepted response until the job is done, fails or the context expires,
+// then it fetches and returns the result. Other responses are returned as they are.
+// A 4xx of the status url fails with ErrJobStatus, a 5xx is polled again.
+func AwaitCompletion(ctx context.Context, doer Doer, accepted Response, opts ...OptionPoll) (Response, error) {
+	p := &poller{
+		interval:    500 * time.Millisecond,
+		maxInterval: 10 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if accepted.StatusCode != http.StatusAccepted {
+		return accepted, nil
+	}
+
+	statusUrl, err := p.url(accepted, p.statusUrlPath)
+
+	if err != nil {
+		return accepted, err
+	}
+
+	if statusUrl == "" {
+		return accepted, errors.New("room: the accepted response has no status url")
+	}
+
+	interval := p.interval
+	last := accepted
+
+	for {
+		if err = wait(ctx, retryAfter(last, interval)); err != nil {
+			return last, fmt.Errorf("room: job is not completed: %w", err)
+		}
+
+		status, err := doer.Do(p.request(ctx, statusUrl))
+
+		if err != nil {
+			return status, err
+		}
+
+		last = status
+
+		// the status url may fail for a while, a server error is polled again until the context expires
+		if status.StatusCode >= http.StatusInternalServerError {
+			interval = min(interval*2, p.maxInterval)
+			continue
+		}
+
+		if status.StatusCode >= http.StatusBadRequest {
+			return status, fmt.Errorf("%w: status url answered %d", ErrJobStatus, status.StatusCode)
+		}
+
+		body := decodeBody(status)
+
+		if p.failed != nil && p.failed.Match(body) {
+			return status, fmt.Errorf("%w: %s", ErrJobFailed, p.failed)
+		}
+
+		if p.completed(status, body) {
+			return p.result(ctx, doer, status, body)
+		}
+
+		interval = min(interval*2, p.maxInterval)
+	}
+}
+
+func (p *poller) completed(status Response, body any) bool {
+	if p.done != nil {
+		return p.done.Match(body)
+	}
+
+	return status.StatusCode != http.StatusAccepted
+}
+
+func (p *poller) result(ctx context.Context, doer Doer, status Response, body any) (Response, error) {
+	resultUrl := ""
+
+	if p.resultUrlPath != nil {
+		if value, found := p.resultUrlPath.First(body); found && value != nil {
+			resultUrl = fmt.Sprint(value)
+		}
+	} else {
+		resultUrl = status.Header.Get("Location")
+	}
+
+	if resultUrl == "" {
+		return status, nil
+	}
+
+	return doer.Do(p.request(ctx, resolveUrl(status, resultUrl)))
+}
+
+// url reads the url from the body by the path or from the Location header, relative urls are resolved against the request
+func (p *poller) url(response Response, path *jsonpath.Path) (string, error) {
+	location := response.Header.Get("Location")
+
+	if path != nil {
+		value, found := path.First(decodeBody(response))
+
+		if !found || value == nil {
+			return "", fmt.Errorf("room: %s is not found in the accepted response", path)
+		}
+
+		location = fmt.Sprint(value)
+	}
+
+	if location == "" {
+		return "", nil
+	}
+
+	return resolveUrl(response, location), nil
+}
+
+func resolveUrl(response Response, location string) string {
+	base, err := url.Parse(response.Request.URI.String())
+
+	if err != nil {
+		return location
+	}
+
+	ref, err := url.Parse(location)
+
+	if err != nil {
+		return location
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+func decodeBody(response Response) any {
+	var body any
+
+	_ = json.Unmarshal(response.Data, &body)
+
+	return body
+}
+
+// retryAfter returns the delay of the Retry-After header in seconds, or the interval without it
+func retryAfter(response Response, interval time.Duration) time.Duration {
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return interval
+}
+
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// request builds a GET request of the url which is cancelled with the context,
+// the credentials are not sent when the url is on another host
+func (p *poller) request(ctx context.Context, u string) *Request {
+	opts := append([]OptionRequest{WithMethod(GET), WithContextBuilder(parentContextBuilder{parent: ctx})}, p.requestOpts...)
+
+	r := NewRequest(u, opts...)
+	r.followed = true
+
+	return r
+}
+
+// parentContextBuilder derives the request context from a parent context
+type parentContextBuilder struct {
+	parent context.Context
+}
+
+func (b parentContextBuilder) Build() Context {
+	ctx, cancel := context.WithCancel(b.parent)
+
+	return Context{Ctx: ctx, Cancel: cancel}
+}
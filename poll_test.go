@@ -0,0 +1,194 @@
+package room
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newJobServer(t *testing.T, finalStatus string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var polls atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/jobs/1")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/jobs/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if polls.Add(1) < 3 {
+			_, _ = w.Write([]byte(`{"status":"running"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"status":"` + finalStatus + `","result":"/reports/1"}`))
+	})
+	mux.HandleFunc("/reports/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"report":"ready"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, &polls
+}
+
+func TestAwaitCompletion(t *testing.T) {
+	server, polls := newJobServer(t, "done")
+	connector := NewConnector(server.URL)
+
+	accepted, err := connector.Do(NewRequest("jobs", WithMethod(POST)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := AwaitCompletion(context.Background(), connector, accepted,
+		WithDoneWhen(`$.status == "done"`),
+		WithFailedWhen(`$.status == "failed"`),
+		WithResultUrlPath("$.result"),
+		WithPollInterval(time.Millisecond, 5*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.ResponseBody()["report"] != "ready" || polls.Load() != 3 {
+		t.Errorf("AwaitCompletion() = %s after %d polls", result.Data, polls.Load())
+	}
+}
+
+func TestAwaitCompletion_Failed(t *testing.T) {
+	server, _ := newJobServer(t, "failed")
+	connector := NewConnector(server.URL)
+
+	accepted, _ := connector.Do(NewRequest("jobs", WithMethod(POST)))
+
+	status, err := AwaitCompletion(context.Background(), connector, accepted,
+		WithDoneWhen(`$.status == "done"`),
+		WithFailedWhen(`$.status == "failed"`),
+		WithPollInterval(time.Millisecond, time.Millisecond),
+	)
+	if !errors.Is(err, ErrJobFailed) || status.ResponseBody()["status"] != "failed" {
+		t.Errorf("AwaitCompletion() = %s, %v, want ErrJobFailed", status.Data, err)
+	}
+}
+
+func TestAwaitCompletion_Deadline(t *testing.T) {
+	server, polls := newJobServer(t, "done")
+	connector := NewConnector(server.URL)
+
+	accepted, _ := connector.Do(NewRequest("jobs", WithMethod(POST)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err := AwaitCompletion(ctx, connector, accepted, WithDoneWhen(`$.status == "done"`), WithPollInterval(20*time.Millisecond, time.Second))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("AwaitCompletion() error = %v, want deadline exceeded", err)
+	}
+
+	if polls.Load() != 1 {
+		t.Errorf("status was polled %d times, want 1 before the deadline", polls.Load())
+	}
+}
+
+func TestAwaitCompletion_NotAccepted(t *testing.T) {
+	response := Response{StatusCode: http.StatusOK}
+
+	result, err := AwaitCompletion(context.Background(), DoerFunc(func(request *Request) (Response, error) {
+		t.Fatal("a completed response must not be polled")
+		return Response{}, nil
+	}), response)
+
+	if err != nil || result.StatusCode != http.StatusOK {
+		t.Errorf("AwaitCompletion() = %d, %v", result.StatusCode, err)
+	}
+}
+
+func TestAwaitCompletion_ForeignHost(t *testing.T) {
+	var authorization, cookie string
+
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization, cookie = r.Header.Get("Authorization"), r.Header.Get("Cookie")
+		_, _ = w.Write([]byte(`{"report":"ready"}`))
+	}))
+	defer storage.Close()
+
+	// the result is on another host, like a presigned storage url
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", strings.Replace(storage.URL, "127.0.0.1", "localhost", 1)+"/reports/1")
+	}))
+	defer api.Close()
+
+	connector := NewConnector(api.URL, WithHeaderConnector(NewHeader().Add("Authorization", "Bearer secret")))
+
+	accepted := Response{StatusCode: http.StatusAccepted, Header: NewHeader().Add("Location", api.URL+"/jobs/1")}
+	accepted.Request.URI = NewURI(api.URL + "/jobs")
+
+	result, err := AwaitCompletion(context.Background(), connector, accepted, WithPollInterval(time.Millisecond, time.Millisecond),
+		WithPollRequestOptions(WithCookies(&http.Cookie{Name: "session", Value: "secret"})))
+
+	if err != nil || result.ResponseBody()["report"] != "ready" {
+		t.Fatalf("AwaitCompletion() = %s, %v", result.Data, err)
+	}
+
+	if authorization != "" || cookie != "" {
+		t.Errorf("the credentials are sent to another host: %q, %q", authorization, cookie)
+	}
+
+	// the status url on the host of the connector keeps them
+	followed := NewRequest("jobs/1").follow(api.URL + "/jobs/1")
+
+	if response, _ := connector.Do(followed); response.Request.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("the credentials are dropped for the same host: %v", response.Request.Header)
+	}
+}
+
+func TestConnector_FollowedCredentials(t *testing.T) {
+	connector := NewConnector("https://api.example.com", WithHeaderConnector(NewHeader().Add("Authorization", "Bearer secret")))
+
+	for u, kept := range map[string]bool{
+		"https://api.example.com/jobs/1":    true,
+		"https://eu.api.example.com/jobs/1": false,
+		"http://api.example.com/jobs/1":     false,
+	} {
+		prepared := connector.prepare(NewRequest("jobs").follow(u))
+
+		if (prepared.Header.Get("Authorization") == "Bearer secret") != kept {
+			t.Errorf("prepare() of %s sent the header %v, expected the credentials kept: %v", u, prepared.Header, kept)
+		}
+	}
+}
+
+func TestAwaitCompletion_StatusError(t *testing.T) {
+	var polls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the status url is unavailable once, then the job is gone
+		if polls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	accepted := Response{StatusCode: http.StatusAccepted, Header: NewHeader().Add("Location", server.URL+"/jobs/1")}
+	accepted.Request.URI = NewURI(server.URL + "/jobs")
+
+	status, err := AwaitCompletion(context.Background(), NewConnector(server.URL), accepted, WithPollInterval(time.Millisecond, time.Millisecond))
+
+	if !errors.Is(err, ErrJobStatus) || status.StatusCode != http.StatusNotFound || polls.Load() != 2 {
+		t.Errorf("AwaitCompletion() = %d, %v after %d polls", status.StatusCode, err, polls.Load())
+	}
+}
@@ -0,0 +1,299 @@
+package room
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/WEG-Technology/room/jsonpath"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const RedactedValue = "[REDACTED]"
+
+var (
+	// DefaultRedactedHeaders are always redacted by a Redactor
+	DefaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	// DefaultRedactedFields are always redacted by a Redactor
+	DefaultRedactedFields = []string{"password", "token", "access_token", "refresh_token", "client_secret"}
+)
+
+// Redactor hides sensitive headers, query params and body fields before they are logged or exported
+type Redactor struct {
+	headers map[string]bool
+	keys    map[string]bool
+	paths   []jsonpath.Path
+	// invalid is set by a field which is not a valid JSONPath, the bodies are redacted as a whole then
+	invalid bool
+}
+
+// NewRedactor creates a redactor for the given headers and fields on top of the defaults.
+// Fields starting with `$` are JSONPath expressions like `$.user.secret`,
+// any other field is a key which is redacted at any depth of the body.
+// A field which is not a valid JSONPath redacts the whole bodies, so a typo does not leak the field,
+// ValidateRedactedFields reports such fields.
+func NewRedactor(headers []string, fields []string) Redactor {
+	r := Redactor{
+		headers: map[string]bool{},
+		keys:    map[string]bool{},
+	}
+
+	for _, header := range append(append([]string(nil), DefaultRedactedHeaders...), headers...) {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	for _, field := range append(append([]string(nil), DefaultRedactedFields...), fields...) {
+		if strings.HasPrefix(field, "$") {
+			if p, err := jsonpath.Parse(field); err == nil {
+				r.paths = append(r.paths, p)
+			} else {
+				r.invalid = true
+			}
+			continue
+		}
+
+		r.keys[strings.ToLower(field)] = true
+	}
+
+	return r
+}
+
+// ValidateRedactedFields returns an error for the fields which are not valid JSONPath expressions
+func ValidateRedactedFields(fields ...string) error {
+	for _, field := range fields {
+		if !strings.HasPrefix(field, "$") {
+			continue
+		}
+
+		if _, err := jsonpath.Parse(field); err != nil {
+			return fmt.Errorf("room: redacted field %q is not a valid JSONPath: %w", field, err)
+		}
+	}
+
+	return nil
+}
+
+// Header returns a copy of the header with the sensitive values redacted
+func (r Redactor) Header(header http.Header) http.Header {
+	redacted := header.Clone()
+
+	for key := range redacted {
+		if r.headers[http.CanonicalHeaderKey(key)] {
+			redacted[key] = []string{RedactedValue}
+		}
+	}
+
+	return redacted
+}
+
+// URL returns the url with the sensitive query params redacted
+func (r Redactor) URL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+
+	redacted := *u
+	redacted.RawQuery = r.values(u.Query()).Encode()
+
+	return redacted.String()
+}
+
+// Body redacts json, xml, form and multipart form bodies, other bodies are returned as they are
+func (r Redactor) Body(contentType string, body []byte) []byte {
+	trimmed := bytes.TrimSpace(body)
+
+	if len(trimmed) == 0 {
+		return body
+	}
+
+	if r.invalid {
+		return []byte(RedactedValue)
+	}
+
+	switch {
+	case strings.Contains(contentType, headerValueMultipartFormData):
+		return r.multipart(contentType, body)
+	case strings.Contains(contentType, headerValueFormEncoded):
+		values, err := url.ParseQuery(string(body))
+
+		if err != nil {
+			return body
+		}
+
+		return []byte(r.values(values).Encode())
+	case strings.Contains(contentType, "json") || trimmed[0] == '{' || trimmed[0] == '[':
+		var document any
+
+		if err := json.Unmarshal(trimmed, &document); err != nil {
+			return body
+		}
+
+		redacted, err := json.Marshal(r.Document(document))
+
+		if err != nil {
+			return body
+		}
+
+		return redacted
+	case strings.Contains(contentType, "xml") || trimmed[0] == '<':
+		return r.xml(body)
+	}
+
+	return body
+}
+
+// xml redacts the content of the elements by their local names, like the wsse:Password of a soap envelope.
+// The rest of the document is kept byte for byte.
+func (r Redactor) xml(body []byte) []byte {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+
+	var redacted bytes.Buffer
+
+	// depth counts the open elements inside a redacted element, its content starts at start
+	var written, start int64
+	depth := 0
+
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.RawToken()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return body
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth > 0 {
+				depth++
+			} else if r.keys[strings.ToLower(t.Name.Local)] {
+				depth, start = 1, decoder.InputOffset()
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				continue
+			}
+
+			if depth--; depth == 0 {
+				redacted.Write(body[written:start])
+
+				// a self-closing element has no content to redact
+				if offset > start {
+					redacted.WriteString(RedactedValue)
+				}
+
+				written = offset
+			}
+		}
+	}
+
+	redacted.Write(body[written:])
+
+	return redacted.Bytes()
+}
+
+// multipart redacts the form fields of a multipart body by their names, the parts keep their headers
+func (r Redactor) multipart(contentType string, body []byte) []byte {
+	_, params, err := mime.ParseMediaType(contentType)
+
+	if err != nil || params["boundary"] == "" {
+		return body
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+
+	var redacted bytes.Buffer
+
+	writer := multipart.NewWriter(&redacted)
+
+	if err = writer.SetBoundary(params["boundary"]); err != nil {
+		return body
+	}
+
+	for {
+		part, err := reader.NextRawPart()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return body
+		}
+
+		data, err := io.ReadAll(part)
+
+		if err != nil {
+			return body
+		}
+
+		if r.keys[strings.ToLower(part.FormName())] {
+			data = []byte(RedactedValue)
+		}
+
+		w, err := writer.CreatePart(part.Header)
+
+		if err != nil {
+			return body
+		}
+
+		_, _ = w.Write(data)
+	}
+
+	if err = writer.Close(); err != nil {
+		return body
+	}
+
+	return redacted.Bytes()
+}
+
+// Document redacts a decoded json document in place and returns it
+func (r Redactor) Document(document any) any {
+	r.redactKeys(document)
+
+	for _, p := range r.paths {
+		p.Replace(document, func(any) any { return RedactedValue })
+	}
+
+	return document
+}
+
+func (r Redactor) redactKeys(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if r.keys[strings.ToLower(key)] {
+				v[key] = RedactedValue
+				continue
+			}
+
+			r.redactKeys(child)
+		}
+	case []any:
+		for _, child := range v {
+			r.redactKeys(child)
+		}
+	}
+}
+
+func (r Redactor) values(values url.Values) url.Values {
+	redacted := url.Values{}
+
+	for key, value := range values {
+		if r.keys[strings.ToLower(key)] {
+			redacted[key] = []string{RedactedValue}
+		} else {
+			redacted[key] = value
+		}
+	}
+
+	return redacted
+}
@@ -0,0 +1,113 @@
+package room
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_Header(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret")
+	header.Set("X-Api-Key", "secret")
+	header.Set("Accept", "application/json")
+
+	redacted := NewRedactor([]string{"x-api-key"}, nil).Header(header)
+
+	if redacted.Get("Authorization") != RedactedValue || redacted.Get("X-Api-Key") != RedactedValue {
+		t.Errorf("Redactor Header() did not redact the sensitive headers, got %v", redacted)
+	}
+
+	if redacted.Get("Accept") != "application/json" {
+		t.Errorf("Redactor Header() redacted Accept, got %s", redacted.Get("Accept"))
+	}
+
+	if header.Get("Authorization") != "Bearer secret" {
+		t.Error("Redactor Header() modified the original header")
+	}
+}
+
+func TestRedactor_Body(t *testing.T) {
+	body := []byte(`{"username":"john","password":"secret","user":{"Token":"abc","secret":"x","name":"john"}}`)
+
+	redacted := NewRedactor(nil, []string{"$.user.secret"}).Body(headerValueApplicationJson, body)
+
+	var document map[string]any
+	if err := json.Unmarshal(redacted, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	user := document["user"].(map[string]any)
+
+	if document["password"] != RedactedValue || user["Token"] != RedactedValue || user["secret"] != RedactedValue {
+		t.Errorf("Redactor Body() did not redact the sensitive fields, got %s", redacted)
+	}
+
+	if document["username"] != "john" || user["name"] != "john" {
+		t.Errorf("Redactor Body() redacted other fields, got %s", redacted)
+	}
+
+	form := NewRedactor(nil, nil).Body(headerValueFormEncoded, []byte("username=john&password=secret"))
+
+	if string(form) != "password=%5BREDACTED%5D&username=john" {
+		t.Errorf("Redactor Body() returned %s for a form body", form)
+	}
+}
+
+func TestRedactor_URL(t *testing.T) {
+	u, _ := url.Parse("https://example.com/todos?token=abc&limit=10")
+
+	expected := "https://example.com/todos?limit=10&token=%5BREDACTED%5D"
+
+	if redacted := NewRedactor(nil, nil).URL(u); redacted != expected {
+		t.Errorf("Redactor URL() returned %s, expected %s", redacted, expected)
+	}
+}
+
+func TestRedactor_InvalidField(t *testing.T) {
+	if err := ValidateRedactedFields("password", "$.user.secret", "$.user[secret"); err == nil {
+		t.Error("ValidateRedactedFields() accepted an invalid JSONPath")
+	}
+
+	// the invalid path can not tell the field, so the whole body is redacted
+	redacted := NewRedactor(nil, []string{"$.user[secret"}).Body(headerValueApplicationJson, []byte(`{"user":{"secret":"x"}}`))
+
+	if string(redacted) != RedactedValue {
+		t.Errorf("Redactor Body() = %s", redacted)
+	}
+}
+
+func TestRedactor_MultipartBody(t *testing.T) {
+	parser := NewMultipartFormDataBodyParser(map[string]string{"username": "john", "password": "secret"})
+
+	redacted := string(NewRedactor(nil, nil).Body(parser.ContentType(), parser.Parse().Bytes()))
+
+	if strings.Contains(redacted, "secret") || !strings.Contains(redacted, RedactedValue) || !strings.Contains(redacted, "john") {
+		t.Errorf("Redactor Body() = %s", redacted)
+	}
+}
+
+func TestRedactor_SOAPBody(t *testing.T) {
+	parser := NewSOAPBodyParser(NewSOAPOperation("GetOrder", "urn:orders", map[string]any{"id": 7}), WithUsernameToken("john", "s3cr&t"))
+
+	body := parser.Parse().Bytes()
+	redacted := string(NewRedactor(nil, nil).Body(parser.ContentType(), body))
+
+	if strings.Contains(redacted, "s3cr") || !strings.Contains(redacted, ">"+RedactedValue+"</wsse:Password>") || !strings.Contains(redacted, "john") {
+		t.Errorf("Redactor Body() = %s", redacted)
+	}
+
+	// the rest of the envelope is kept as it is
+	if strings.Replace(redacted, RedactedValue, "s3cr&amp;t", 1) != string(body) {
+		t.Errorf("Redactor Body() changed the envelope: %s", redacted)
+	}
+
+	// nested and empty elements, and an invalid document which is kept as it is
+	document := `<auth><Token><value>abc</value></Token><password/><user>john</user></auth>`
+
+	if redacted := string(NewRedactor(nil, nil).Body("application/xml", []byte(document))); redacted != `<auth><Token>`+RedactedValue+`</Token><password/><user>john</user></auth>` {
+		t.Errorf("Redactor Body() = %s", redacted)
+	}
+}
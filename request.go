@@ -1,7 +1,14 @@
 package room
 
 import (
+	"context"
+	"fmt"
+	"github.com/WEG-Technology/room/segment"
+	"github.com/WEG-Technology/room/store"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 )
@@ -28,6 +35,27 @@ type Request struct {
 	BodyParser     IBodyParser
 	contextBuilder IContextBuilder
 	Cookies        []*http.Cookie
+	pathParams     map[string]string
+	expectedStatus []int
+	labels         map[string]string
+	attempt        int
+	transport      http.RoundTripper
+	hedging        *Hedging
+	attemptTimeout time.Duration
+	compression    compression
+	parent         context.Context
+	// followed is set for the urls given by a partner, like pagination links and job status urls
+	followed bool
+}
+
+// UnexpectedStatusError is returned when the response status is not one of the expected statuses of the request
+type UnexpectedStatusError struct {
+	StatusCode int
+	Expected   []int
+}
+
+func (e UnexpectedStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d, expected one of %v", e.StatusCode, e.Expected)
 }
 
 // NewRequest creates a new request
@@ -54,39 +82,108 @@ func NewRequest(path string, opts ...OptionRequest) *Request {
 }
 
 func (r *Request) Send() (Response, error) {
-	c := new(http.Client)
+	ctx := r.context()
+
+	// the response body is read before returning, so the deadline can be released
+	if ctx.Cancel != nil {
+		defer ctx.Cancel()
+	}
+
+	if r.hedging.applies(r.Method) {
+		return r.sendHedged(ctx.Ctx)
+	}
+
+	return r.sendAttempt(ctx.Ctx)
+}
+
+func (r *Request) sendAttempt(ctx context.Context) (Response, error) {
+	if r.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.attemptTimeout)
+		defer cancel()
+	}
+
+	c := &http.Client{Transport: r.transport}
+
+	timing := segment.NewHTTPTiming()
 
-	req := r.request()
+	req := r.request(ctx)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), timing.ClientTrace()))
+
+	timing.Start()
 
 	response, err := c.Do(req)
 
 	if err != nil {
-		return NewErrorResponse(req, err)
+		res, err := NewErrorResponse(req, err)
+		res.Timing = timing.End()
+
+		return res, err
 	}
 
-	return NewResponse(response, req), nil
+	timing.HeadersReceived()
+
+	res := NewResponse(response, req)
+	res.Timing = timing.End()
+
+	if len(r.expectedStatus) > 0 && !slices.Contains(r.expectedStatus, res.StatusCode) {
+		return res, UnexpectedStatusError{StatusCode: res.StatusCode, Expected: r.expectedStatus}
+	}
+
+	return res, nil
 }
 
-func (r *Request) request() *http.Request {
-	var context Context
+// context builds the deadline of the whole request, 30 seconds unless a context builder is set
+func (r *Request) context() Context {
+	built := NewContextBuilder(30 * time.Second).Build()
 
 	if r.contextBuilder != nil {
-		context = r.contextBuilder.Build()
-	} else {
-		context = NewContextBuilder(30 * time.Second).Build()
+		built = r.contextBuilder.Build()
 	}
 
+	if r.parent == nil {
+		return built
+	}
+
+	ctx, cancel := context.WithCancel(built.Ctx)
+	stop := context.AfterFunc(r.parent, cancel)
+
+	return Context{Ctx: ctx, Cancel: func() {
+		stop()
+		cancel()
+
+		if built.Cancel != nil {
+			built.Cancel()
+		}
+	}}
+}
+
+func (r *Request) request(ctx context.Context) *http.Request {
+	path := r.resolvePath()
+
 	if r.Query != nil && r.Query.String() != "" {
-		r.URI = NewURI(r.path + "?" + r.Query.String())
+		r.URI = NewURI(path + "?" + r.Query.String())
 	} else {
-		r.URI = NewURI(r.path)
+		r.URI = NewURI(path)
 	}
 
-	req, _ := http.NewRequestWithContext(context.Ctx, r.Method.String(), r.URI.String(), r.BodyParser.Parse())
+	if r.BodyParser == nil {
+		r.BodyParser = dumpBody{}
+	}
+
+	ctx = contextWithInfo(ctx, RequestInfo{
+		PathTemplate: NewURI(r.path).Path(),
+		Labels:       r.labels,
+		Attempt:      r.attempt,
+	})
+
+	body, encoding := r.compression.compress(r.BodyParser.Parse())
+
+	req, _ := http.NewRequestWithContext(ctx, r.Method.String(), r.URI.httpString(), body)
 
 	if r.Header != nil {
 		r.Header.Properties().Each(func(k string, v any) {
-			req.Header.Add(k, v.(string))
+			req.Header.Add(k, fmt.Sprint(v))
 		})
 	}
 
@@ -94,6 +191,21 @@ func (r *Request) request() *http.Request {
 		req.Header.Set("Content-Type", r.BodyParser.ContentType())
 	}
 
+	if headerer, ok := r.BodyParser.(bodyHeaderer); ok {
+		for key, value := range headerer.headers() {
+			req.Header.Set(key, value)
+		}
+	}
+
+	if encoding != "" {
+		req.Header.Set(headerKeyContentEncoding, encoding)
+	}
+
+	// a custom Accept-Encoding turns off the transparent gzip of the transport, the responses are decoded by setData
+	if r.compression.acceptEncoding != "" && req.Header.Get(headerKeyAcceptEncoding) == "" {
+		req.Header.Set(headerKeyAcceptEncoding, r.compression.acceptEncoding)
+	}
+
 	if r.Cookies != nil && len(r.Cookies) > 0 {
 		for _, cookie := range r.Cookies {
 			req.AddCookie(cookie)
@@ -103,7 +215,59 @@ func (r *Request) request() *http.Request {
 	return req
 }
 
+// resolvePath replaces `{key}` placeholders in the path with the escaped path params
+func (r *Request) resolvePath() string {
+	path := r.path
+
+	for key, value := range r.pathParams {
+		path = strings.ReplaceAll(path, "{"+key+"}", url.PathEscape(value))
+	}
+
+	return path
+}
+
+// Clone returns a copy of the request that can be modified and sent without affecting the original.
+// Header, cookies and path params are copied, body parser and query are shared as they are read-only while sending.
+func (r *Request) Clone() *Request {
+	c := *r
+
+	if r.Header != nil {
+		c.Header = NewHeader(store.NewMapStore(r.Header.Properties().All()))
+	}
+
+	if r.Cookies != nil {
+		c.Cookies = append([]*http.Cookie(nil), r.Cookies...)
+	}
+
+	if r.expectedStatus != nil {
+		c.expectedStatus = append([]int(nil), r.expectedStatus...)
+	}
+
+	if r.labels != nil {
+		c.labels = make(map[string]string, len(r.labels))
+
+		for key, value := range r.labels {
+			c.labels[key] = value
+		}
+	}
+
+	if r.pathParams != nil {
+		c.pathParams = make(map[string]string, len(r.pathParams))
+
+		for key, value := range r.pathParams {
+			c.pathParams[key] = value
+		}
+	}
+
+	return &c
+}
+
 func (r *Request) SetBaseUrl(baseUrl string) *Request {
+	// an absolute url out of the base url, like a pagination link of another host, is sent as it is
+	if trimmed := strings.TrimSuffix(baseUrl, "/"); strings.Contains(r.path, "://") && r.path != trimmed && !strings.HasPrefix(r.path, trimmed+"/") {
+		return r
+	}
+
 	if strings.HasPrefix(r.path, "/") {
 		r.path = r.path[1:]
 	}
@@ -121,13 +285,51 @@ func (r *Request) SetBaseUrl(baseUrl string) *Request {
 	return r
 }
 
+// follow returns a copy of the request for a url given by a partner, the connector does not send credentials to another host
+func (r *Request) follow(u string) *Request {
+	c := r.Clone()
+	c.path = u
+	c.Query = nil
+	c.followed = true
+
+	return c
+}
+
+// withoutCredentials drops the headers and cookies which authenticate the request, like net/http does on redirects
+func (r *Request) withoutCredentials() *Request {
+	r.Cookies = nil
+
+	if r.Header == nil {
+		return r
+	}
+
+	var keys []string
+
+	r.Header.Properties().Each(func(key string, _ any) {
+		for _, sensitive := range []string{"Authorization", "Proxy-Authorization", "Cookie", "Www-Authenticate"} {
+			if strings.EqualFold(key, sensitive) {
+				keys = append(keys, key)
+			}
+		}
+	})
+
+	for _, key := range keys {
+		r.Header.Properties().Remove(key)
+	}
+
+	return r
+}
+
+// MergeHeader merges the given header under a copy of the request header, request values take precedence
 func (r *Request) MergeHeader(header IHeader) *Request {
 	if header != nil {
-		if r.Header == nil {
-			r.Header = header
-		} else {
-			r.Header.Merge(header)
+		merged := NewHeader().Merge(header)
+
+		if r.Header != nil {
+			merged.Merge(r.Header)
 		}
+
+		r.Header = merged
 	}
 
 	return r
@@ -175,8 +377,48 @@ func WithContextBuilder(contextBuilder IContextBuilder) OptionRequest {
 	}
 }
 
+// WithParentContext cancels the request with the context, the deadline of the context builder still applies
+func WithParentContext(ctx context.Context) OptionRequest {
+	return func(request *Request) {
+		request.parent = ctx
+	}
+}
+
 func WithCookies(cookies ...*http.Cookie) OptionRequest {
 	return func(request *Request) {
 		request.Cookies = cookies
 	}
 }
+
+// WithPathParams sets the values for `{key}` placeholders in the request path
+func WithPathParams(params map[string]string) OptionRequest {
+	return func(request *Request) {
+		if request.pathParams == nil {
+			request.pathParams = make(map[string]string, len(params))
+		}
+
+		for key, value := range params {
+			request.pathParams[key] = value
+		}
+	}
+}
+
+// WithExpectedStatus makes Send return an UnexpectedStatusError when the response status is not one of the given codes
+func WithExpectedStatus(codes ...int) OptionRequest {
+	return func(request *Request) {
+		request.expectedStatus = codes
+	}
+}
+
+// WithLabels attaches labels to the request, they are passed to the middlewares by RequestInfo
+func WithLabels(labels map[string]string) OptionRequest {
+	return func(request *Request) {
+		if request.labels == nil {
+			request.labels = make(map[string]string, len(labels))
+		}
+
+		for key, value := range labels {
+			request.labels[key] = value
+		}
+	}
+}
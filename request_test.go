@@ -0,0 +1,86 @@
+package room
+
+import (
+	"github.com/WEG-Technology/room/store"
+	"testing"
+)
+
+func TestRequest_Clone(t *testing.T) {
+	original := NewRequest("todos/{id}",
+		WithHeader(NewHeader(store.NewMapStore(map[string]any{"X-Key": "original"}))),
+		WithPathParams(map[string]string{"id": "1"}),
+	)
+
+	clone := original.Clone()
+	clone.Header.Add("X-Key", "clone")
+	WithPathParams(map[string]string{"id": "2"})(clone)
+
+	if original.Header.Get("X-Key") != "original" {
+		t.Errorf("Clone() shares the header with the original request, got %s", original.Header.Get("X-Key"))
+	}
+
+	if original.resolvePath() != "todos/1" {
+		t.Errorf("Clone() shares the path params with the original request, got %s", original.resolvePath())
+	}
+
+	if clone.resolvePath() != "todos/2" {
+		t.Errorf("resolvePath() returned %s, expected todos/2", clone.resolvePath())
+	}
+}
+
+func TestRequest_MergeHeader(t *testing.T) {
+	connectorHeader := NewHeader().Add("X-Key", "connector").Add("X-Connector", "value")
+	requestHeader := NewHeader().Add("X-Key", "request")
+	request := NewRequest("todos", WithHeader(requestHeader))
+
+	request.MergeHeader(connectorHeader)
+
+	if request.Header.Get("X-Key") != "request" {
+		t.Errorf("MergeHeader() returned %s, expected request value to take precedence", request.Header.Get("X-Key"))
+	}
+
+	if request.Header.Get("X-Connector") != "value" {
+		t.Error("MergeHeader() did not merge the connector header")
+	}
+
+	if connectorHeader.Get("X-Key") != "connector" || requestHeader.Get("X-Connector") != "" {
+		t.Error("MergeHeader() modified the merged headers")
+	}
+}
+
+func TestWithPathParams(t *testing.T) {
+	request := NewRequest("users/{user}/todos/{id}", WithPathParams(map[string]string{"user": "a b", "id": "7"}))
+
+	expected := "users/a%20b/todos/7"
+	if request.resolvePath() != expected {
+		t.Errorf("resolvePath() returned %s, expected %s", request.resolvePath(), expected)
+	}
+}
+
+func TestRequest_SetBaseUrl(t *testing.T) {
+	for path, want := range map[string]string{
+		"todos/1":                              "https://api.example.com/v1/todos/1",
+		"https://api.example.com/v1/todos/1":   "https://api.example.com/v1/todos/1",
+		"https://api.example.com.evil.io/next": "https://api.example.com.evil.io/next",
+		"https://cdn.example.com/reports/1":    "https://cdn.example.com/reports/1",
+	} {
+		if got := NewRequest(path).SetBaseUrl("https://api.example.com/v1/").path; got != want {
+			t.Errorf("SetBaseUrl() of %s = %s, want %s", path, got, want)
+		}
+	}
+
+	for u, want := range map[string]bool{
+		"/v1/next":                          true,
+		"https://API.example.com/next":      true,
+		"https://api.example.com:443/next":  true,
+		"https://api.example.com:8443/next": false,
+		"https://eu.api.example.com/next":   false,
+		"http://api.example.com/next":       false,
+		"https://api.example.com.evil.io/":  false,
+		"https://example.com/next":          false,
+	} {
+		if sameOrigin("https://api.example.com/v1", u) != want {
+			t.Errorf("sameOrigin() of %s != %v", u, want)
+		}
+	}
+}
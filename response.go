@@ -3,6 +3,7 @@ package room
 import (
 	"encoding/json"
 	"encoding/xml"
+	"github.com/WEG-Technology/room/segment"
 	"github.com/WEG-Technology/room/store"
 	"io"
 	"mime"
@@ -12,9 +13,11 @@ import (
 
 type Response struct {
 	StatusCode int
+	Proto      string
 	Header     IHeader
 	Data       []byte
 	Request    RequestDTO
+	Timing     segment.Timing
 }
 
 type RequestDTO struct {
@@ -28,6 +31,7 @@ func NewResponse(response *http.Response, request *http.Request) Response {
 	responseDTO := newResponse(response.Request).setHeader(response.Header).setData(response)
 
 	responseDTO.StatusCode = response.StatusCode
+	responseDTO.Proto = response.Proto
 
 	return responseDTO
 }
@@ -84,9 +88,11 @@ func (r Response) setRequestHeader(header http.Header) Response {
 	return r
 }
 
+// setData reads the body decoded by its Content-Encoding, the transport decodes gzip by itself only when it asked for it
 func (r Response) setData(response *http.Response) Response {
 	if response.Body != nil {
 		r.Data, _ = io.ReadAll(response.Body)
+		r.Data, _ = decode(response.Header, r.Data)
 	}
 
 	return r
@@ -122,11 +128,35 @@ func (r Response) DTOorFail(v any) error {
 	return NewDTOFactory(r.Header.Get(headerKeyContentType)).marshall(r.Data, v)
 }
 
+// DecodeDTO decodes the response into T, it takes the results of a send so the call can be wrapped:
+//
+//	todo, err := room.DecodeDTO[Todo](client.TodoRoom.GetTodo("1"))
+func DecodeDTO[T any](response Response, err error) (T, error) {
+	var dto T
+
+	if err != nil {
+		return dto, err
+	}
+
+	err = response.DTOorFail(&dto)
+
+	return dto, err
+}
+
+// setRequestData reads a copy of the request body by GetBody, the body itself is consumed once the request is sent
 func (r Response) setRequestData(request *http.Request) Response {
-	if request.Body != nil {
+	if request.GetBody != nil {
+		if body, err := request.GetBody(); err == nil {
+			r.Request.Data, _ = io.ReadAll(body)
+			_ = body.Close()
+		}
+	} else if request.Body != nil {
 		r.Request.Data, _ = io.ReadAll(request.Body)
 	}
 
+	// the data is the body as the body parser produced it, before the connector compressed it
+	r.Request.Data, _ = decode(request.Header, r.Request.Data)
+
 	return r
 }
 
@@ -176,6 +206,8 @@ func NewDTOFactory(contentType ...string) IDTOFactory {
 
 	if strings.Contains(ct, headerValueMultipartFormData) {
 		return MultipartFormDataDTOFactory{contentType: ct}
+	} else if strings.HasPrefix(ct, headerValueSOAP12) {
+		return SOAPDTOFactory{}
 	} else {
 		switch ct {
 		case headerValueApplicationJson:
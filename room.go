@@ -1,6 +1,10 @@
 package room
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 const (
 	ErrAuthRoomCanNotFoundKey = "authToken can not found in response"
@@ -10,6 +14,18 @@ type IRoom interface {
 	Send(request *Request) (Response, error)
 }
 
+// IStreamRoom is a room which opens server-sent events streams
+type IStreamRoom interface {
+	IRoom
+	SSE(ctx context.Context, request *Request, opts ...OptionSSE) (*EventStream, error)
+}
+
+// IWebSocketRoom is a room which opens websockets
+type IWebSocketRoom interface {
+	IRoom
+	WebSocket(ctx context.Context, request *Request, opts ...OptionWebSocket) (*WebSocket, error)
+}
+
 type Room struct {
 	Connector *Connector
 }
@@ -22,6 +38,16 @@ func (r *Room) Send(request *Request) (Response, error) {
 	return r.Connector.Do(request)
 }
 
+// SSE opens a server-sent events stream of the request, see Connector.SSE
+func (r *Room) SSE(ctx context.Context, request *Request, opts ...OptionSSE) (*EventStream, error) {
+	return r.Connector.SSE(ctx, request, opts...)
+}
+
+// WebSocket opens a websocket of the request, see Connector.WebSocket
+func (r *Room) WebSocket(ctx context.Context, request *Request, opts ...OptionWebSocket) (*WebSocket, error) {
+	return r.Connector.WebSocket(ctx, request, opts...)
+}
+
 type AuthRoom struct {
 	*Room
 	AuthRequest *Request
@@ -37,25 +63,69 @@ func NewAuthRoom(connector *Connector, authRequest *Request, authToken string) I
 }
 
 func (r *AuthRoom) Send(request *Request) (Response, error) {
-	if r.AuthRequest != nil {
-		response, err := r.Connector.Do(r.AuthRequest)
+	authorized, response, err := r.authorize(request)
 
-		if err != nil {
-			return response, err
-		}
+	if authorized == nil {
+		return response, err
+	}
 
-		if !response.OK() {
-			return response, err
-		}
+	return r.Room.Send(authorized)
+}
 
-		if token, found := findToken(response.ResponseBody(), r.AuthToken); found {
-			r.Connector.Header.Add("Authorization", "Bearer "+token)
-		} else {
-			return response, errors.New(ErrAuthRoomCanNotFoundKey)
-		}
+// SSE opens the event stream with the token of the auth request, every reconnect fetches a new token
+func (r *AuthRoom) SSE(ctx context.Context, request *Request, opts ...OptionSSE) (*EventStream, error) {
+	return r.Room.SSE(ctx, request, append(opts, withSSEAuthorizer(r.reauthorize))...)
+}
+
+// WebSocket opens the websocket with the token of the auth request, every reconnect fetches a new token
+func (r *AuthRoom) WebSocket(ctx context.Context, request *Request, opts ...OptionWebSocket) (*WebSocket, error) {
+	return r.Room.WebSocket(ctx, request, append(opts, withWebSocketAuthorizer(r.reauthorize))...)
+}
+
+// reauthorize authorizes a connection of a stream, a rejected auth request is a StreamStatusError
+func (r *AuthRoom) reauthorize(request *Request) (*Request, error) {
+	authorized, response, err := r.authorize(request)
+
+	if authorized == nil && err == nil {
+		err = StreamStatusError{StatusCode: response.StatusCode, Message: fmt.Sprintf("room: auth request answered %d", response.StatusCode)}
+	}
+
+	return authorized, err
+}
+
+// authorize sends the auth request and returns a copy of the request with its token,
+// the request is nil when the auth request fails
+func (r *AuthRoom) authorize(request *Request) (*Request, Response, error) {
+	if r.AuthRequest == nil {
+		return request, Response{}, nil
 	}
 
-	return r.Room.Send(request)
+	response, err := r.Connector.Do(r.AuthRequest)
+
+	if err != nil {
+		return nil, response, err
+	}
+
+	if !response.OK() {
+		return nil, response, err
+	}
+
+	token, found := findToken(response.ResponseBody(), r.AuthToken)
+
+	if !found {
+		return nil, response, errors.New(ErrAuthRoomCanNotFoundKey)
+	}
+
+	// the token is applied to a copy of the request to keep the shared connector untouched
+	request = request.Clone()
+
+	if request.Header == nil {
+		request.Header = NewHeader()
+	}
+
+	request.Header.Add("Authorization", "Bearer "+token)
+
+	return request, response, nil
 }
 
 type IAuth interface {
@@ -0,0 +1,78 @@
+// Package roomtest provides test helpers for room based clients, like the record/replay Recorder.
+package roomtest
+
+import (
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Cassette keeps the recorded interactions of a Recorder
+type Cassette struct {
+	Interactions []Interaction `json:"interactions" yaml:"interactions"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request" yaml:"request"`
+	Response RecordedResponse `json:"response" yaml:"response"`
+}
+
+type RecordedRequest struct {
+	Method  string              `json:"method" yaml:"method"`
+	URL     string              `json:"url" yaml:"url"`
+	Headers map[string][]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string              `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status  int                 `json:"status" yaml:"status"`
+	Headers map[string][]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string              `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// LoadCassette reads a cassette, files with the .json extension are decoded as json and others as yaml
+func LoadCassette(path string) (Cassette, error) {
+	var cassette Cassette
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return cassette, err
+	}
+
+	if isJson(path) {
+		err = json.Unmarshal(data, &cassette)
+	} else {
+		err = yaml.Unmarshal(data, &cassette)
+	}
+
+	return cassette, err
+}
+
+// Save writes the cassette, the format is chosen by the file extension like LoadCassette
+func (c Cassette) Save(path string) error {
+	var data []byte
+	var err error
+
+	if isJson(path) {
+		data, err = json.MarshalIndent(c, "", "  ")
+	} else {
+		data, err = yaml.Marshal(c)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func isJson(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
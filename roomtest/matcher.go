@@ -0,0 +1,102 @@
+package roomtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+// Matcher compares an outgoing request with a recorded one, it returns an empty string on a match
+// or the description of the difference otherwise
+type Matcher func(req *http.Request, body []byte, recorded RecordedRequest) string
+
+// DefaultMatchers match requests by method, path, query and body
+var DefaultMatchers = []Matcher{MatchMethod, MatchPath, MatchQuery, MatchBody}
+
+func MatchMethod(req *http.Request, _ []byte, recorded RecordedRequest) string {
+	if req.Method != recorded.Method {
+		return fmt.Sprintf("method: got %s, recorded %s", req.Method, recorded.Method)
+	}
+
+	return ""
+}
+
+func MatchPath(req *http.Request, _ []byte, recorded RecordedRequest) string {
+	u, err := url.Parse(recorded.URL)
+
+	if err != nil {
+		return fmt.Sprintf("url: recorded %s is invalid", recorded.URL)
+	}
+
+	if req.URL.Path != u.Path {
+		return fmt.Sprintf("path: got %s, recorded %s", req.URL.Path, u.Path)
+	}
+
+	return ""
+}
+
+// MatchQuery compares the query params regardless of their order
+func MatchQuery(req *http.Request, _ []byte, recorded RecordedRequest) string {
+	u, err := url.Parse(recorded.URL)
+
+	if err != nil {
+		return fmt.Sprintf("url: recorded %s is invalid", recorded.URL)
+	}
+
+	if !reflect.DeepEqual(normalizeQuery(req.URL.Query()), normalizeQuery(u.Query())) {
+		return fmt.Sprintf("query: got %q, recorded %q", req.URL.RawQuery, u.RawQuery)
+	}
+
+	return ""
+}
+
+// MatchBody compares json bodies semantically and any other body byte by byte
+func MatchBody(_ *http.Request, body []byte, recorded RecordedRequest) string {
+	if bodiesEqual(body, []byte(recorded.Body)) {
+		return ""
+	}
+
+	return fmt.Sprintf("body: got %s, recorded %s", body, recorded.Body)
+}
+
+// MatchHeaders compares the given headers
+func MatchHeaders(keys ...string) Matcher {
+	return func(req *http.Request, _ []byte, recorded RecordedRequest) string {
+		recordedHeader := http.Header(recorded.Headers)
+
+		for _, key := range keys {
+			if req.Header.Get(key) != recordedHeader.Get(key) {
+				return fmt.Sprintf("header %s: got %q, recorded %q", key, req.Header.Get(key), recordedHeader.Get(key))
+			}
+		}
+
+		return ""
+	}
+}
+
+func bodiesEqual(a, b []byte) bool {
+	a, b = bytes.TrimSpace(a), bytes.TrimSpace(b)
+
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	var aDocument, bDocument any
+
+	if json.Unmarshal(a, &aDocument) != nil || json.Unmarshal(b, &bDocument) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(aDocument, bDocument)
+}
+
+func normalizeQuery(values url.Values) url.Values {
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values
+}
@@ -0,0 +1,309 @@
+package roomtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/elevator"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type Mode int
+
+const (
+	// ModeReplay serves the requests from the cassette without network access, it is the zero value
+	ModeReplay Mode = iota
+	// ModeRecord sends the requests and saves them to the cassette on Stop
+	ModeRecord
+)
+
+// RecordEnv is the environment variable ModeFromEnv reads, `ROOM_RECORD=1 go test ./...` re-records the cassettes
+const RecordEnv = "ROOM_RECORD"
+
+// ErrNoInteraction is returned in replay mode for requests which do not match any recorded interaction
+var ErrNoInteraction = errors.New("roomtest: no recorded interaction matches the request")
+
+// ModeFromEnv returns ModeRecord when RecordEnv is set to a non-empty value, ModeReplay otherwise
+func ModeFromEnv() Mode {
+	if os.Getenv(RecordEnv) != "" {
+		return ModeRecord
+	}
+
+	return ModeReplay
+}
+
+// Recorder records the traffic of connectors to a cassette or replays it from the cassette
+type Recorder struct {
+	path     string
+	mode     Mode
+	matchers []Matcher
+	redactor room.Redactor
+	cassette Cassette
+	used     []bool
+	mu       sync.Mutex
+}
+
+type OptionRecorder func(recorder *Recorder)
+
+// WithMatchers replaces DefaultMatchers
+func WithMatchers(matchers ...Matcher) OptionRecorder {
+	return func(recorder *Recorder) {
+		recorder.matchers = matchers
+	}
+}
+
+// WithCassetteRedactor redacts the recorded headers and bodies, by default room.DefaultRedactedHeaders and
+// room.DefaultRedactedFields are redacted. The live request bodies are redacted the same way before they are matched.
+func WithCassetteRedactor(redactor room.Redactor) OptionRecorder {
+	return func(recorder *Recorder) {
+		recorder.redactor = redactor
+	}
+}
+
+// NewRecorder creates a recorder for the cassette file, in replay mode the cassette must exist
+func NewRecorder(path string, mode Mode, opts ...OptionRecorder) (*Recorder, error) {
+	r := &Recorder{
+		path:     path,
+		mode:     mode,
+		matchers: DefaultMatchers,
+		redactor: room.NewRedactor(nil, nil),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if mode != ModeRecord {
+		cassette, err := LoadCassette(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		r.cassette = cassette
+		r.used = make([]bool, len(cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// New creates a recorder in the mode of ModeFromEnv and saves the cassette when the test ends
+func New(t testing.TB, path string, opts ...OptionRecorder) *Recorder {
+	t.Helper()
+
+	r, err := NewRecorder(path, ModeFromEnv(), opts...)
+
+	if err != nil {
+		t.Fatalf("roomtest: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := r.Stop(); err != nil {
+			t.Errorf("roomtest: %v", err)
+		}
+	})
+
+	return r
+}
+
+// Middleware records or replays the requests passing through it
+func (r *Recorder) Middleware() room.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return room.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			body, err := requestBody(req)
+
+			if err != nil {
+				return nil, err
+			}
+
+			// compressed requests are matched and recorded by their content, redacted like the recorded requests
+			body, _ = room.DecodeBody(req.Header, body)
+			body = r.redactor.Body(req.Header.Get("Content-Type"), body)
+
+			if r.mode != ModeRecord {
+				return r.replay(req, body)
+			}
+
+			return r.record(next, req, body)
+		})
+	}
+}
+
+// ConnectorOption attaches the recorder to a connector
+func (r *Recorder) ConnectorOption() room.OptionConnector {
+	return room.WithMiddleware(r.Middleware())
+}
+
+// EngineOption attaches the recorder to every room of an elevator engine
+func (r *Recorder) EngineOption() elevator.OptionEngine {
+	return elevator.WithConnectorOptions(r.ConnectorOption())
+}
+
+// Cassette returns a copy of the recorded or loaded interactions
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Stop saves the cassette in record mode
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	return r.Cassette().Save(r.path)
+}
+
+func (r *Recorder) record(next http.RoundTripper, req *http.Request, body []byte) (*http.Response, error) {
+	res, err := next.RoundTrip(req)
+
+	// streams are passed through, they can not be replayed from a cassette
+	if err != nil || room.IsStream(res) {
+		return res, err
+	}
+
+	responseBody, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	if err != nil {
+		return res, err
+	}
+
+	responseHeader := r.redactor.Header(res.Header)
+
+	// the response is recorded decoded, so its fields can be redacted and it is replayed without the encoding
+	if decoded, err := room.DecodeBody(res.Header, responseBody); err == nil && res.Header.Get("Content-Encoding") != "" {
+		responseBody = decoded
+		responseHeader.Del("Content-Encoding")
+		responseHeader.Del("Content-Length")
+	}
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: r.redactor.Header(req.Header),
+			Body:    string(body),
+		},
+		Response: RecordedResponse{
+			Status:  res.StatusCode,
+			Headers: responseHeader,
+			Body:    string(r.redactor.Body(res.Header.Get("Content-Type"), responseBody)),
+		},
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return res, nil
+}
+
+// replay serves the first unused matching interaction, matched interactions are reused once all of them are used
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+
+	for i, interaction := range r.cassette.Interactions {
+		if len(r.differences(req, body, interaction.Request)) > 0 {
+			continue
+		}
+
+		if !r.used[i] {
+			match = i
+			break
+		}
+
+		if match < 0 {
+			match = i
+		}
+	}
+
+	if match < 0 {
+		return nil, r.noInteractionError(req, body)
+	}
+
+	r.used[match] = true
+
+	recorded := r.cassette.Interactions[match].Response
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header(recorded.Headers).Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) differences(req *http.Request, body []byte, recorded RecordedRequest) []string {
+	var differences []string
+
+	for _, matcher := range r.matchers {
+		if difference := matcher(req, body, recorded); difference != "" {
+			differences = append(differences, difference)
+		}
+	}
+
+	return differences
+}
+
+// noInteractionError describes the differences to the closest recorded interaction
+func (r *Recorder) noInteractionError(req *http.Request, body []byte) error {
+	var closest []string
+
+	for _, interaction := range r.cassette.Interactions {
+		differences := r.differences(req, body, interaction.Request)
+
+		if closest == nil || len(differences) < len(closest)-1 {
+			closest = append([]string{interaction.Request.Method + " " + interaction.Request.URL}, differences...)
+		}
+	}
+
+	message := fmt.Sprintf("%s %s in %s", req.Method, req.URL.String(), r.path)
+
+	if closest != nil {
+		message += "\nclosest interaction " + closest[0] + ":\n  " + strings.Join(closest[1:], "\n  ")
+	}
+
+	return fmt.Errorf("%w: %s", ErrNoInteraction, message)
+}
+
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer body.Close()
+
+		return io.ReadAll(body)
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+
+	return data, err
+}
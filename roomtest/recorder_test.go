@@ -0,0 +1,201 @@
+package roomtest
+
+import (
+	"compress/gzip"
+	"errors"
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/elevator"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func recordTestCassette(t *testing.T, path string) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	recorder, err := NewRecorder(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connector := room.NewConnector(server.URL,
+		room.WithHeaderConnector(room.NewHeader().Add("Authorization", "Bearer secret")),
+		recorder.ConnectorOption(),
+	)
+
+	response, err := connector.Do(room.NewRequest("todos/add?limit=1",
+		room.WithMethod(room.POST),
+		room.WithBody(room.NewJsonBodyParser(map[string]any{"todo": "lorem", "completed": true})),
+	))
+	if err != nil || response.StatusCode != http.StatusCreated {
+		t.Fatalf("Do() returned %d, %v in record mode", response.StatusCode, err)
+	}
+
+	if err = recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	return server.URL
+}
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	for _, name := range []string{"todo.yml", "todo.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			baseUrl := recordTestCassette(t, path)
+
+			cassette, err := LoadCassette(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(cassette.Interactions) != 1 || http.Header(cassette.Interactions[0].Request.Headers).Get("Authorization") != room.RedactedValue {
+				t.Fatalf("Recorder saved %+v, expected one interaction with a redacted Authorization header", cassette)
+			}
+
+			recorder, err := NewRecorder(path, ModeReplay)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the server is closed, so the response can only come from the cassette
+			connector := room.NewConnector(baseUrl, recorder.ConnectorOption())
+
+			response, err := connector.Do(room.NewRequest("todos/add?limit=1",
+				room.WithMethod(room.POST),
+				room.WithBody(room.NewJsonBodyParser(map[string]any{"completed": true, "todo": "lorem"})),
+			))
+			if err != nil {
+				t.Fatalf("Do() returned error in replay mode: %v", err)
+			}
+
+			if response.StatusCode != http.StatusCreated || response.ResponseBody()["id"] != float64(1) {
+				t.Errorf("Do() returned %d %s in replay mode", response.StatusCode, response.Data)
+			}
+
+			_, err = connector.Do(room.NewRequest("todos/add?limit=2",
+				room.WithMethod(room.POST),
+				room.WithBody(room.NewJsonBodyParser(map[string]any{"completed": true, "todo": "lorem"})),
+			))
+
+			if !errors.Is(err, ErrNoInteraction) || !strings.Contains(err.Error(), `query: got "limit=2", recorded "limit=1"`) {
+				t.Errorf("Do() returned %v, expected ErrNoInteraction with the query difference", err)
+			}
+		})
+	}
+}
+
+func TestRecorder_CompressedBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+
+		writer := gzip.NewWriter(w)
+		_, _ = writer.Write([]byte(`{"id":1,"token":"abc"}`))
+		_ = writer.Close()
+	}))
+
+	path := filepath.Join(t.TempDir(), "login.yml")
+
+	// the passwords and tokens are redacted by default
+	recorder, err := NewRecorder(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login := func(connector *room.Connector, password string) (room.Response, error) {
+		return connector.Do(room.NewRequest("login", room.WithMethod(room.POST),
+			room.WithBody(room.NewJsonBodyParser(map[string]any{"user": "john", "password": password}))))
+	}
+
+	opts := []room.OptionConnector{room.WithRequestCompression(room.EncodingGzip, 1), room.WithAcceptEncoding(room.EncodingGzip)}
+
+	if response, err := login(room.NewConnector(server.URL, append(opts, recorder.ConnectorOption())...), "secret"); err != nil || response.ResponseBody()["token"] != "abc" {
+		t.Fatalf("Do() returned %s, %v in record mode", response.Data, err)
+	}
+
+	server.Close()
+
+	interaction := recorder.Cassette().Interactions[0]
+
+	if interaction.Request.Body != `{"password":"[REDACTED]","user":"john"}` || interaction.Response.Body != `{"id":1,"token":"[REDACTED]"}` {
+		t.Errorf("Recorder recorded %q and %q", interaction.Request.Body, interaction.Response.Body)
+	}
+
+	if http.Header(interaction.Response.Headers).Get("Content-Encoding") != "" {
+		t.Errorf("Recorder kept the encoding of the decoded response: %v", interaction.Response.Headers)
+	}
+
+	if err = recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder, err = NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the live body is redacted before it is matched, so the recorded body matches whatever password is sent
+	if response, err := login(room.NewConnector(server.URL, append(opts, recorder.ConnectorOption())...), "other"); err != nil || response.ResponseBody()["id"] != float64(1) {
+		t.Errorf("Do() returned %s, %v in replay mode", response.Data, err)
+	}
+}
+
+func TestRecorder_EngineOption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.yml")
+	baseUrl := recordTestCassette(t, path)
+
+	el, err := elevator.Load(elevator.WithBytes([]byte(`
+flat:
+  rooms:
+    todoRoom:
+      connection:
+        baseUrl: ` + baseUrl + `
+      requests:
+        addTodo:
+          method: "POST"
+          path: "todos/add"
+          query:
+            content:
+              limit: 1
+          body:
+            type: "json"
+            dynamicContent:
+              - key: "todo"
+                type: "string"
+              - key: "completed"
+                type: "boolean"
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := elevator.NewElevatorEngine(el, New(t, path).EngineOption()).WarmUp()
+
+	response, err := engine.DynamicExecute("todoRoom", "addTodo", map[string]any{"todo": "lorem", "completed": "true"})
+	if err != nil {
+		t.Fatalf("DynamicExecute() returned error: %v", err)
+	}
+
+	if response.StatusCode != http.StatusCreated {
+		t.Errorf("DynamicExecute() returned %d, expected the recorded 201", response.StatusCode)
+	}
+}
+
+func TestNewRecorder_ZeroMode(t *testing.T) {
+	var mode Mode
+
+	// the zero mode replays, so the missing cassette must be reported instead of recording a new one
+	if _, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), mode); err == nil {
+		t.Error("NewRecorder() returned no error, expected the zero mode to replay the missing cassette")
+	}
+}
@@ -0,0 +1,306 @@
+package roomtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/elevator"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Server is a mock server which replies to the requests by the registered expectations
+type Server struct {
+	server       *httptest.Server
+	expectations []*Expectation
+	unexpected   []string
+	mu           sync.Mutex
+}
+
+// NewServer starts a mock server, it is closed and verified when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := newServer()
+
+	t.Cleanup(func() {
+		s.Close()
+
+		if err := s.Verify(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	return s
+}
+
+func newServer() *Server {
+	s := &Server{}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// URL returns the base url of the server to pass to room.NewConnector
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// EngineOption points the rooms of an elevator engine to the server
+func (s *Server) EngineOption(roomKeys ...string) elevator.OptionEngine {
+	return func(engine *elevator.ElevatorEngine) {
+		for _, roomKey := range roomKeys {
+			elevator.WithBaseUrl(roomKey, s.URL())(engine)
+		}
+	}
+}
+
+// Expect registers an expectation for the method and path, the path may contain query params which must be sent too.
+// The expectation must be met once unless Times or AnyTimes is set.
+func (s *Server) Expect(method room.HTTPMethod, path string) *Expectation {
+	e := &Expectation{
+		method: method.String(),
+		path:   path,
+		times:  1,
+		query:  url.Values{},
+		header: http.Header{},
+		reply:  reply{status: http.StatusOK, header: http.Header{}},
+	}
+
+	if u, err := url.Parse(path); err == nil {
+		e.path = u.Path
+		e.query = u.Query()
+	}
+
+	if !strings.HasPrefix(e.path, "/") {
+		e.path = "/" + e.path
+	}
+
+	s.mu.Lock()
+	s.expectations = append(s.expectations, e)
+	s.mu.Unlock()
+
+	return e
+}
+
+// Verify returns the unmet expectations and the requests which did not match any expectation
+func (s *Server) Verify() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+
+	for _, e := range s.expectations {
+		if e.times >= 0 && e.calls != e.times {
+			errs = append(errs, fmt.Errorf("roomtest: expected %s %s %d times, got %d", e.method, e.path, e.times, e.calls))
+		}
+	}
+
+	for _, request := range s.unexpected {
+		errs = append(errs, fmt.Errorf("roomtest: unexpected request %s", request))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+
+	var matched *Expectation
+	var closest []string
+
+	for _, e := range s.expectations {
+		differences := e.differences(r, body)
+
+		if len(differences) > 0 {
+			if closest == nil || len(differences) < len(closest) {
+				closest = differences
+			}
+
+			continue
+		}
+
+		if e.times < 0 || e.calls < e.times {
+			matched = e
+			break
+		}
+
+		closest = []string{fmt.Sprintf("%s %s is already called %d times", e.method, e.path, e.calls)}
+	}
+
+	if matched == nil {
+		request := r.Method + " " + r.URL.String()
+		if closest != nil {
+			request += ": " + strings.Join(closest, ", ")
+		}
+
+		s.unexpected = append(s.unexpected, request)
+		s.mu.Unlock()
+
+		http.Error(w, "roomtest: unexpected request "+request, http.StatusNotImplemented)
+		return
+	}
+
+	matched.calls++
+	reply := matched.reply
+	s.mu.Unlock()
+
+	for key, values := range reply.header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+
+	w.WriteHeader(reply.status)
+	_, _ = w.Write(reply.body)
+}
+
+// Expectation describes an expected request and the reply to it
+type Expectation struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+	reply  reply
+	times  int
+	calls  int
+}
+
+type reply struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// WithQuery expects the query param, other query params are allowed
+func (e *Expectation) WithQuery(key, value string) *Expectation {
+	e.query.Add(key, value)
+
+	return e
+}
+
+// WithHeader expects the header, other headers are allowed
+func (e *Expectation) WithHeader(key, value string) *Expectation {
+	e.header.Add(key, value)
+
+	return e
+}
+
+// WithBody expects the raw request body
+func (e *Expectation) WithBody(body string) *Expectation {
+	e.body = []byte(body)
+
+	return e
+}
+
+// WithJSONBody expects a json body equal to v regardless of the key order and whitespace
+func (e *Expectation) WithJSONBody(v any) *Expectation {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		panic(fmt.Sprintf("roomtest: json body of %s %s: %v", e.method, e.path, err))
+	}
+
+	e.body = data
+
+	return e
+}
+
+// Reply sets the reply status and body, strings and byte slices are sent as they are and other values as json
+func (e *Expectation) Reply(status int, body any) *Expectation {
+	e.reply.status = status
+
+	switch b := body.(type) {
+	case nil:
+		e.reply.body = nil
+	case string:
+		e.reply.body = []byte(b)
+	case []byte:
+		e.reply.body = b
+	default:
+		data, err := json.Marshal(b)
+
+		if err != nil {
+			panic(fmt.Sprintf("roomtest: reply body of %s %s: %v", e.method, e.path, err))
+		}
+
+		e.reply.body = data
+
+		if e.reply.header.Get("Content-Type") == "" {
+			e.reply.header.Set("Content-Type", "application/json")
+		}
+	}
+
+	return e
+}
+
+// ReplyHeader adds a header to the reply
+func (e *Expectation) ReplyHeader(key, value string) *Expectation {
+	e.reply.header.Add(key, value)
+
+	return e
+}
+
+// Times sets how many times the request is expected
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+
+	return e
+}
+
+// AnyTimes allows the request any number of times, including none
+func (e *Expectation) AnyTimes() *Expectation {
+	e.times = -1
+
+	return e
+}
+
+func (e *Expectation) differences(r *http.Request, body []byte) []string {
+	var differences []string
+
+	if r.Method != e.method {
+		differences = append(differences, fmt.Sprintf("method: got %s, expected %s", r.Method, e.method))
+	}
+
+	if r.URL.Path != e.path {
+		differences = append(differences, fmt.Sprintf("path: got %s, expected %s", r.URL.Path, e.path))
+	}
+
+	query := r.URL.Query()
+	for key, values := range e.query {
+		if strings.Join(query[key], ",") != strings.Join(values, ",") {
+			differences = append(differences, fmt.Sprintf("query %s: got %q, expected %q", key, query[key], values))
+		}
+	}
+
+	for key := range e.header {
+		if r.Header.Get(key) != e.header.Get(key) {
+			differences = append(differences, fmt.Sprintf("header %s: got %q, expected %q", key, r.Header.Get(key), e.header.Get(key)))
+		}
+	}
+
+	if e.body != nil && !bodiesEqual(body, e.body) {
+		differences = append(differences, fmt.Sprintf("body: got %s, expected %s", body, e.body))
+	}
+
+	return differences
+}
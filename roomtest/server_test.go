@@ -0,0 +1,108 @@
+package roomtest
+
+import (
+	"github.com/WEG-Technology/room"
+	"github.com/WEG-Technology/room/elevator"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestServer_Expect(t *testing.T) {
+	server := NewServer(t)
+
+	server.Expect(room.POST, "/todos/add").
+		WithHeader("X-Trace", "1").
+		WithJSONBody(map[string]any{"todo": "lorem", "completed": true}).
+		Reply(http.StatusCreated, map[string]any{"id": 1}).
+		Times(2)
+
+	server.Expect(room.GET, "/todos?limit=1").Reply(http.StatusOK, `[]`)
+
+	connector := room.NewConnector(server.URL(), room.WithHeaderConnector(room.NewHeader().Add("X-Trace", "1")))
+
+	for i := 0; i < 2; i++ {
+		response, err := connector.Do(room.NewRequest("todos/add",
+			room.WithMethod(room.POST),
+			room.WithBody(room.NewJsonBodyParser(map[string]any{"completed": true, "todo": "lorem"})),
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response.StatusCode != http.StatusCreated || response.ResponseBody()["id"] != float64(1) {
+			t.Errorf("Do() returned %d %s", response.StatusCode, response.Data)
+		}
+	}
+
+	response, err := connector.Do(room.NewRequest("todos?limit=1"))
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Errorf("Do() returned %d, %v", response.StatusCode, err)
+	}
+}
+
+func TestServer_Verify(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	server.Expect(room.GET, "/todos").Times(2)
+	server.Expect(room.DELETE, "/todos/1").AnyTimes()
+
+	connector := room.NewConnector(server.URL())
+
+	if _, err := connector.Do(room.NewRequest("todos")); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := connector.Do(room.NewRequest("todos/2", room.WithMethod(room.DELETE)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusNotImplemented {
+		t.Errorf("Do() returned %d for an unexpected request, expected 501", response.StatusCode)
+	}
+
+	err = server.Verify()
+
+	for _, expected := range []string{
+		"expected GET /todos 2 times, got 1",
+		"unexpected request DELETE /todos/2: path: got /todos/2, expected /todos/1",
+	} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Verify() returned %v, expected it to contain %q", err, expected)
+		}
+	}
+
+	if strings.Contains(err.Error(), "DELETE /todos/1 ") {
+		t.Errorf("Verify() returned %v, AnyTimes expectations must not be reported", err)
+	}
+}
+
+func TestServer_EngineOption(t *testing.T) {
+	server := NewServer(t)
+
+	server.Expect(room.GET, "/todos/1").Reply(http.StatusOK, map[string]any{"id": 1})
+
+	el, err := elevator.Load(elevator.WithBytes([]byte(`
+flat:
+  rooms:
+    todoRoom:
+      connection:
+        baseUrl: "https://dummyjson.com"
+      requests:
+        getTodo:
+          method: "GET"
+          path: "todos/1"
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := elevator.NewElevatorEngine(el, server.EngineOption("todoRoom")).WarmUp()
+
+	response, err := engine.Execute("todoRoom", "getTodo")
+	if err != nil || response.ResponseBody()["id"] != float64(1) {
+		t.Errorf("Execute() returned %s, %v from the mock server", response.Data, err)
+	}
+}
@@ -1,11 +1,18 @@
 package segment
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 type SegmentSchema struct {
+	name        string
 	startedAt   time.Time
 	endedAt     time.Time
 	elapsedTime float64
+	timing      Timing
+	children    []ISegment
+	mu          sync.Mutex
 }
 
 type ISegment interface {
@@ -13,6 +20,11 @@ type ISegment interface {
 	startNow() ISegment
 	End() ISegment
 	GetElapsedTime() float64
+	Name() string
+	StartChild(name string) ISegment
+	Children() []ISegment
+	SetTiming(timing Timing) ISegment
+	Timing() Timing
 }
 
 func (s *SegmentSchema) start(t time.Time) ISegment {
@@ -25,16 +37,59 @@ func (s *SegmentSchema) startNow() ISegment {
 }
 
 func (s *SegmentSchema) End() ISegment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.endedAt = time.Now()
 	s.elapsedTime = time.Since(s.startedAt).Seconds()
 	return s
 }
 
 func (s *SegmentSchema) GetElapsedTime() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.elapsedTime
 }
 
+func (s *SegmentSchema) Name() string {
+	return s.name
+}
+
+// StartChild starts a nested segment, children can be started and ended concurrently
+func (s *SegmentSchema) StartChild(name string) ISegment {
+	child := StartNamedSegmentNow(name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.children = append(s.children, child)
+	return child
+}
+
+func (s *SegmentSchema) Children() []ISegment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ISegment(nil), s.children...)
+}
+
+// SetTiming attaches the http phase breakdown of the request measured by the segment
+func (s *SegmentSchema) SetTiming(timing Timing) ISegment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timing = timing
+	return s
+}
+
+func (s *SegmentSchema) Timing() Timing {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.timing
+}
+
 func StartSegmentNow() ISegment {
 	s := new(SegmentSchema)
 	return s.startNow()
 }
+
+func StartNamedSegmentNow(name string) ISegment {
+	s := &SegmentSchema{name: name}
+	return s.startNow()
+}
@@ -0,0 +1,117 @@
+package segment
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing is the per-phase breakdown of a single http request
+type Timing struct {
+	StartedAt       time.Time
+	DNS             time.Duration
+	Connect         time.Duration
+	TLSHandshake    time.Duration
+	TimeToFirstByte time.Duration
+	BodyRead        time.Duration
+	Total           time.Duration
+	ConnReused      bool
+}
+
+// HTTPTiming collects the Timing of a request by net/http/httptrace
+type HTTPTiming struct {
+	mu           sync.Mutex
+	startedAt    time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	headersAt    time.Time
+	timing       Timing
+}
+
+func NewHTTPTiming() *HTTPTiming {
+	return new(HTTPTiming)
+}
+
+// Start marks the beginning of the request, it is called right before the request is sent
+func (t *HTTPTiming) Start() *HTTPTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.startedAt = time.Now()
+	t.timing.StartedAt = t.startedAt
+
+	return t
+}
+
+// ClientTrace returns the hooks to attach to the request context by httptrace.WithClientTrace
+func (t *HTTPTiming) ClientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mark(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.since(&t.timing.DNS, &t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mark(&t.connectStart)
+		},
+		ConnectDone: func(string, string, error) {
+			t.since(&t.timing.Connect, &t.connectStart)
+		},
+		TLSHandshakeStart: func() {
+			t.mark(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.since(&t.timing.TLSHandshake, &t.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.timing.ConnReused = info.Reused
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.since(&t.timing.TimeToFirstByte, &t.startedAt)
+		},
+	}
+}
+
+// HeadersReceived marks the moment the response is returned by the client, the body is read after it
+func (t *HTTPTiming) HeadersReceived() *HTTPTiming {
+	t.mark(&t.headersAt)
+
+	return t
+}
+
+// End marks the end of the body read and returns the collected Timing
+func (t *HTTPTiming) End() Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+
+	if !t.headersAt.IsZero() {
+		t.timing.BodyRead = now.Sub(t.headersAt)
+	}
+
+	t.timing.Total = now.Sub(t.startedAt)
+
+	return t.timing
+}
+
+func (t *HTTPTiming) mark(at *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	*at = time.Now()
+}
+
+func (t *HTTPTiming) since(d *time.Duration, from *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !from.IsZero() {
+		*d = time.Since(*from)
+	}
+}
@@ -0,0 +1,69 @@
+package segment
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"testing"
+	"time"
+)
+
+func TestHTTPTiming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("test body"))
+	}))
+	defer server.Close()
+
+	timing := NewHTTPTiming()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), timing.ClientTrace()))
+
+	timing.Start()
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timing.HeadersReceived()
+	_, _ = io.ReadAll(res.Body)
+	_ = res.Body.Close()
+
+	result := timing.End()
+
+	if result.Connect <= 0 {
+		t.Errorf("Expected Connect to be greater than 0, but got %v", result.Connect)
+	}
+
+	if result.TimeToFirstByte < 20*time.Millisecond {
+		t.Errorf("Expected TimeToFirstByte to include the server delay, but got %v", result.TimeToFirstByte)
+	}
+
+	if result.Total < result.TimeToFirstByte {
+		t.Errorf("Expected Total %v to be greater than TimeToFirstByte %v", result.Total, result.TimeToFirstByte)
+	}
+}
+
+func TestSegmentSchema_Children(t *testing.T) {
+	s := StartNamedSegmentNow("batch")
+	child := s.StartChild("todoRoom.addTodo")
+	child.SetTiming(Timing{Total: time.Second}).End()
+	s.End()
+
+	children := s.Children()
+
+	if len(children) != 1 || children[0].Name() != "todoRoom.addTodo" {
+		t.Fatalf("Expected one child named todoRoom.addTodo, but got %v", children)
+	}
+
+	if children[0].Timing().Total != time.Second {
+		t.Errorf("Expected the child timing to be kept, but got %v", children[0].Timing())
+	}
+
+	if s.GetElapsedTime() < children[0].GetElapsedTime() {
+		t.Error("Expected the parent segment to be longer than its child")
+	}
+}
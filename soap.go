@@ -0,0 +1,302 @@
+package room
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+)
+
+type SOAPVersion string
+
+const (
+	SOAP11 SOAPVersion = "1.1"
+	SOAP12 SOAPVersion = "1.2"
+
+	soap11Namespace     = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12Namespace     = "http://www.w3.org/2003/05/soap-envelope"
+	wsseNamespace       = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+	wssePasswordText    = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordText"
+	headerKeySOAPAction = "SOAPAction"
+	headerValueSOAP12   = "application/soap+xml"
+)
+
+// bodyHeaderer is a body parser which sets headers of its own, like the SOAPAction of a SOAP 1.1 envelope
+type bodyHeaderer interface {
+	headers() map[string]string
+}
+
+// SOAPBody wraps its payload in a SOAP envelope, the payload is marshalled by encoding/xml
+type SOAPBody struct {
+	v        any
+	version  SOAPVersion
+	action   string
+	username string
+	password string
+}
+
+type OptionSOAP func(body *SOAPBody)
+
+// WithSOAPVersion sets the version of the envelope, the default is SOAP 1.1
+func WithSOAPVersion(version SOAPVersion) OptionSOAP {
+	return func(body *SOAPBody) {
+		body.version = version
+	}
+}
+
+// WithSOAPAction sets the action, it is sent as the SOAPAction header with SOAP 1.1 and in the content type with SOAP 1.2
+func WithSOAPAction(action string) OptionSOAP {
+	return func(body *SOAPBody) {
+		body.action = action
+	}
+}
+
+// WithUsernameToken adds a WS-Security UsernameToken header with a plain text password
+func WithUsernameToken(username, password string) OptionSOAP {
+	return func(body *SOAPBody) {
+		body.username = username
+		body.password = password
+	}
+}
+
+// NewSOAPBodyParser wraps v, a struct with xml tags or a *SOAPOperation, in a SOAP envelope
+func NewSOAPBodyParser(v any, opts ...OptionSOAP) IBodyParser {
+	body := &SOAPBody{v: v, version: SOAP11}
+
+	for _, opt := range opts {
+		opt(body)
+	}
+
+	return body
+}
+
+func (b *SOAPBody) Parse() *bytes.Buffer {
+	var buf bytes.Buffer
+
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<soap:Envelope xmlns:soap="` + b.version.namespace() + `">`)
+
+	if b.username != "" {
+		buf.WriteString(`<soap:Header><wsse:Security xmlns:wsse="` + wsseNamespace + `" soap:mustUnderstand="` + b.version.mustUnderstand() + `">`)
+		buf.WriteString(`<wsse:UsernameToken><wsse:Username>` + html.EscapeString(b.username) + `</wsse:Username>`)
+		buf.WriteString(`<wsse:Password Type="` + wssePasswordText + `">` + html.EscapeString(b.password) + `</wsse:Password>`)
+		buf.WriteString(`</wsse:UsernameToken></wsse:Security></soap:Header>`)
+	}
+
+	buf.WriteString(`<soap:Body>`)
+
+	if b.v != nil {
+		if err := xml.NewEncoder(&buf).Encode(b.v); err != nil {
+			panic(err)
+		}
+	}
+
+	buf.WriteString(`</soap:Body></soap:Envelope>`)
+
+	return &buf
+}
+
+func (b *SOAPBody) ContentType() string {
+	if b.version == SOAP12 {
+		if b.action != "" {
+			return fmt.Sprintf(`%s; charset=utf-8; action="%s"`, headerValueSOAP12, b.action)
+		}
+
+		return headerValueSOAP12 + "; charset=utf-8"
+	}
+
+	return headerValueTextXML + "; charset=utf-8"
+}
+
+func (b *SOAPBody) headers() map[string]string {
+	if b.version == SOAP12 {
+		return nil
+	}
+
+	// the action of SOAP 1.1 is a quoted uri, an empty one tells the server to take the intent from the body
+	return map[string]string{headerKeySOAPAction: `"` + b.action + `"`}
+}
+
+func (v SOAPVersion) namespace() string {
+	if v == SOAP12 {
+		return soap12Namespace
+	}
+
+	return soap11Namespace
+}
+
+func (v SOAPVersion) mustUnderstand() string {
+	if v == SOAP12 {
+		return "true"
+	}
+
+	return "1"
+}
+
+// SOAPOperation is a payload without a struct, the params are encoded as child elements in the order of their keys.
+// Maps are encoded as nested elements and slices as repeated elements.
+type SOAPOperation struct {
+	Name      string
+	Namespace string
+	Params    map[string]any
+}
+
+func NewSOAPOperation(name, namespace string, params map[string]any) *SOAPOperation {
+	return &SOAPOperation{Name: name, Namespace: namespace, Params: params}
+}
+
+func (o *SOAPOperation) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: xml.Name{Local: o.Name}}
+
+	if o.Namespace != "" {
+		start.Attr = []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: o.Namespace}}
+	}
+
+	return encodeSOAPElement(e, start, o.Params)
+}
+
+func encodeSOAPElement(e *xml.Encoder, start xml.StartElement, v any) error {
+	switch value := v.(type) {
+	case map[string]any:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			if err := encodeSOAPElement(e, xml.StartElement{Name: xml.Name{Local: key}}, value[key]); err != nil {
+				return err
+			}
+		}
+
+		return e.EncodeToken(start.End())
+	case []any:
+		for _, item := range value {
+			if err := encodeSOAPElement(e, start, item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case nil:
+		return e.EncodeElement("", start)
+	default:
+		return e.EncodeElement(value, start)
+	}
+}
+
+// SOAPFault is the fault of a SOAP 1.1 or 1.2 response
+type SOAPFault struct {
+	Code   string
+	String string
+	Actor  string
+	// Detail is the inner xml of the detail element
+	Detail string
+}
+
+func (f *SOAPFault) Error() string {
+	return fmt.Sprintf("soap: %s %s", f.Code, f.String)
+}
+
+type soapEnvelope struct {
+	Body struct {
+		Inner []byte     `xml:",innerxml"`
+		Fault *soapFault `xml:"Fault"`
+	} `xml:"Body"`
+}
+
+// soapFault reads the elements of both versions, SOAP 1.1 faults are lower case and SOAP 1.2 faults are nested
+type soapFault struct {
+	FaultCode   string `xml:"faultcode"`
+	FaultString string `xml:"faultstring"`
+	FaultActor  string `xml:"faultactor"`
+	Code        string `xml:"Code>Value"`
+	Subcode     string `xml:"Code>Subcode>Value"`
+	Reason      string `xml:"Reason>Text"`
+	Node        string `xml:"Node"`
+	Detail      struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"detail"`
+	Detail12 struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"Detail"`
+}
+
+func (f soapFault) fault() *SOAPFault {
+	if f.Code != "" || f.Reason != "" {
+		code := f.Code
+		if f.Subcode != "" {
+			code += "/" + f.Subcode
+		}
+
+		return &SOAPFault{Code: code, String: f.Reason, Actor: f.Node, Detail: strings.TrimSpace(f.Detail12.Inner)}
+	}
+
+	return &SOAPFault{Code: f.FaultCode, String: f.FaultString, Actor: f.FaultActor, Detail: strings.TrimSpace(f.Detail.Inner)}
+}
+
+// SOAPDTOFactory unwraps the Body of a SOAP envelope and decodes its content with XMLDTOFactory, a Fault is returned as a *SOAPFault
+type SOAPDTOFactory struct{}
+
+func (r SOAPDTOFactory) marshall(data []byte, v any) error {
+	var envelope soapEnvelope
+
+	if err := (XMLDTOFactory{}).marshall(data, &envelope); err != nil {
+		return err
+	}
+
+	if envelope.Body.Fault != nil {
+		return envelope.Body.Fault.fault()
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return (XMLDTOFactory{}).marshall(envelope.Body.Inner, v)
+}
+
+// SendSOAP sends the request and returns the fault of the response as a *SOAPFault, faults come with status 500 or even 200
+func SendSOAP(doer Doer, request *Request) (Response, error) {
+	response, err := doer.Do(request)
+
+	var statusErr UnexpectedStatusError
+
+	if err != nil && !errors.As(err, &statusErr) {
+		return response, err
+	}
+
+	if fault := (SOAPDTOFactory{}).marshall(response.Data, nil); fault != nil {
+		var soapFault *SOAPFault
+
+		// a failed status without an envelope, like a gateway error, keeps its status error
+		if errors.As(fault, &soapFault) || err == nil {
+			return response, fault
+		}
+	}
+
+	return response, err
+}
+
+// DecodeSOAP decodes the content of the Body of the SOAP response into T:
+//
+//	order, err := room.DecodeSOAP[GetOrderResponse](room.SendSOAP(connector, request))
+func DecodeSOAP[T any](response Response, err error) (T, error) {
+	var v T
+
+	if err != nil {
+		return v, err
+	}
+
+	err = SOAPDTOFactory{}.marshall(response.Data, &v)
+
+	return v, err
+}
@@ -0,0 +1,106 @@
+package room
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type getOrder struct {
+	XMLName xml.Name `xml:"urn:erp GetOrder"`
+	ID      string   `xml:"ID"`
+}
+
+type getOrderResponse struct {
+	XMLName xml.Name `xml:"GetOrderResponse"`
+	Status  string   `xml:"Status"`
+}
+
+func TestSOAPBody_Parse(t *testing.T) {
+	body := NewSOAPBodyParser(getOrder{ID: "7"}, WithSOAPAction("urn:erp/GetOrder"), WithUsernameToken("erp", "s3<cret"))
+
+	got := body.Parse().String()
+
+	for _, want := range []string{
+		`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">`,
+		`soap:mustUnderstand="1"><wsse:UsernameToken><wsse:Username>erp</wsse:Username>`,
+		`#PasswordText">s3&lt;cret</wsse:Password>`,
+		`<soap:Body><GetOrder xmlns="urn:erp"><ID>7</ID></GetOrder></soap:Body></soap:Envelope>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Parse() = %s, want %s", got, want)
+		}
+	}
+
+	if body.ContentType() != "text/xml; charset=utf-8" || body.(bodyHeaderer).headers()["SOAPAction"] != `"urn:erp/GetOrder"` {
+		t.Errorf("ContentType() = %s, headers() = %v", body.ContentType(), body.(bodyHeaderer).headers())
+	}
+
+	body = NewSOAPBodyParser(NewSOAPOperation("GetOrder", "urn:erp", map[string]any{"ID": 7, "Lines": []any{"a", "b"}}),
+		WithSOAPVersion(SOAP12), WithSOAPAction("urn:erp/GetOrder"))
+
+	if got = body.Parse().String(); !strings.Contains(got, `<soap:Body><GetOrder xmlns="urn:erp"><ID>7</ID><Lines>a</Lines><Lines>b</Lines></GetOrder></soap:Body>`) ||
+		!strings.Contains(got, soap12Namespace) {
+		t.Errorf("Parse() = %s", got)
+	}
+
+	if body.ContentType() != `application/soap+xml; charset=utf-8; action="urn:erp/GetOrder"` || body.(bodyHeaderer).headers() != nil {
+		t.Errorf("ContentType() = %s", body.ContentType())
+	}
+}
+
+func TestSendSOAP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+
+		if r.Header.Get("SOAPAction") != `"urn:erp/GetOrder"` || strings.Contains(string(data), "<ID>0</ID>") {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault>` +
+				`<faultcode>soap:Client</faultcode><faultstring>order not found</faultstring><detail><id>0</id></detail></soap:Fault></soap:Body></soap:Envelope>`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`<?xml version="1.0"?><soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+			`<GetOrderResponse xmlns="urn:erp"><Status>shipped</Status></GetOrderResponse></soap:Body></soap:Envelope>`))
+	}))
+	defer server.Close()
+
+	connector := NewConnector(server.URL)
+	request := func(id string) *Request {
+		return NewRequest("erp", WithMethod(POST), WithBody(NewSOAPBodyParser(getOrder{ID: id}, WithSOAPAction("urn:erp/GetOrder"))))
+	}
+
+	order, err := DecodeSOAP[getOrderResponse](SendSOAP(connector, request("7")))
+
+	if err != nil || order.Status != "shipped" {
+		t.Errorf("DecodeSOAP() = %+v, %v", order, err)
+	}
+
+	_, err = SendSOAP(connector, request("0"))
+
+	var fault *SOAPFault
+
+	if !errors.As(err, &fault) || fault.Code != "soap:Client" || fault.String != "order not found" || fault.Detail != "<id>0</id>" {
+		t.Errorf("SendSOAP() error = %v (%+v)", err, fault)
+	}
+}
+
+func TestSOAPDTOFactory_Fault12(t *testing.T) {
+	data := []byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>` +
+		`<env:Code><env:Value>env:Sender</env:Value><env:Subcode><env:Value>erp:Locked</env:Value></env:Subcode></env:Code>` +
+		`<env:Reason><env:Text xml:lang="en">order is locked</env:Text></env:Reason></env:Fault></env:Body></env:Envelope>`)
+
+	err := NewDTOFactory("application/soap+xml; charset=utf-8").marshall(data, &getOrderResponse{})
+
+	var fault *SOAPFault
+
+	if !errors.As(err, &fault) || fault.Code != "env:Sender/erp:Locked" || err.Error() != "soap: env:Sender/erp:Locked order is locked" {
+		t.Errorf("marshall() = %v", err)
+	}
+}
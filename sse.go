@@ -0,0 +1,364 @@
+package room
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const headerValueEventStream = "text/event-stream"
+
+// ErrStreamEnded is returned by SSE when the server answers 204 No Content, the stream is not reconnected then
+var ErrStreamEnded = errors.New("room: event stream ended by the server")
+
+// StreamStatusError is returned when the server or the auth request of a stream rejects it by the status code
+type StreamStatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e StreamStatusError) Error() string {
+	return e.Message
+}
+
+// retryable reports whether a stream is reconnected after the error, client errors other than 408 and 429 are final
+func retryable(err error) bool {
+	var statusError StreamStatusError
+
+	if !errors.As(err, &statusError) || statusError.StatusCode < http.StatusBadRequest || statusError.StatusCode >= http.StatusInternalServerError {
+		return true
+	}
+
+	return statusError.StatusCode == http.StatusRequestTimeout || statusError.StatusCode == http.StatusTooManyRequests
+}
+
+// Event is a parsed server-sent event, Event is `message` unless the server names it
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	// Retry is the reconnection delay sent by the server, it is 0 when the event does not set it
+	Retry time.Duration
+}
+
+// EventStream delivers the events of a server-sent events stream, it reconnects with Last-Event-ID when the connection drops
+type EventStream struct {
+	events chan Event
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	authorize func(request *Request) (*Request, error)
+
+	mu          sync.Mutex
+	err         error
+	lastEventID string
+}
+
+type sseOptions struct {
+	reconnectDelay time.Duration
+	maxReconnects  int
+	lastEventID    string
+	authorize      func(request *Request) (*Request, error)
+}
+
+type OptionSSE func(options *sseOptions)
+
+// WithReconnectDelay sets the delay before reconnecting, it is 3 seconds by default and the `retry` field of the server overrides it
+func WithReconnectDelay(delay time.Duration) OptionSSE {
+	return func(options *sseOptions) {
+		options.reconnectDelay = delay
+	}
+}
+
+// withSSEAuthorizer authorizes every connection of the stream, so the reconnects do not reuse an expired token
+func withSSEAuthorizer(authorize func(request *Request) (*Request, error)) OptionSSE {
+	return func(options *sseOptions) {
+		options.authorize = authorize
+	}
+}
+
+// WithMaxReconnects stops the stream after n reconnects in a row without an event, it reconnects forever by default.
+// A client error other than 408 and 429 stops the stream at once with a StreamStatusError.
+func WithMaxReconnects(n int) OptionSSE {
+	return func(options *sseOptions) {
+		options.maxReconnects = n
+	}
+}
+
+// WithLastEventID resumes a stream from the event id
+func WithLastEventID(id string) OptionSSE {
+	return func(options *sseOptions) {
+		options.lastEventID = id
+	}
+}
+
+// Events returns the channel of the events, it is closed when the stream ends
+func (s *EventStream) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns the error which ended the stream, it is nil while the stream is open or when it is closed
+func (s *EventStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// LastEventID returns the id of the last event, it is sent as Last-Event-ID on reconnects
+func (s *EventStream) LastEventID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastEventID
+}
+
+// Close ends the stream and waits until the events channel is closed
+func (s *EventStream) Close() {
+	s.cancel()
+
+	for range s.events {
+	}
+
+	<-s.done
+}
+
+// SSE opens a server-sent events stream of the request with the connector's base url, header and transport.
+// The first connection is made before returning, so a rejected stream returns its error at once.
+// The context ends the stream, the timeout of the context builder does not apply to streams.
+func (c *Connector) SSE(ctx context.Context, request *Request, opts ...OptionSSE) (*EventStream, error) {
+	options := sseOptions{reconnectDelay: 3 * time.Second, maxReconnects: -1}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	r := request.
+		Clone().
+		SetBaseUrl(c.baseUrl).
+		MergeHeader(c.Header)
+
+	if r.Header == nil {
+		r.Header = NewHeader()
+	}
+
+	r.Header.Add(headerKeyAccept, headerValueEventStream)
+	r.Header.Add("Cache-Control", "no-cache")
+
+	if c.transport != nil {
+		r.transport = c.transport
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	stream := &EventStream{
+		events:      make(chan Event, 16),
+		cancel:      cancel,
+		done:        make(chan struct{}),
+		lastEventID: options.lastEventID,
+		authorize:   options.authorize,
+	}
+
+	body, err := stream.connect(ctx, r)
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go stream.run(ctx, r, body, options)
+
+	return stream, nil
+}
+
+func (s *EventStream) connect(ctx context.Context, r *Request) (io.ReadCloser, error) {
+	attempt := r.Clone()
+
+	if s.authorize != nil {
+		authorized, err := s.authorize(attempt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		attempt = authorized
+	}
+
+	if id := s.LastEventID(); id != "" {
+		attempt.Header.Add("Last-Event-ID", id)
+	}
+
+	response, err := (&http.Client{Transport: attempt.transport}).Do(attempt.request(ctx))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNoContent {
+		_ = response.Body.Close()
+		return nil, ErrStreamEnded
+	}
+
+	if response.StatusCode != http.StatusOK {
+		res := NewResponse(response, response.Request)
+		_ = response.Body.Close()
+
+		return nil, StreamStatusError{
+			StatusCode: res.StatusCode,
+			Message:    fmt.Sprintf("room: event stream answered %d: %s", res.StatusCode, strings.TrimSpace(string(res.Data))),
+		}
+	}
+
+	return response.Body, nil
+}
+
+func (s *EventStream) run(ctx context.Context, r *Request, body io.ReadCloser, options sseOptions) {
+	defer close(s.done)
+	defer close(s.events)
+
+	delay := options.reconnectDelay
+	reconnects := 0
+
+	for {
+		received, retry, err := s.read(ctx, body)
+		_ = body.Close()
+
+		if retry > 0 {
+			delay = retry
+		}
+
+		if received {
+			reconnects = 0
+		}
+
+		if errors.Is(err, io.EOF) {
+			err = errors.New("room: event stream is disconnected")
+		}
+
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+
+			if options.maxReconnects >= 0 && reconnects >= options.maxReconnects {
+				s.fail(err)
+				return
+			}
+
+			reconnects++
+
+			if wait(ctx, delay) != nil {
+				return
+			}
+
+			// the reconnects are the resends of the request
+			r.attempt++
+
+			if body, err = s.connect(ctx, r); err == nil {
+				break
+			}
+
+			// 204 No Content tells the client to stop reconnecting
+			if errors.Is(err, ErrStreamEnded) {
+				return
+			}
+
+			// a rejected request like 401 or 404 is not accepted by another try
+			if !retryable(err) {
+				s.fail(err)
+				return
+			}
+		}
+	}
+}
+
+func (s *EventStream) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+}
+
+// read parses the events of the body until it ends, it reports whether an event was delivered and the last retry field
+func (s *EventStream) read(ctx context.Context, body io.Reader) (bool, time.Duration, error) {
+	reader := bufio.NewReader(body)
+
+	var data strings.Builder
+	var event Event
+	var retry time.Duration
+	hasData := false
+	received := false
+
+	for {
+		line, err := reader.ReadString('\n')
+
+		if err != nil {
+			// an incomplete event at the end of the stream is discarded like the spec says
+			return received, retry, err
+		}
+
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if hasData {
+				event.Data = data.String()
+
+				if event.Event == "" {
+					event.Event = "message"
+				}
+
+				event.ID = s.LastEventID()
+
+				select {
+				case s.events <- event:
+					received = true
+				case <-ctx.Done():
+					return received, retry, ctx.Err()
+				}
+			}
+
+			data.Reset()
+			event = Event{}
+			hasData = false
+
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+
+			data.WriteString(value)
+			hasData = true
+		case "event":
+			event.Event = value
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				s.mu.Lock()
+				s.lastEventID = value
+				s.mu.Unlock()
+			}
+		case "retry":
+			if milliseconds, err := strconv.Atoi(value); err == nil && milliseconds >= 0 {
+				retry = time.Duration(milliseconds) * time.Millisecond
+				event.Retry = retry
+			}
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package room
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func collectEvents(t *testing.T, stream *EventStream) []Event {
+	t.Helper()
+
+	var events []Event
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case event, ok := <-stream.Events():
+			if !ok {
+				return events
+			}
+
+			events = append(events, event)
+		case <-timeout:
+			t.Fatal("event stream did not end")
+		}
+	}
+}
+
+func TestConnector_SSE(t *testing.T) {
+	var connections atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" || r.Header.Get("X-Partner") != "room" {
+			t.Errorf("header = %v", r.Header)
+		}
+
+		switch connections.Add(1) {
+		case 1:
+			w.Header().Set("Content-Type", "text/event-stream")
+			// the retry field replaces the hour long reconnect delay of the test
+			_, _ = fmt.Fprint(w, ": welcome\r\nretry: 10\nid: 1\nevent: greet\ndata: hello\ndata:world\n\nid: 2\ndata\n\n")
+			_, _ = fmt.Fprint(w, "data: incomplete")
+		case 2:
+			if id := r.Header.Get("Last-Event-ID"); id != "2" {
+				t.Errorf("Last-Event-ID = %q", id)
+			}
+
+			_, _ = fmt.Fprint(w, "data: bye\n\n")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	connector := NewConnector(server.URL, WithHeaderConnector(NewHeader().Add("X-Partner", "room")))
+
+	stream, err := connector.SSE(context.Background(), NewRequest("events"), WithReconnectDelay(time.Hour))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := collectEvents(t, stream)
+
+	want := []Event{
+		{ID: "1", Event: "greet", Data: "hello\nworld", Retry: 10 * time.Millisecond},
+		{ID: "2", Event: "message", Data: ""},
+		{ID: "2", Event: "message", Data: "bye"},
+	}
+
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+
+	if stream.Err() != nil || connections.Load() != 3 {
+		t.Errorf("err = %v, connections = %d", stream.Err(), connections.Load())
+	}
+}
+
+func TestConnector_SSE_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	if _, err := NewConnector(server.URL).SSE(context.Background(), NewRequest("events")); err == nil {
+		t.Error("expected an error for the rejected stream")
+	}
+}
+
+func TestConnector_SSE_MaxReconnects(t *testing.T) {
+	var connections atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connections.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	stream, err := NewConnector(server.URL).SSE(context.Background(), NewRequest("events"), WithReconnectDelay(time.Millisecond), WithMaxReconnects(2))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if events := collectEvents(t, stream); len(events) != 0 {
+		t.Errorf("events = %v", events)
+	}
+
+	if stream.Err() == nil || connections.Load() != 3 {
+		t.Errorf("err = %v, connections = %d", stream.Err(), connections.Load())
+	}
+}
+
+func TestAuthRoom_SSE(t *testing.T) {
+	var tokens, connections atomic.Int32
+	var authorizations []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"token":"t%d"}`, tokens.Add(1))
+	})
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+
+		// the first connection drops, the reconnect finds the stream gone
+		if connections.Add(1) == 1 {
+			_, _ = fmt.Fprint(w, "retry: 1\ndata: hello\n\n")
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	connector := NewConnector(server.URL)
+	authRoom := NewAuthRoom(connector, NewRequest("login", WithMethod(POST)), "token").(*AuthRoom)
+
+	stream, err := authRoom.SSE(context.Background(), NewRequest("events"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if events := collectEvents(t, stream); len(events) != 1 {
+		t.Errorf("events = %v", events)
+	}
+
+	var statusError StreamStatusError
+
+	if !errors.As(stream.Err(), &statusError) || statusError.StatusCode != http.StatusNotFound || connections.Load() != 2 {
+		t.Errorf("err = %v, connections = %d", stream.Err(), connections.Load())
+	}
+
+	if fmt.Sprint(authorizations) != "[Bearer t1 Bearer t2]" {
+		t.Errorf("authorizations = %v", authorizations)
+	}
+}
+
+func TestEventStream_Close(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	stream, err := NewConnector(server.URL).SSE(context.Background(), NewRequest("events"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if event := <-stream.Events(); event.Data != "first" {
+		t.Errorf("event = %v", event)
+	}
+
+	stream.Close()
+
+	if _, open := <-stream.Events(); open || stream.Err() != nil {
+		t.Errorf("stream is not closed, err = %v", stream.Err())
+	}
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -73,6 +74,10 @@ func (s *MapStore) StringAll() string {
 
 	keys := v.MapKeys()
 
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
 	parts := make([]string, len(keys))
 
 	for i := 0; i < len(keys); i++ {
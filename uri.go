@@ -1,9 +1,13 @@
 package room
 
 import (
+	"slices"
 	"strings"
 )
 
+// knownSchemes are the schemes of the urls, websockets are opened by an http upgrade of the ws and wss urls
+var knownSchemes = []string{"http", "https", "ws", "wss"}
+
 type URI struct {
 	scheme    string
 	authority string
@@ -21,6 +25,18 @@ func (u URI) String() string {
 	return url
 }
 
+// httpString returns the url with the ws and wss schemes replaced by http and https
+func (u URI) httpString() string {
+	switch u.scheme {
+	case "ws":
+		u.scheme = "http"
+	case "wss":
+		u.scheme = "https"
+	}
+
+	return u.String()
+}
+
 func (u URI) Query() string {
 	return u.query
 }
@@ -48,13 +64,12 @@ func NewURI(fullUrl string) URI {
 		uri.query = splittedURL[1]
 	}
 
-	var urn string
-	if strings.HasPrefix(fullUrl, "https://") {
-		uri.scheme = "https"
-		urn = strings.TrimPrefix(fullUrl, "https://")
-	} else {
-		uri.scheme = "http"
-		urn = strings.TrimPrefix(fullUrl, "http://")
+	uri.scheme = "http"
+	urn := fullUrl
+
+	if scheme, rest, found := strings.Cut(fullUrl, "://"); found && slices.Contains(knownSchemes, scheme) {
+		uri.scheme = scheme
+		urn = rest
 	}
 
 	if len(urn) > 0 && urn[len(urn)-1] != '/' {
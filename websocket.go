@@ -0,0 +1,573 @@
+package room
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/WEG-Technology/room/jsonpath"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWebSocketClosed is returned when a message is sent or received while the websocket is not connected
+var ErrWebSocketClosed = errors.New("room: websocket is not connected")
+
+// errNormalClosure ends the websocket without reconnecting, the server closed it on purpose
+var errNormalClosure = errors.New("room: websocket is closed by the server")
+
+// Message is a received websocket message
+type Message struct {
+	// Type is read from a json text message by the message type path, it is empty without it
+	Type   string
+	Data   []byte
+	Binary bool
+}
+
+// Decode decodes the json message into v
+func (m Message) Decode(v any) error {
+	return json.Unmarshal(m.Data, v)
+}
+
+type webSocketOptions struct {
+	pingInterval      time.Duration
+	minBackoff        time.Duration
+	maxBackoff        time.Duration
+	reconnectAttempts int
+	typePath          jsonpath.Path
+	onConnect         []func(ws *WebSocket) error
+	authorize         func(request *Request) (*Request, error)
+}
+
+type OptionWebSocket func(options *webSocketOptions)
+
+// WithPingInterval pings the server every interval, the connection is dropped and reconnected
+// when nothing is received for two intervals. It is 30 seconds by default, 0 disables the pings.
+func WithPingInterval(interval time.Duration) OptionWebSocket {
+	return func(options *webSocketOptions) {
+		options.pingInterval = interval
+	}
+}
+
+// WithReconnectBackoff sets the first delay before reconnecting, it doubles after every failed attempt up to the max.
+// It is 500 milliseconds up to 30 seconds by default.
+func WithReconnectBackoff(delay, maxDelay time.Duration) OptionWebSocket {
+	return func(options *webSocketOptions) {
+		options.minBackoff = delay
+		options.maxBackoff = maxDelay
+	}
+}
+
+// WithReconnectAttempts stops after n failed reconnects in a row, 0 disables reconnects. It reconnects forever by default.
+// A handshake rejected with a client error other than 408 and 429 stops at once with a StreamStatusError.
+func WithReconnectAttempts(n int) OptionWebSocket {
+	return func(options *webSocketOptions) {
+		options.reconnectAttempts = n
+	}
+}
+
+// WithMessageTypePath reads the type of the json messages by the JSONPath for the subscriptions, it is `$.type` by default
+func WithMessageTypePath(path string) OptionWebSocket {
+	return func(options *webSocketOptions) {
+		options.typePath = jsonpath.MustParse(path)
+	}
+}
+
+// WithOnConnect calls fn after every connect and reconnect, like sending the subscribe messages of the server.
+// An error of fn fails the connect.
+func WithOnConnect(fn func(ws *WebSocket) error) OptionWebSocket {
+	return func(options *webSocketOptions) {
+		options.onConnect = append(options.onConnect, fn)
+	}
+}
+
+// withWebSocketAuthorizer authorizes every handshake of the websocket, so the reconnects do not reuse an expired token
+func withWebSocketAuthorizer(authorize func(request *Request) (*Request, error)) OptionWebSocket {
+	return func(options *webSocketOptions) {
+		options.authorize = authorize
+	}
+}
+
+type subscription struct {
+	handler func(message Message)
+}
+
+// WebSocket is a websocket client which reconnects with backoff when the connection drops.
+// Messages of a subscribed type are passed to the handlers of their subscriptions, the others are read by Next.
+type WebSocket struct {
+	request  *Request
+	options  webSocketOptions
+	ctx      context.Context
+	cancel   context.CancelFunc
+	messages chan Message
+	done     chan struct{}
+	// dials counts the handshakes, it is only touched by the goroutine which dials
+	dials int
+
+	mu            sync.Mutex
+	conn          *wsConn
+	subscriptions map[string][]*subscription
+	err           error
+
+	// lastSeen and busy let the keepalive tell a dead connection from a reader waiting for Next
+	lastSeen atomic.Int64
+	busy     atomic.Bool
+}
+
+// WebSocket opens a websocket of the request with the connector's base url, header, transport and middlewares.
+// The base url may use the ws and wss schemes as well as http and https.
+// The context builder limits the handshake, the context ends the websocket.
+func (c *Connector) WebSocket(ctx context.Context, request *Request, opts ...OptionWebSocket) (*WebSocket, error) {
+	options := webSocketOptions{
+		pingInterval:      30 * time.Second,
+		minBackoff:        500 * time.Millisecond,
+		maxBackoff:        30 * time.Second,
+		reconnectAttempts: -1,
+		typePath:          jsonpath.MustParse("$.type"),
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	r := request.
+		Clone().
+		SetBaseUrl(c.baseUrl).
+		MergeHeader(c.Header)
+
+	r.Method = GET
+
+	if r.contextBuilder == nil {
+		r.SetContextBuilder(c.contextBuilder)
+	}
+
+	if c.transport != nil {
+		r.transport = c.transport
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	ws := &WebSocket{
+		request:       r,
+		options:       options,
+		ctx:           ctx,
+		cancel:        cancel,
+		messages:      make(chan Message, 64),
+		done:          make(chan struct{}),
+		subscriptions: map[string][]*subscription{},
+	}
+
+	conn, err := ws.dial()
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go ws.run(conn)
+
+	return ws, nil
+}
+
+// Send sends v as a json text message
+func (ws *WebSocket) Send(v any) error {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	return ws.write(opText, data)
+}
+
+// SendMessage sends the data of the message as it is
+func (ws *WebSocket) SendMessage(message Message) error {
+	if message.Binary {
+		return ws.write(opBinary, message.Data)
+	}
+
+	return ws.write(opText, message.Data)
+}
+
+func (ws *WebSocket) write(opcode byte, data []byte) error {
+	ws.mu.Lock()
+	conn := ws.conn
+	ws.mu.Unlock()
+
+	if conn == nil {
+		return ErrWebSocketClosed
+	}
+
+	return conn.write(opcode, data)
+}
+
+// Next returns the next message which is not handled by a subscription.
+// Up to 64 messages are queued, the websocket stops reading while the queue is full.
+func (ws *WebSocket) Next(ctx context.Context) (Message, error) {
+	select {
+	case message, ok := <-ws.messages:
+		if !ok {
+			if err := ws.Err(); err != nil {
+				return Message{}, err
+			}
+
+			return Message{}, ErrWebSocketClosed
+		}
+
+		return message, nil
+	case <-ctx.Done():
+		return Message{}, ctx.Err()
+	}
+}
+
+// Receive decodes the next json message which is not handled by a subscription
+func Receive[T any](ctx context.Context, ws *WebSocket) (T, error) {
+	var v T
+
+	message, err := ws.Next(ctx)
+
+	if err != nil {
+		return v, err
+	}
+
+	err = message.Decode(&v)
+
+	return v, err
+}
+
+// Subscribe passes the messages of the type to the handler until the returned func is called.
+// The handlers are called by the reader of the websocket one at a time, so a slow handler delays the next messages.
+func (ws *WebSocket) Subscribe(messageType string, handler func(message Message)) (unsubscribe func()) {
+	s := &subscription{handler: handler}
+
+	ws.mu.Lock()
+	ws.subscriptions[messageType] = append(ws.subscriptions[messageType], s)
+	ws.mu.Unlock()
+
+	return func() {
+		ws.mu.Lock()
+		defer ws.mu.Unlock()
+
+		ws.subscriptions[messageType] = slices.DeleteFunc(ws.subscriptions[messageType], func(other *subscription) bool {
+			return other == s
+		})
+	}
+}
+
+// Err returns the error which ended the websocket, it is nil while it is open or when it is closed
+func (ws *WebSocket) Err() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.err
+}
+
+// Done is closed when the websocket ends
+func (ws *WebSocket) Done() <-chan struct{} {
+	return ws.done
+}
+
+// Close closes the websocket with a normal closure and waits until it ends
+func (ws *WebSocket) Close() error {
+	ws.mu.Lock()
+	conn := ws.conn
+	ws.mu.Unlock()
+
+	if conn != nil {
+		_ = conn.write(opClose, binary.BigEndian.AppendUint16(nil, closeNormal))
+	}
+
+	ws.cancel()
+	<-ws.done
+
+	return nil
+}
+
+// dial makes the handshake and calls the connect hooks
+func (ws *WebSocket) dial() (*wsConn, error) {
+	r := ws.request.Clone()
+
+	// the reconnects are the resends of the handshake
+	r.attempt = ws.dials
+	ws.dials++
+
+	if ws.options.authorize != nil {
+		authorized, err := ws.options.authorize(r)
+
+		if err != nil {
+			return nil, err
+		}
+
+		r = authorized
+	}
+
+	key, err := webSocketKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Header == nil {
+		r.Header = NewHeader()
+	}
+
+	r.Header.
+		Add("Connection", "Upgrade").
+		Add("Upgrade", "websocket").
+		Add("Sec-WebSocket-Version", "13").
+		Add("Sec-WebSocket-Key", key)
+
+	// the deadline of the context builder applies to the handshake only, the connection outlives it
+	ctx := ws.ctx
+	built := r.context()
+
+	if built.Cancel != nil {
+		defer built.Cancel()
+	}
+
+	if deadline, ok := built.Ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	response, err := (&http.Client{Transport: r.transport}).Do(r.request(ctx))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		res := NewResponse(response, response.Request)
+		_ = response.Body.Close()
+
+		return nil, StreamStatusError{
+			StatusCode: res.StatusCode,
+			Message:    fmt.Sprintf("room: websocket handshake answered %d: %s", res.StatusCode, strings.TrimSpace(string(res.Data))),
+		}
+	}
+
+	rwc, isUpgraded := response.Body.(io.ReadWriteCloser)
+
+	if !isUpgraded || !strings.EqualFold(response.Header.Get("Upgrade"), "websocket") || response.Header.Get("Sec-WebSocket-Accept") != webSocketAccept(key) {
+		_ = response.Body.Close()
+
+		return nil, errors.New("room: websocket handshake is not accepted")
+	}
+
+	conn := newWsConn(rwc, true)
+
+	ws.mu.Lock()
+	ws.conn = conn
+	ws.mu.Unlock()
+
+	for _, fn := range ws.options.onConnect {
+		if err = fn(ws); err != nil {
+			ws.drop(conn)
+			conn.closeWith(closeNormal)
+
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+func (ws *WebSocket) run(conn *wsConn) {
+	defer close(ws.done)
+	defer close(ws.messages)
+
+	for {
+		err := ws.serve(conn)
+
+		if ws.ctx.Err() != nil || errors.Is(err, errNormalClosure) {
+			return
+		}
+
+		if conn, err = ws.reconnect(err); conn == nil {
+			if ws.ctx.Err() == nil {
+				ws.fail(err)
+			}
+
+			return
+		}
+	}
+}
+
+func (ws *WebSocket) reconnect(cause error) (*wsConn, error) {
+	delay := ws.options.minBackoff
+
+	for attempt := 0; ws.options.reconnectAttempts < 0 || attempt < ws.options.reconnectAttempts; attempt++ {
+		if wait(ws.ctx, delay) != nil {
+			return nil, cause
+		}
+
+		conn, err := ws.dial()
+
+		if err == nil {
+			return conn, nil
+		}
+
+		// a rejected handshake like 401 or 404 is not accepted by another try
+		if !retryable(err) {
+			return nil, err
+		}
+
+		cause = err
+		delay = min(delay*2, ws.options.maxBackoff)
+	}
+
+	return nil, cause
+}
+
+// serve reads the connection until it fails or closes, it answers the pings and delivers the messages
+func (ws *WebSocket) serve(conn *wsConn) error {
+	stop := context.AfterFunc(ws.ctx, conn.close)
+	defer stop()
+
+	defer conn.close()
+	defer ws.drop(conn)
+
+	ws.lastSeen.Store(time.Now().UnixNano())
+
+	if ws.options.pingInterval > 0 {
+		alive := make(chan struct{})
+		defer close(alive)
+
+		go ws.keepalive(conn, alive)
+	}
+
+	var message []byte
+	var opcode byte
+
+	for {
+		frame, err := conn.readFrame()
+
+		if err != nil {
+			return err
+		}
+
+		ws.lastSeen.Store(time.Now().UnixNano())
+
+		switch frame.opcode {
+		case opPing:
+			_ = conn.write(opPong, frame.payload)
+		case opPong:
+		case opClose:
+			code := closeNoStatus
+
+			if len(frame.payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(frame.payload))
+			}
+
+			conn.closeWith(closeNormal)
+
+			if code == closeNormal {
+				return errNormalClosure
+			}
+
+			return fmt.Errorf("room: websocket is closed by the server with %d", code)
+		case opText, opBinary, opContinuation:
+			if frame.opcode != opContinuation {
+				opcode, message = frame.opcode, nil
+			}
+
+			message = append(message, frame.payload...)
+
+			if len(message) > maxWebSocketMessage {
+				conn.closeWith(closeTooLarge)
+				return fmt.Errorf("room: websocket message of %d bytes is too large", len(message))
+			}
+
+			if frame.fin {
+				ws.deliver(Message{Data: message, Binary: opcode == opBinary})
+				message = nil
+			}
+		default:
+			conn.closeWith(closeProtocolError)
+			return fmt.Errorf("room: websocket opcode %d is not supported", frame.opcode)
+		}
+	}
+}
+
+func (ws *WebSocket) deliver(message Message) {
+	if !message.Binary {
+		var body any
+
+		if json.Unmarshal(message.Data, &body) == nil {
+			if value, found := ws.options.typePath.First(body); found && value != nil {
+				message.Type = fmt.Sprint(value)
+			}
+		}
+	}
+
+	ws.mu.Lock()
+	subscriptions := slices.Clone(ws.subscriptions[message.Type])
+	ws.mu.Unlock()
+
+	// the connection is not read while a handler runs or the queue is full
+	ws.busy.Store(true)
+
+	defer func() {
+		ws.lastSeen.Store(time.Now().UnixNano())
+		ws.busy.Store(false)
+	}()
+
+	if len(subscriptions) > 0 {
+		for _, s := range subscriptions {
+			s.handler(message)
+		}
+
+		return
+	}
+
+	select {
+	case ws.messages <- message:
+	case <-ws.ctx.Done():
+	}
+}
+
+// keepalive pings the server and drops the connection when nothing is received for two intervals
+func (ws *WebSocket) keepalive(conn *wsConn, alive <-chan struct{}) {
+	ticker := time.NewTicker(ws.options.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-alive:
+			return
+		case <-ticker.C:
+			if ws.busy.Load() {
+				continue
+			}
+
+			if time.Since(time.Unix(0, ws.lastSeen.Load())) > 2*ws.options.pingInterval || conn.write(opPing, nil) != nil {
+				conn.close()
+				return
+			}
+		}
+	}
+}
+
+// drop forgets the connection, so the messages are not sent to a closed connection
+func (ws *WebSocket) drop(conn *wsConn) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.conn == conn {
+		ws.conn = nil
+	}
+}
+
+func (ws *WebSocket) fail(err error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	ws.err = err
+}
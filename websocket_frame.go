@@ -0,0 +1,181 @@
+package room
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// the websocket opcodes of RFC 6455
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+const (
+	webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// maxWebSocketMessage limits the size of a received message, fragments included
+	maxWebSocketMessage = 32 << 20
+	closeNormal         = 1000
+	closeProtocolError  = 1002
+	closeNoStatus       = 1005
+	closeTooLarge       = 1009
+)
+
+type wsFrame struct {
+	fin     bool
+	opcode  byte
+	payload []byte
+}
+
+// wsConn reads and writes the frames of an upgraded connection, clients mask the frames they write
+type wsConn struct {
+	rwc     io.ReadWriteCloser
+	reader  *bufio.Reader
+	mask    bool
+	writeMu sync.Mutex
+	once    sync.Once
+}
+
+func newWsConn(rwc io.ReadWriteCloser, mask bool) *wsConn {
+	return &wsConn{rwc: rwc, reader: bufio.NewReader(rwc), mask: mask}
+}
+
+func (c *wsConn) write(opcode byte, payload []byte) error {
+	header := make([]byte, 2, 14)
+	header[0] = 0x80 | opcode
+
+	switch length := len(payload); {
+	case length < 126:
+		header[1] = byte(length)
+	case length <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	data := payload
+
+	if c.mask {
+		var key [4]byte
+
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+
+		header[1] |= 0x80
+		header = append(header, key[:]...)
+
+		data = make([]byte, len(payload))
+		for i := range payload {
+			data[i] = payload[i] ^ key[i%4]
+		}
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_, err := c.rwc.Write(append(header, data...))
+
+	return err
+}
+
+func (c *wsConn) readFrame() (wsFrame, error) {
+	var head [2]byte
+
+	if _, err := io.ReadFull(c.reader, head[:]); err != nil {
+		return wsFrame{}, err
+	}
+
+	frame := wsFrame{fin: head[0]&0x80 != 0, opcode: head[0] & 0x0F}
+	length := uint64(head[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return frame, err
+		}
+
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return frame, err
+		}
+
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	if length > maxWebSocketMessage {
+		return frame, fmt.Errorf("room: websocket frame of %d bytes is too large", length)
+	}
+
+	if frame.opcode >= opClose && (length > 125 || !frame.fin) {
+		return frame, errors.New("room: websocket control frame is invalid")
+	}
+
+	var key [4]byte
+
+	if masked := head[1]&0x80 != 0; masked {
+		if _, err := io.ReadFull(c.reader, key[:]); err != nil {
+			return frame, err
+		}
+	}
+
+	frame.payload = make([]byte, length)
+
+	if _, err := io.ReadFull(c.reader, frame.payload); err != nil {
+		return frame, err
+	}
+
+	if head[1]&0x80 != 0 {
+		for i := range frame.payload {
+			frame.payload[i] ^= key[i%4]
+		}
+	}
+
+	return frame, nil
+}
+
+// closeWith sends a close frame with the code and closes the connection
+func (c *wsConn) closeWith(code int) {
+	_ = c.write(opClose, binary.BigEndian.AppendUint16(nil, uint16(code)))
+	c.close()
+}
+
+func (c *wsConn) close() {
+	c.once.Do(func() {
+		_ = c.rwc.Close()
+	})
+}
+
+// webSocketKey returns a random Sec-WebSocket-Key
+func webSocketKey() (string, error) {
+	var key [16]byte
+
+	if _, err := rand.Read(key[:]); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key[:]), nil
+}
+
+// webSocketAccept returns the Sec-WebSocket-Accept the server must answer to the key
+func webSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,331 @@
+package room
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newWebSocketServer upgrades the requests and calls serve with the server side of the connection
+func newWebSocketServer(t *testing.T, serve func(conn *wsConn, r *http.Request)) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || r.Header.Get("Sec-WebSocket-Version") != "13" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		netConn, rw, err := w.(http.Hijacker).Hijack()
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + webSocketAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		_ = rw.Flush()
+
+		conn := &wsConn{rwc: netConn, reader: rw.Reader}
+		defer conn.close()
+
+		serve(conn, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// echo answers the pings and sends the messages back until the client closes the connection
+func echo(conn *wsConn, _ *http.Request) {
+	for {
+		frame, err := conn.readFrame()
+
+		if err != nil {
+			return
+		}
+
+		switch frame.opcode {
+		case opPing:
+			_ = conn.write(opPong, frame.payload)
+		case opClose:
+			conn.closeWith(closeNormal)
+			return
+		default:
+			_ = conn.write(frame.opcode, frame.payload)
+		}
+	}
+}
+
+type tick struct {
+	Type string `json:"type"`
+	N    int    `json:"n"`
+}
+
+func TestConnector_WebSocket(t *testing.T) {
+	server := newWebSocketServer(t, func(conn *wsConn, r *http.Request) {
+		if r.Header.Get("X-Partner") != "room" || r.URL.Path != "/stream" {
+			conn.closeWith(closeProtocolError)
+			return
+		}
+
+		echo(conn, r)
+	})
+
+	connector := NewConnector(strings.Replace(server.URL, "http://", "ws://", 1), WithHeaderConnector(NewHeader().Add("X-Partner", "room")))
+
+	ws, err := connector.WebSocket(context.Background(), NewRequest("stream"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	ticks := make(chan Message, 1)
+	unsubscribe := ws.Subscribe("tick", func(message Message) {
+		ticks <- message
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err = ws.Send(tick{Type: "tick", N: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if message := <-ticks; message.Type != "tick" || string(message.Data) != `{"type":"tick","n":1}` {
+		t.Errorf("subscribed message = %+v", message)
+	}
+
+	unsubscribe()
+
+	_ = ws.Send(tick{Type: "tick", N: 2})
+
+	if received, err := Receive[tick](ctx, ws); err != nil || received.N != 2 {
+		t.Errorf("Receive() = %+v, %v", received, err)
+	}
+
+	_ = ws.SendMessage(Message{Data: []byte{0, 1, 2}, Binary: true})
+	_ = ws.SendMessage(Message{Data: []byte(strings.Repeat("x", 70000))})
+
+	if message, err := ws.Next(ctx); err != nil || !message.Binary || len(message.Data) != 3 {
+		t.Errorf("binary message = %+v, %v", message, err)
+	}
+
+	if message, err := ws.Next(ctx); err != nil || len(message.Data) != 70000 {
+		t.Errorf("large message has %d bytes, %v", len(message.Data), err)
+	}
+
+	_ = ws.Close()
+
+	if _, err = ws.Next(ctx); err != ErrWebSocketClosed {
+		t.Errorf("Next() after Close = %v", err)
+	}
+}
+
+func TestConnector_WebSocket_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	if _, err := NewConnector(server.URL).WebSocket(context.Background(), NewRequest("stream")); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("WebSocket() = %v", err)
+	}
+}
+
+func TestWebSocket_Reconnect(t *testing.T) {
+	var connections atomic.Int32
+
+	server := newWebSocketServer(t, func(conn *wsConn, r *http.Request) {
+		// the first connection drops without a close frame after the subscribe message
+		if connections.Add(1) == 1 {
+			_, _ = conn.readFrame()
+			return
+		}
+
+		echo(conn, r)
+	})
+
+	var connects atomic.Int32
+
+	ws, err := NewConnector(server.URL).WebSocket(context.Background(), NewRequest("stream"),
+		WithReconnectBackoff(time.Millisecond, 10*time.Millisecond),
+		WithOnConnect(func(ws *WebSocket) error {
+			connects.Add(1)
+			return ws.Send(map[string]string{"type": "subscribe"})
+		}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the subscribe message of the reconnect is echoed by the second connection
+	if message, err := ws.Next(ctx); err != nil || message.Type != "subscribe" {
+		t.Fatalf("Next() = %+v, %v", message, err)
+	}
+
+	if connections.Load() != 2 || connects.Load() != 2 {
+		t.Errorf("connections = %d, connects = %d", connections.Load(), connects.Load())
+	}
+}
+
+func TestWebSocket_Keepalive(t *testing.T) {
+	var pings atomic.Int32
+
+	// the server reads the pings but never answers them
+	server := newWebSocketServer(t, func(conn *wsConn, r *http.Request) {
+		for {
+			frame, err := conn.readFrame()
+
+			if err != nil {
+				return
+			}
+
+			if frame.opcode == opPing {
+				pings.Add(1)
+			}
+		}
+	})
+
+	ws, err := NewConnector(server.URL).WebSocket(context.Background(), NewRequest("stream"), WithPingInterval(10*time.Millisecond), WithReconnectAttempts(0))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ws.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the dead connection is not dropped")
+	}
+
+	if ws.Err() == nil || pings.Load() == 0 {
+		t.Errorf("err = %v, pings = %d", ws.Err(), pings.Load())
+	}
+}
+
+func TestWebSocket_KeepaliveSlowHandler(t *testing.T) {
+	server := newWebSocketServer(t, func(conn *wsConn, r *http.Request) {
+		_ = conn.write(opText, []byte(`{"type":"tick","n":1}`))
+		echo(conn, r)
+	})
+
+	handled := make(chan struct{})
+
+	ws, err := NewConnector(server.URL).WebSocket(context.Background(), NewRequest("stream"), WithPingInterval(10*time.Millisecond), WithReconnectAttempts(0))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	// the pongs are not read while the handler runs, the connection is alive anyway
+	ws.Subscribe("tick", func(message Message) {
+		time.Sleep(100 * time.Millisecond)
+		close(handled)
+	})
+
+	<-handled
+
+	select {
+	case <-ws.Done():
+		t.Fatalf("the connection is dropped while the handler runs: %v", ws.Err())
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestAuthRoom_WebSocket(t *testing.T) {
+	var tokens, connections atomic.Int32
+	var authorizations []string
+
+	// the first connection drops, the reconnect finds the stream gone
+	upgrader := newWebSocketServer(t, func(conn *wsConn, r *http.Request) {
+		_, _ = conn.readFrame()
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"token":"t%d"}`, tokens.Add(1))
+	})
+	mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+
+		if connections.Add(1) > 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		upgrader.Config.Handler.ServeHTTP(w, r)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	authRoom := NewAuthRoom(NewConnector(server.URL), NewRequest("login", WithMethod(POST)), "token").(*AuthRoom)
+
+	ws, err := authRoom.WebSocket(context.Background(), NewRequest("stream"), WithReconnectBackoff(time.Millisecond, time.Millisecond),
+		WithOnConnect(func(ws *WebSocket) error {
+			return ws.Send(map[string]string{"type": "subscribe"})
+		}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ws.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the websocket is reconnected after a client error")
+	}
+
+	var statusError StreamStatusError
+
+	if !errors.As(ws.Err(), &statusError) || statusError.StatusCode != http.StatusNotFound || connections.Load() != 2 {
+		t.Errorf("err = %v, connections = %d", ws.Err(), connections.Load())
+	}
+
+	if fmt.Sprint(authorizations) != "[Bearer t1 Bearer t2]" {
+		t.Errorf("authorizations = %v", authorizations)
+	}
+}
+
+func TestWebSocket_NormalClosure(t *testing.T) {
+	var connections atomic.Int32
+
+	server := newWebSocketServer(t, func(conn *wsConn, r *http.Request) {
+		connections.Add(1)
+		_ = conn.write(opText, []byte(`{"type":"bye"}`))
+		conn.closeWith(closeNormal)
+	})
+
+	ws, err := NewConnector(server.URL).WebSocket(context.Background(), NewRequest("stream"), WithReconnectBackoff(time.Millisecond, time.Millisecond))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-ws.Done()
+
+	if message, err := ws.Next(context.Background()); err != nil || message.Type != "bye" {
+		t.Errorf("Next() = %+v, %v", message, err)
+	}
+
+	if ws.Err() != nil || connections.Load() != 1 {
+		t.Errorf("err = %v, connections = %d", ws.Err(), connections.Load())
+	}
+}